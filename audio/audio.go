@@ -15,20 +15,29 @@ import (
 // However, temporary files are uniquely named to prevent conflicts between
 // concurrent operations on different Audio instances.
 type Audio struct {
-	path string
-	info *Info
+	path    string
+	info    *Info
+	probed  *ProbeResult
+	caps    *Capabilities
+	runner  ffutil.Runner
+	ffmpeg  string   // ffmpeg binary name or path
+	ffprobe string   // ffprobe binary name or path
+	opts    []Option // kept so derived instances (e.g. for segments) share the configuration
 }
 
 // New creates a new Audio instance from a file path.
 // Returns an error if ffmpeg/ffprobe are not installed or the file does not exist.
-func New(path string) (*Audio, error) {
-	if err := ffutil.CheckDependencies(); err != nil {
-		return nil, err
+func New(path string, opts ...Option) (*Audio, error) {
+	a := newWithOptions(path, opts)
+	if _, ok := a.runner.(ffutil.ExecRunner); ok {
+		if err := ffutil.CheckBinaries(a.ffmpeg, a.ffprobe); err != nil {
+			return nil, err
+		}
 	}
 	if _, err := os.Stat(path); err != nil {
 		return nil, fmt.Errorf("audio file not accessible: %s: %w", path, err)
 	}
-	return &Audio{path: path}, nil
+	return a, nil
 }
 
 // Path returns the audio file path
@@ -0,0 +1,44 @@
+package audio
+
+import (
+	"context"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// Capabilities describes what the configured ffmpeg build can do: its version and
+// the encoders and filters compiled into it.
+type Capabilities = ffutil.Capabilities
+
+// ErrEncoderNotAvailable is wrapped by the error Convert returns when the requested
+// codec is not compiled into the configured ffmpeg build.
+var ErrEncoderNotAvailable = ffutil.ErrEncoderNotAvailable
+
+// Capabilities probes the configured ffmpeg binary with -version, -encoders and
+// -filters. Results are cached — subsequent calls return the cached value.
+func (a *Audio) Capabilities() (*Capabilities, error) {
+	return a.capabilities(context.Background())
+}
+
+func (a *Audio) capabilities(ctx context.Context) (*Capabilities, error) {
+	if a.caps != nil {
+		return a.caps, nil
+	}
+	caps, err := ffutil.ProbeCapabilities(ctx, a.runner, a.ffmpeg)
+	if err != nil {
+		return nil, err
+	}
+	a.caps = caps
+	return caps, nil
+}
+
+// requireEncoders fails early, before any encoding starts, if one of the encoders is
+// missing from the ffmpeg build. If the build cannot be probed, the check is skipped
+// and ffmpeg reports the problem itself.
+func (a *Audio) requireEncoders(ctx context.Context, encoders ...string) error {
+	caps, err := a.capabilities(ctx)
+	if err != nil {
+		return nil
+	}
+	return caps.RequireEncoders(encoders...)
+}
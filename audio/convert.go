@@ -1,11 +1,13 @@
 package audio
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
 )
 
 // ConvertConfig contains configuration for audio conversion
@@ -26,6 +28,9 @@ type ConvertConfig struct {
 
 	// Bitrate in kbps (e.g., 128, 192, 320)
 	Bitrate int
+
+	// OnProgress, if set, receives progress updates while ffmpeg runs
+	OnProgress ProgressFunc
 }
 
 // Common audio codecs
@@ -47,32 +52,65 @@ const (
 	SampleRate96000 = 96000
 )
 
-// Convert converts the audio according to the configuration
+// Convert converts the audio according to the configuration.
+// Returns an error wrapping ErrEncoderNotAvailable, before encoding starts, if the
+// requested codec is not compiled into ffmpeg.
 func (a *Audio) Convert(outputPath string, config ConvertConfig) error {
+	return a.ConvertContext(context.Background(), outputPath, config)
+}
+
+// ConvertContext is like Convert but stops ffmpeg when ctx is cancelled or its
+// deadline passes. The returned error then wraps ctx.Err().
+func (a *Audio) ConvertContext(ctx context.Context, outputPath string, config ConvertConfig) error {
+	if err := a.requireEncoders(ctx, config.codec()); err != nil {
+		return err
+	}
+
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
+	var stage *ffutil.StageProgress
+	if config.OnProgress != nil {
+		info, err := a.getInfo(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get audio info: %w", err)
+		}
+		stage = ffutil.NewProgressTracker(config.OnProgress).Stage(StageConvert, 0, 100, info.Duration, 1)
+	}
+
 	args := []string{"-i", a.path}
 	args = append(args, buildConvertArgs(&config)...)
 	args = append(args, "-y", outputPath)
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
+	_, err := ffutil.RunWithProgress(ctx, a.runner, a.ffmpeg, args, stage.Reporter(0))
 	if err != nil {
-		return fmt.Errorf("FFmpeg error: %w - %s", err, string(output))
+		return fmt.Errorf("failed to convert: %w", err)
 	}
+	stage.Done()
 	return nil
 }
 
+// codec returns the configured audio encoder, defaulting to AAC.
+func (c *ConvertConfig) codec() string {
+	if c.Codec == "" {
+		return CodecAAC
+	}
+	return c.Codec
+}
+
+// needsReencoding reports whether the config asks for anything beyond a stream copy.
+func (c *ConvertConfig) needsReencoding() bool {
+	if c == nil {
+		return false
+	}
+	return c.Codec != "" || c.SampleRate > 0 || c.Channels > 0 || c.Quality > 0 || c.Bitrate > 0
+}
+
 func buildConvertArgs(config *ConvertConfig) []string {
 	var args []string
 
-	codec := config.Codec
-	if codec == "" {
-		codec = CodecAAC
-	}
-	args = append(args, "-c:a", codec)
+	args = append(args, "-c:a", config.codec())
 
 	if config.SampleRate > 0 {
 		args = append(args, "-ar", strconv.Itoa(config.SampleRate))
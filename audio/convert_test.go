@@ -6,6 +6,7 @@ import (
 )
 
 func TestConvert_BasicCodec(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -24,6 +25,7 @@ func TestConvert_BasicCodec(t *testing.T) {
 }
 
 func TestConvert_SampleRate(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -53,6 +55,7 @@ func TestConvert_SampleRate(t *testing.T) {
 }
 
 func TestConvert_OutputDirCreated(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
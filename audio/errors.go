@@ -0,0 +1,23 @@
+package audio
+
+import "github.com/meunomeebero/ffmpego/internal/ffutil"
+
+// FFmpegError describes a failed ffmpeg or ffprobe invocation: the binary, its full
+// argument list, the exit code, the tail of its diagnostic output and a classified
+// Kind. Every error returned by this package that comes from running ffmpeg wraps
+// one, so it can be retrieved with errors.As.
+type FFmpegError = ffutil.FFmpegError
+
+// ErrorKind classifies why an ffmpeg or ffprobe invocation failed.
+type ErrorKind = ffutil.ErrorKind
+
+// Error kinds reported in FFmpegError.Kind
+const (
+	ErrorKindUnknown          = ffutil.ErrorKindUnknown          // Not recognised; inspect Stderr
+	ErrorKindInputNotFound    = ffutil.ErrorKindInputNotFound    // Input file or URL does not exist
+	ErrorKindUnsupportedCodec = ffutil.ErrorKindUnsupportedCodec // No decoder, or codec not allowed in the container
+	ErrorKindInvalidData      = ffutil.ErrorKindInvalidData      // Corrupt or unrecognised input
+	ErrorKindDiskFull         = ffutil.ErrorKindDiskFull         // No space left while writing output
+	ErrorKindEncoderMissing   = ffutil.ErrorKindEncoderMissing   // Requested encoder is not compiled into ffmpeg
+	ErrorKindPermissionDenied = ffutil.ErrorKindPermissionDenied // Input or output not readable/writable
+)
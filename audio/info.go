@@ -1,10 +1,9 @@
 package audio
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
 )
 
 // Info contains information about an audio file
@@ -12,6 +11,8 @@ type Info struct {
 	Duration      float64
 	SampleRate    int
 	Channels      int
+	ChannelLayout string // e.g. "mono", "stereo", "5.1"
+	SampleFormat  string // e.g. "s16", "fltp"
 	Codec         string
 	BitRate       int
 	FileSizeBytes int64
@@ -19,31 +20,44 @@ type Info struct {
 
 // GetInfo retrieves information about the audio file.
 // Results are cached — subsequent calls return the cached value without invoking ffprobe.
+// Stream fields describe the first audio stream; use Probe to inspect every stream.
 func (a *Audio) GetInfo() (*Info, error) {
+	return a.GetInfoContext(context.Background())
+}
+
+// GetInfoContext is like GetInfo but stops ffprobe when ctx is cancelled or its
+// deadline passes.
+func (a *Audio) GetInfoContext(ctx context.Context) (*Info, error) {
+	return a.getInfo(ctx)
+}
+
+func (a *Audio) getInfo(ctx context.Context) (*Info, error) {
 	if a.info != nil {
 		copy := *a.info
 		return &copy, nil
 	}
 
-	output, err := ffprobeAudio(a.path)
+	probe, err := a.probe(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get audio info: %w", err)
 	}
 
-	info := &Info{}
-	lines := strings.Split(string(output), "\n")
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "sample_rate=") {
-			info.SampleRate, _ = strconv.Atoi(strings.TrimPrefix(line, "sample_rate="))
-		} else if strings.HasPrefix(line, "channels=") {
-			info.Channels, _ = strconv.Atoi(strings.TrimPrefix(line, "channels="))
-		} else if strings.HasPrefix(line, "codec_name=") {
-			info.Codec = strings.TrimPrefix(line, "codec_name=")
-		} else if strings.HasPrefix(line, "bit_rate=") {
-			info.BitRate, _ = strconv.Atoi(strings.TrimPrefix(line, "bit_rate="))
-		} else if strings.HasPrefix(line, "duration=") {
-			info.Duration, _ = strconv.ParseFloat(strings.TrimPrefix(line, "duration="), 64)
+	info := &Info{Duration: probe.Format.Duration}
+
+	if streams := probe.AudioStreams(); len(streams) > 0 {
+		s := streams[0]
+		info.SampleRate = s.SampleRate
+		info.Channels = s.Channels
+		info.ChannelLayout = s.ChannelLayout
+		info.SampleFormat = s.SampleFormat
+		info.Codec = s.CodecName
+		info.BitRate = int(s.BitRate)
+		if info.BitRate == 0 {
+			// Some containers (e.g. FLAC, Matroska) only report the overall bit rate
+			info.BitRate = int(probe.Format.BitRate)
+		}
+		if info.Duration == 0 {
+			info.Duration = s.Duration
 		}
 	}
 
@@ -1,11 +1,14 @@
 package audio
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestNew_ValidFile(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	path := fixture("no-silence.wav")
@@ -28,6 +31,7 @@ func TestNew_FileNotFound(t *testing.T) {
 }
 
 func TestNew_PermissionDenied(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	if os.Getuid() == 0 {
@@ -65,6 +69,7 @@ func TestNew_PermissionDenied(t *testing.T) {
 }
 
 func TestGetInfo_ReturnsCorrectMetadata(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -100,6 +105,7 @@ func TestGetInfo_ReturnsCorrectMetadata(t *testing.T) {
 }
 
 func TestGetInfo_Cached(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -123,6 +129,7 @@ func TestGetInfo_Cached(t *testing.T) {
 }
 
 func TestGetInfo_CopyNotShared(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -148,3 +155,59 @@ func TestGetInfo_CopyNotShared(t *testing.T) {
 			info2.SampleRate, originalSampleRate)
 	}
 }
+
+func TestGetInfo_CorruptFile_FFmpegError(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "corrupt.wav")
+	if err := os.WriteFile(path, []byte("definitely not media"), 0644); err != nil {
+		t.Fatalf("failed to write corrupt file: %v", err)
+	}
+
+	a, err := New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, err = a.GetInfo()
+	var ffErr *FFmpegError
+	if !errors.As(err, &ffErr) {
+		t.Fatalf("expected *FFmpegError, got %T: %v", err, err)
+	}
+	if ffErr.Binary != "ffprobe" {
+		t.Errorf("Binary = %q, want ffprobe", ffErr.Binary)
+	}
+	if ffErr.Kind != ErrorKindInvalidData {
+		t.Errorf("Kind = %q, want %q (stderr: %s)", ffErr.Kind, ErrorKindInvalidData, ffErr.Stderr)
+	}
+	if ffErr.ExitCode == 0 {
+		t.Error("ExitCode should be non-zero")
+	}
+}
+
+func TestProbe_DescribesAudioStream(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("no-silence.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	probe, err := a.Probe()
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+
+	streams := probe.AudioStreams()
+	if len(streams) != 1 {
+		t.Fatalf("got %d audio streams, want 1", len(streams))
+	}
+	if streams[0].SampleRate <= 0 || streams[0].Channels <= 0 || streams[0].SampleFormat == "" {
+		t.Errorf("audio stream = %+v, want sample rate, channels and sample format", streams[0])
+	}
+	if len(probe.VideoStreams()) != 0 {
+		t.Errorf("got %d video streams, want 0", len(probe.VideoStreams()))
+	}
+}
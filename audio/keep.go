@@ -0,0 +1,211 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// CutMethod selects how kept segments are cut out of the source and joined.
+type CutMethod string
+
+const (
+	// CutMethodCopy encodes the segments in parallel, each with a short fade, and
+	// joins them without re-encoding (default). Fast, but every segment is padded to a
+	// whole packet of the codec (1152 samples for MP3, 1024 for AAC), so each join can
+	// add up to a packet, about 25 ms, and long edits drift from the segment times.
+	CutMethodCopy CutMethod = "copy"
+	// CutMethodAccurate renders the whole edit in one ffmpeg pass with atrim/concat
+	// filters. Cuts are sample-exact and the output is as long as the segments, but
+	// it is encoded in a single pass with the output format's default encoder.
+	CutMethodAccurate CutMethod = "accurate"
+)
+
+// validate reports an unknown cut method.
+func (m CutMethod) validate() error {
+	switch m {
+	case "", CutMethodCopy, CutMethodAccurate:
+		return nil
+	default:
+		return fmt.Errorf("unknown cut method %q", m)
+	}
+}
+
+// CutConfig contains configuration for KeepSegments and RemoveSegments
+type CutConfig struct {
+	// Method selects how segments are cut and joined (default: CutMethodCopy).
+	Method CutMethod
+
+	// OnProgress, if set, receives progress updates while extracting and joining segments.
+	OnProgress ProgressFunc
+}
+
+// KeepSegments writes a new audio made of the given segments of this one, in source
+// order. Segments are clamped to the file, and overlapping ones are merged.
+// Works like RemoveSilence: segments are extracted in parallel and each one gets a
+// short fade at its boundaries to prevent clicks.
+func (a *Audio) KeepSegments(outputPath string, segments []Segment, config CutConfig) error {
+	return a.KeepSegmentsContext(context.Background(), outputPath, segments, config)
+}
+
+// KeepSegmentsContext is like KeepSegments but can be cancelled through ctx, with the
+// same cleanup as RemoveSilenceContext.
+func (a *Audio) KeepSegmentsContext(ctx context.Context, outputPath string, segments []Segment, config CutConfig) error {
+	if err := config.Method.validate(); err != nil {
+		return err
+	}
+	info, err := a.getInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get audio info: %w", err)
+	}
+	segments = ffutil.NormalizeSegments(segments, info.Duration)
+	if len(segments) == 0 {
+		return fmt.Errorf("no segments to keep")
+	}
+	return a.keepSegments(ctx, outputPath, segments, config.Method, ffutil.NewProgressTracker(config.OnProgress), 0)
+}
+
+// RemoveSegments writes a new audio without the given segments, keeping everything
+// else. It is the inverse of KeepSegments.
+func (a *Audio) RemoveSegments(outputPath string, segments []Segment, config CutConfig) error {
+	return a.RemoveSegmentsContext(context.Background(), outputPath, segments, config)
+}
+
+// RemoveSegmentsContext is like RemoveSegments but can be cancelled through ctx.
+func (a *Audio) RemoveSegmentsContext(ctx context.Context, outputPath string, segments []Segment, config CutConfig) error {
+	if err := config.Method.validate(); err != nil {
+		return err
+	}
+	info, err := a.getInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get audio info: %w", err)
+	}
+	kept := ffutil.InvertSegments(segments, info.Duration)
+	if len(kept) == 0 {
+		return fmt.Errorf("nothing left after removing segments")
+	}
+	return a.keepSegments(ctx, outputPath, kept, config.Method, ffutil.NewProgressTracker(config.OnProgress), 0)
+}
+
+// keepSegments joins segments of the source into outputPath using method.
+// segments must be sorted and not overlap. Progress is reported through tracker
+// from progressStart to 100 percent.
+func (a *Audio) keepSegments(ctx context.Context, outputPath string, segments []Segment, method CutMethod, tracker *ffutil.ProgressTracker, progressStart float64) error {
+	if method == CutMethodAccurate {
+		clips := ffutil.SegmentClips(segments)
+		stage := tracker.Stage(StageRender, progressStart, 100, ffutil.ClipsDuration(clips), 1)
+		return a.renderClips(ctx, outputPath, clips, nil, stage)
+	}
+	return a.extractAndConcat(ctx, outputPath, segments, tracker, progressStart)
+}
+
+// extractAndConcat extracts segments in parallel with audio fades and joins them
+// with the concat demuxer.
+func (a *Audio) extractAndConcat(ctx context.Context, outputPath string, segments []Segment, tracker *ffutil.ProgressTracker, progressStart float64) error {
+	tempDir, err := os.MkdirTemp("", "ffmpego_cut_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	ext := filepath.Ext(a.path)
+	if ext == "" {
+		ext = ".mp3"
+	}
+
+	var keptDuration float64
+	for _, seg := range segments {
+		keptDuration += seg.Duration
+	}
+
+	extractStage := tracker.Stage(StageExtract, progressStart, extractProgressEnd, keptDuration, len(segments))
+	segmentPaths := make([]string, len(segments))
+	errs := make([]error, len(segments))
+
+	maxWorkers := 4
+	if len(segments) < maxWorkers {
+		maxWorkers = len(segments)
+	}
+
+	var wg sync.WaitGroup
+	jobs := make(chan int, len(segments))
+
+	for w := 0; w < maxWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// Drain remaining jobs without starting ffmpeg once ctx is done
+				if ctx.Err() != nil {
+					continue
+				}
+				seg := segments[i]
+				path := filepath.Join(tempDir, fmt.Sprintf("seg_%03d%s", i, ext))
+				segmentPaths[i] = path
+				errs[i] = a.extractSegmentWithAudioFade(ctx, path, seg.StartTime, seg.EndTime, extractStage.Reporter(i))
+			}
+		}()
+	}
+
+	for i := range segments {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("cutting interrupted: %w", err)
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("failed to extract segment %d: %w", i+1, err)
+		}
+	}
+
+	extractStage.Done()
+
+	metadataPath, err := a.writeMetadata(ctx, tempDir, ffutil.SegmentClips(segments))
+	if err != nil {
+		return err
+	}
+
+	concatStage := tracker.Stage(StageConcat, extractProgressEnd, 100, keptDuration, 1)
+	return a.concatenate(ctx, segmentPaths, outputPath, nil, metadataPath, concatStage)
+}
+
+// extractSegmentWithAudioFade extracts an audio segment with a short fade-in/fade-out
+// at the boundaries. Every audio track is kept and faded, and cover art is copied.
+//
+// This is used by RemoveSilence and KeepSegments. When audio is cut at arbitrary points,
+// the waveform rarely lands on a zero-crossing, which produces audible clicks after
+// concatenation. The fade smooths these transitions without perceptibly affecting volume.
+func (a *Audio) extractSegmentWithAudioFade(ctx context.Context, outputPath string, startTime, endTime float64, onReport func(ffutil.ProgressReport)) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	duration := endTime - startTime
+	fadeFilter := ffutil.AudioFadeFilter(duration, ffutil.DefaultFadeDurationSec)
+
+	args := []string{
+		"-ss", fmt.Sprintf("%.3f", startTime),
+		"-i", a.path,
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-map", "0:a",
+		"-map", "0:v?",
+		"-c:v", "copy",
+		"-af", fadeFilter,
+		"-y", outputPath,
+	}
+
+	_, err := ffutil.RunWithProgress(ctx, a.runner, a.ffmpeg, args, onReport)
+	if err != nil {
+		return fmt.Errorf("failed to extract segment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package audio
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestKeepSegments(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("no-silence.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	segments := []Segment{
+		{StartTime: 3.0, EndTime: 4.0},
+		{StartTime: 0.5, EndTime: 1.5},
+		{StartTime: 1.0, EndTime: 2.0}, // overlaps the previous one
+	}
+	if err := a.KeepSegments(out, segments, CutConfig{}); err != nil {
+		t.Fatalf("KeepSegments: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 2.5, 0.5)
+}
+
+func TestRemoveSegments(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("no-silence.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	if err := a.RemoveSegments(out, []Segment{{StartTime: 1.0, EndTime: 3.0}}, CutConfig{}); err != nil {
+		t.Fatalf("RemoveSegments: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 3.0, 0.5)
+}
+
+func TestKeepSegments_NothingToKeep(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("no-silence.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	if err := a.KeepSegments(out, []Segment{{StartTime: 20, EndTime: 30}}, CutConfig{}); err == nil {
+		t.Error("expected an error for segments outside the file, got nil")
+	}
+	if err := a.RemoveSegments(out, []Segment{{StartTime: 0, EndTime: 30}}, CutConfig{}); err == nil {
+		t.Error("expected an error when removing everything, got nil")
+	}
+}
+
+func TestKeepSegments_Accurate(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("no-silence.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	segments := []Segment{
+		{StartTime: 0.5, EndTime: 1.5},
+		{StartTime: 3.0, EndTime: 4.2},
+	}
+	if err := a.KeepSegments(out, segments, CutConfig{Method: CutMethodAccurate}); err != nil {
+		t.Fatalf("KeepSegments: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 2.2, 0.2)
+}
+
+func TestKeepSegments_UnknownMethod(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("no-silence.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	err = a.KeepSegments(out, []Segment{{StartTime: 0, EndTime: 1}}, CutConfig{Method: "bogus"})
+	if err == nil {
+		t.Error("expected an error for an unknown cut method, got nil")
+	}
+}
@@ -0,0 +1,75 @@
+package audio
+
+import "github.com/meunomeebero/ffmpego/internal/ffutil"
+
+// Runner executes the ffmpeg and ffprobe commands issued by an Audio.
+//
+// Run must block until the command exits and should stop the command when ctx is
+// done. stdin may be nil; stdout and stderr may be the same writer.
+type Runner = ffutil.Runner
+
+// Option configures an Audio created with New.
+type Option func(*Audio)
+
+// WithRunner makes the Audio run ffmpeg and ffprobe through r instead of starting
+// local processes. Use it to run commands in a container or on a remote host, to
+// record invocations, or to fake ffmpeg in unit tests.
+//
+// The PATH check for ffmpeg/ffprobe is skipped when a custom runner is used, since
+// the binaries may not exist on the local machine.
+func WithRunner(r Runner) Option {
+	return func(a *Audio) {
+		if r != nil {
+			a.runner = r
+		}
+	}
+}
+
+// WithFFmpegPath sets the ffmpeg binary to use, either a name looked up in PATH or a
+// path to an executable. Defaults to "ffmpeg". Useful when several ffmpeg builds are
+// installed side by side.
+func WithFFmpegPath(path string) Option {
+	return func(a *Audio) {
+		if path != "" {
+			a.ffmpeg = path
+		}
+	}
+}
+
+// WithFFprobePath sets the ffprobe binary to use, either a name looked up in PATH or
+// a path to an executable. Defaults to "ffprobe".
+func WithFFprobePath(path string) Option {
+	return func(a *Audio) {
+		if path != "" {
+			a.ffprobe = path
+		}
+	}
+}
+
+// WithProbe gives the Audio a probe already taken of its file, such as the one from
+// media.Open, so the file isn't probed again. It only applies to the file named by
+// probe.Format.Filename, which is the path ffprobe was given; instances derived for
+// other files, such as segments, probe their own.
+func WithProbe(probe *ProbeResult) Option {
+	return func(a *Audio) {
+		if probe != nil && probe.Format.Filename == a.path {
+			a.probed = probe.Clone()
+		}
+	}
+}
+
+// newWithOptions returns an Audio for path with defaults applied and opts on top.
+// It does not validate anything.
+func newWithOptions(path string, opts []Option) *Audio {
+	a := &Audio{
+		path:    path,
+		runner:  ffutil.ExecRunner{},
+		ffmpeg:  ffutil.DefaultFFmpeg,
+		ffprobe: ffutil.DefaultFFprobe,
+		opts:    opts,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
@@ -0,0 +1,106 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingRunner answers ffprobe with canned output and records every invocation
+// instead of running a process.
+type recordingRunner struct {
+	mu    sync.Mutex
+	probe string
+	calls [][]string
+}
+
+func (r *recordingRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	r.mu.Lock()
+	r.calls = append(r.calls, append([]string{name}, args...))
+	r.mu.Unlock()
+	if name == "ffprobe" {
+		io.WriteString(stdout, r.probe)
+	}
+	return nil
+}
+
+func TestWithRunner_GetInfoUsesRunner(t *testing.T) {
+	t.Parallel()
+
+	r := &recordingRunner{probe: `{
+		"streams": [{"index": 0, "codec_type": "audio", "codec_name": "opus", "sample_rate": "48000", "channels": 2}],
+		"format": {"duration": "12.500000"}
+	}`}
+	a, err := New(placeholderFile(t, "no-silence.wav"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	info, err := a.GetInfo()
+	if err != nil {
+		t.Fatalf("GetInfo: %v", err)
+	}
+	if info.SampleRate != 48000 || info.Codec != "opus" {
+		t.Errorf("got %d Hz %q, want 48000 Hz opus from fake runner", info.SampleRate, info.Codec)
+	}
+	if info.Duration != 12.5 {
+		t.Errorf("Duration = %.3f, want 12.5 from fake runner", info.Duration)
+	}
+	if len(r.calls) == 0 || r.calls[0][0] != "ffprobe" {
+		t.Errorf("expected ffprobe to go through the runner, got %v", r.calls)
+	}
+}
+
+func TestWithRunner_ExtractSegmentArgs(t *testing.T) {
+	t.Parallel()
+
+	r := &recordingRunner{}
+	a, err := New(placeholderFile(t, "no-silence.wav"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "segment.wav")
+	if err := a.ExtractSegment(out, 1.0, 3.0, nil); err != nil {
+		t.Fatalf("ExtractSegment: %v", err)
+	}
+
+	if len(r.calls) != 1 {
+		t.Fatalf("expected 1 runner call, got %d", len(r.calls))
+	}
+	got := strings.Join(r.calls[0], " ")
+	want := "ffmpeg -ss 1.000 -i " + a.Path() + " -t 2.000 -c copy -y " + out
+	if got != want {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+}
+
+func TestWithFFprobePath_MissingBinary(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	_, err := New(fixture("no-silence.wav"), WithFFprobePath("/nonexistent/ffprobe"))
+	if err == nil {
+		t.Fatal("expected error for missing ffprobe binary, got nil")
+	}
+}
+
+func TestConvert_UnavailableEncoder(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("no-silence.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "out.ogg")
+	err = a.Convert(outputPath, ConvertConfig{Codec: "libdoesnotexist"})
+	if !errors.Is(err, ErrEncoderNotAvailable) {
+		t.Fatalf("expected ErrEncoderNotAvailable, got %v", err)
+	}
+}
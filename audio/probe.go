@@ -0,0 +1,54 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// ProbeResult is the full ffprobe description of a media file: every stream, the
+// container format and chapters.
+type ProbeResult = ffutil.ProbeResult
+
+// Stream describes one stream (video, audio, subtitle, data or attachment).
+type Stream = ffutil.Stream
+
+// Disposition holds a stream's disposition flags.
+type Disposition = ffutil.Disposition
+
+// Format describes the container.
+type Format = ffutil.Format
+
+// Chapter describes a chapter marker.
+type Chapter = ffutil.Chapter
+
+// Stream types reported in Stream.CodecType
+const (
+	StreamTypeVideo      = ffutil.StreamTypeVideo
+	StreamTypeAudio      = ffutil.StreamTypeAudio
+	StreamTypeSubtitle   = ffutil.StreamTypeSubtitle
+	StreamTypeData       = ffutil.StreamTypeData
+	StreamTypeAttachment = ffutil.StreamTypeAttachment
+)
+
+// Probe describes all streams, the container format and the chapters of the file.
+// Results are cached — subsequent calls return the cached value without invoking ffprobe.
+func (a *Audio) Probe() (*ProbeResult, error) {
+	result, err := a.probe(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe file: %w", err)
+	}
+	return result, nil
+}
+
+func (a *Audio) probe(ctx context.Context) (*ProbeResult, error) {
+	if a.probed == nil {
+		result, err := ffutil.Probe(ctx, a.runner, a.ffprobe, a.path)
+		if err != nil {
+			return nil, err
+		}
+		a.probed = result
+	}
+	return a.probed.Clone(), nil
+}
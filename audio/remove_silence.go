@@ -1,11 +1,8 @@
 package audio
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"sync"
 
 	"github.com/meunomeebero/ffmpego/internal/ffutil"
 )
@@ -15,91 +12,65 @@ import (
 // Uses parallel extraction. Each segment gets a short audio fade at its boundaries
 // to prevent clicks and pops when concatenated.
 func (a *Audio) RemoveSilence(outputPath string, config SilenceConfig) error {
-	segments, err := a.GetNonSilentSegments(config)
-	if err != nil {
-		return fmt.Errorf("failed to detect segments: %w", err)
-	}
+	return a.RemoveSilenceContext(context.Background(), outputPath, config)
+}
 
-	if len(segments) == 0 {
-		return fmt.Errorf("no audible content found above the configured threshold")
-	}
+// RemoveSilenceContext is like RemoveSilence but can be cancelled through ctx.
+// On cancellation or deadline the running ffmpeg processes are killed, no further
+// segments are extracted, temporary files are removed and the returned error wraps
+// ctx.Err().
+func (a *Audio) RemoveSilenceContext(ctx context.Context, outputPath string, config SilenceConfig) error {
+	_, err := a.RemoveSilenceWithTimelineContext(ctx, outputPath, config)
+	return err
+}
 
-	tempDir, err := os.MkdirTemp("", "ffmpego_silence_*")
-	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
-	}
-	defer os.RemoveAll(tempDir)
+// RemoveSilenceWithTimeline is like RemoveSilence but also returns the Timeline of the
+// edit, for translating times between the source and the output.
+func (a *Audio) RemoveSilenceWithTimeline(outputPath string, config SilenceConfig) (*Timeline, error) {
+	return a.RemoveSilenceWithTimelineContext(context.Background(), outputPath, config)
+}
 
-	ext := filepath.Ext(a.path)
-	if ext == "" {
-		ext = ".mp3"
+// RemoveSilenceWithTimelineContext is like RemoveSilenceWithTimeline but can be
+// cancelled through ctx, as RemoveSilenceContext.
+func (a *Audio) RemoveSilenceWithTimelineContext(ctx context.Context, outputPath string, config SilenceConfig) (*Timeline, error) {
+	if err := config.validateMode(); err != nil {
+		return nil, err
 	}
 
-	segmentPaths := make([]string, len(segments))
-	errs := make([]error, len(segments))
+	tracker := ffutil.NewProgressTracker(config.OnProgress)
 
-	maxWorkers := 4
-	if len(segments) < maxWorkers {
-		maxWorkers = len(segments)
+	segments, err := a.nonSilentSegments(ctx, config, tracker, detectProgressEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect segments: %w", err)
 	}
 
-	var wg sync.WaitGroup
-	jobs := make(chan int, len(segments))
-
-	for w := 0; w < maxWorkers; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for i := range jobs {
-				seg := segments[i]
-				path := filepath.Join(tempDir, fmt.Sprintf("seg_%03d%s", i, ext))
-				segmentPaths[i] = path
-				errs[i] = a.extractSegmentWithAudioFade(path, seg.StartTime, seg.EndTime)
-			}
-		}()
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("no audible content found above the configured threshold")
 	}
 
-	for i := range segments {
-		jobs <- i
+	info, err := a.getInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audio info: %w", err)
 	}
-	close(jobs)
-	wg.Wait()
 
-	for i, err := range errs {
+	if config.Mode == SilenceModeCrossfade {
+		clips, err := a.crossfadeSilence(ctx, outputPath, segments, config, tracker)
 		if err != nil {
-			return fmt.Errorf("failed to extract segment %d: %w", i+1, err)
+			return nil, err
 		}
+		return ffutil.NewTimeline(clips, info.Duration), nil
 	}
 
-	return ConcatenateSegments(segmentPaths, outputPath, nil)
-}
-
-// extractSegmentWithAudioFade extracts an audio segment with a short fade-in/fade-out
-// at the boundaries.
-//
-// This is used exclusively by RemoveSilence. When audio is cut at arbitrary points,
-// the waveform rarely lands on a zero-crossing, which produces audible clicks after
-// concatenation. The fade smooths these transitions without perceptibly affecting volume.
-func (a *Audio) extractSegmentWithAudioFade(outputPath string, startTime, endTime float64) error {
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	duration := endTime - startTime
-	fadeFilter := ffutil.AudioFadeFilter(duration, ffutil.DefaultFadeDurationSec)
-
-	args := []string{
-		"-ss", fmt.Sprintf("%.3f", startTime),
-		"-i", a.path,
-		"-t", fmt.Sprintf("%.3f", duration),
-		"-af", fadeFilter,
-		"-y", outputPath,
+	if config.Mode == SilenceModeSpeedUp {
+		clips, err := a.speedUpSilence(ctx, outputPath, segments, config, tracker)
+		if err != nil {
+			return nil, err
+		}
+		return ffutil.NewTimeline(clips, info.Duration), nil
 	}
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("FFmpeg error: %w - %s", err, string(output))
+	if err := a.keepSegments(ctx, outputPath, segments, config.CutMethod, tracker, detectProgressEnd); err != nil {
+		return nil, err
 	}
-	return nil
+	return ffutil.NewTimeline(ffutil.SegmentClips(segments), info.Duration), nil
 }
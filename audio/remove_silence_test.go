@@ -1,6 +1,8 @@
 package audio
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"sync"
@@ -27,6 +29,7 @@ func tempDirNames(t *testing.T, prefix string) map[string]struct{} {
 // --- RemoveSilence tests ---
 
 func TestRemoveSilence_ProducesShorterOutput(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("silence-middle.wav"))
@@ -65,6 +68,7 @@ func TestRemoveSilence_ProducesShorterOutput(t *testing.T) {
 }
 
 func TestRemoveSilence_NoSilence_CopiesFile(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -85,6 +89,7 @@ func TestRemoveSilence_NoSilence_CopiesFile(t *testing.T) {
 }
 
 func TestRemoveSilence_AllSilence(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("all-silence.wav"))
@@ -104,6 +109,7 @@ func TestRemoveSilence_AllSilence(t *testing.T) {
 }
 
 func TestRemoveSilence_ShortFile(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("short.wav"))
@@ -129,6 +135,7 @@ func TestRemoveSilence_ShortFile(t *testing.T) {
 }
 
 func TestRemoveSilence_TempFilesCleanedUp(t *testing.T) {
+	requireFFmpeg(t)
 	// NOT parallel — runs after all parallel tests complete, so no
 	// other test is creating ffmpego_silence_* dirs concurrently.
 	const prefix = "ffmpego_silence_"
@@ -159,6 +166,7 @@ func TestRemoveSilence_TempFilesCleanedUp(t *testing.T) {
 // --- Parallel tests ---
 
 func TestParallel_SameInput_DifferentOutputs(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	config := SilenceConfig{}
@@ -191,6 +199,7 @@ func TestParallel_SameInput_DifferentOutputs(t *testing.T) {
 }
 
 func TestParallel_DifferentInputs(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	inputs := []string{"silence-start.wav", "silence-end.wav"}
@@ -224,6 +233,7 @@ func TestParallel_DifferentInputs(t *testing.T) {
 }
 
 func TestParallel_SameOutput_NoPanic(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	outputPath := filepath.Join(t.TempDir(), "out.wav")
@@ -257,6 +267,7 @@ func TestParallel_SameOutput_NoPanic(t *testing.T) {
 }
 
 func TestParallel_RemoveSilence_Concurrent(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	config := SilenceConfig{}
@@ -290,6 +301,7 @@ func TestParallel_RemoveSilence_Concurrent(t *testing.T) {
 }
 
 func TestParallel_TempFilesIsolated(t *testing.T) {
+	requireFFmpeg(t)
 	// NOT parallel — runs after all parallel tests complete, so the
 	// snapshot/diff approach works without false positives from sibling tests.
 	const prefix = "ffmpego_silence_"
@@ -320,3 +332,208 @@ func TestParallel_TempFilesIsolated(t *testing.T) {
 		}
 	}
 }
+
+// --- Context tests ---
+
+func TestRemoveSilenceContext_Canceled(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("silence-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	outputPath := filepath.Join(t.TempDir(), "out.wav")
+	err = a.RemoveSilenceContext(ctx, outputPath, SilenceConfig{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error wrapping context.Canceled, got %v", err)
+	}
+	if _, statErr := os.Stat(outputPath); statErr == nil {
+		t.Errorf("output %q should not exist after cancellation", outputPath)
+	}
+}
+
+func TestConvertContext_Canceled(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("no-silence.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	outputPath := filepath.Join(t.TempDir(), "out.mp3")
+	err = a.ConvertContext(ctx, outputPath, ConvertConfig{Codec: CodecMP3})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error wrapping context.Canceled, got %v", err)
+	}
+}
+
+// --- Progress tests ---
+
+func TestRemoveSilence_ReportsProgress(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("silence-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var updates []Progress
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		OnProgress:         func(p Progress) { updates = append(updates, p) },
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "out.wav")
+	if err := a.RemoveSilence(outputPath, config); err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	if len(updates) == 0 {
+		t.Fatal("expected progress updates, got none")
+	}
+
+	last := 0.0
+	for _, p := range updates {
+		if p.Percent < last {
+			t.Errorf("percent moved backwards: %.1f -> %.1f", last, p.Percent)
+		}
+		last = p.Percent
+	}
+	if last != 100 {
+		t.Errorf("final percent = %.1f, want 100", last)
+	}
+}
+
+func TestRemoveSilence_SpeedUp(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("silence-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		Mode:               SilenceModeSpeedUp,
+		SpeedUpFactor:      4,
+	}
+	if err := a.RemoveSilence(out, config); err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	// 2s tone + 2s silence at 4x + 2s tone
+	assertValidMedia(t, out)
+	assertDuration(t, out, 4.5, 0.4)
+}
+
+func TestRemoveSilence_Crossfade(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("silence-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		Mode:               SilenceModeCrossfade,
+		CrossfadeDuration:  300,
+		CrossfadeCurve:     CrossfadeLinear,
+	}
+	timeline, err := a.RemoveSilenceWithTimeline(out, config)
+	if err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	// 2s tone + 2s tone, overlapping by 0.3s
+	assertValidMedia(t, out)
+	assertDuration(t, out, 3.7, 0.4)
+	if got := timeline.OutputDuration(); got < 3.3 || got > 4.1 {
+		t.Errorf("timeline output duration = %.2f, want about 3.7", got)
+	}
+}
+
+func TestRemoveSilence_InvalidMode(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("silence-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	if err := a.RemoveSilence(out, SilenceConfig{Mode: "bogus"}); err == nil {
+		t.Error("expected an error for an unknown mode, got nil")
+	}
+	if err := a.RemoveSilence(out, SilenceConfig{Mode: SilenceModeSpeedUp, SpeedUpFactor: 0.5}); err == nil {
+		t.Error("expected an error for SpeedUpFactor below 1, got nil")
+	}
+	if err := a.RemoveSilence(out, SilenceConfig{Mode: SilenceModeCrossfade, CrossfadeCurve: "wobbly"}); err == nil {
+		t.Error("expected an error for an unknown crossfade curve, got nil")
+	}
+}
+
+func TestRemoveSilence_MaxPauseDuration(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("silence-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		MaxPauseDuration:   500,
+	}
+	if err := a.RemoveSilence(out, config); err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	// 2s tone + 2s silence shortened to 0.5s + 2s tone
+	assertValidMedia(t, out)
+	assertDuration(t, out, 4.5, 0.4)
+}
+
+func TestRemoveSilence_AccurateCut(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("silence-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.wav")
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		CutMethod:          CutMethodAccurate,
+	}
+	if err := a.RemoveSilence(out, config); err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 4.0, 0.4)
+}
@@ -0,0 +1,98 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// speedUpSilence renders the audio with segments at normal speed and the silent
+// parts between them sped up by config.SpeedUpFactor, keeping the pitch. It returns
+// the clips it played.
+func (a *Audio) speedUpSilence(ctx context.Context, outputPath string, segments []Segment, config SilenceConfig, tracker *ffutil.ProgressTracker) ([]ffutil.Clip, error) {
+	info, err := a.getInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audio info: %w", err)
+	}
+	clips := ffutil.SpeedUpClips(segments, info.Duration, config.speedUpFactor())
+	stage := tracker.Stage(StageRender, detectProgressEnd, 100, ffutil.ClipsDuration(clips), 1)
+	if err := a.renderClips(ctx, outputPath, clips, nil, stage); err != nil {
+		return nil, err
+	}
+	return clips, nil
+}
+
+// crossfadeSilence renders segments into outputPath with each cut overlapped by a
+// crossfade, and returns the clips of the source visible in the output.
+func (a *Audio) crossfadeSilence(ctx context.Context, outputPath string, segments []Segment, config SilenceConfig, tracker *ffutil.ProgressTracker) ([]ffutil.Clip, error) {
+	xfade := config.crossfade()
+	clips := ffutil.SegmentClips(segments)
+	visible := ffutil.CrossfadeClips(clips, xfade.Duration)
+	stage := tracker.Stage(StageRender, detectProgressEnd, 100, ffutil.ClipsDuration(visible), 1)
+	if err := a.renderClips(ctx, outputPath, clips, &xfade, stage); err != nil {
+		return nil, err
+	}
+	return visible, nil
+}
+
+// renderClips renders clips of the source into outputPath in a single ffmpeg pass,
+// re-encoding with the output format's default encoder. Every audio track is cut
+// the same way, and container metadata and chapters are carried over.
+// With xfade set, neighbouring clips are crossfaded instead of faded at the cuts.
+func (a *Audio) renderClips(ctx context.Context, outputPath string, clips []ffutil.Clip, xfade *ffutil.Crossfade, stage *ffutil.StageProgress) error {
+	if len(clips) == 0 {
+		return fmt.Errorf("no segments to render")
+	}
+
+	tempDir, err := os.MkdirTemp("", "ffmpego_render_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	probe, err := a.probe(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get audio info: %w", err)
+	}
+	audioTracks := max(len(probe.AudioStreams()), 1)
+
+	visible := clips
+	if xfade != nil {
+		visible = ffutil.CrossfadeClips(clips, xfade.Duration)
+	}
+	metadataPath, err := a.writeMetadata(ctx, tempDir, visible)
+	if err != nil {
+		return err
+	}
+
+	graph := ffutil.ConcatFilterTracks(clips, false, audioTracks, ffutil.DefaultFadeDurationSec)
+	if xfade != nil {
+		graph = ffutil.CrossfadeFilterTracks(clips, false, audioTracks, *xfade)
+	}
+	filterArgs, err := ffutil.FilterComplexArgs(graph, tempDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	args := append([]string{"-i", a.path}, metadataInputArgs(metadataPath)...)
+	args = append(args, filterArgs...)
+	for track := 0; track < audioTracks; track++ {
+		args = append(args, "-map", ffutil.AudioOutputLabel(track))
+	}
+	args = append(args, mapMetadataArgs(1)...)
+	args = append(args, "-y", outputPath)
+
+	_, err = ffutil.RunWithProgress(ctx, a.runner, a.ffmpeg, args, stage.Reporter(0))
+	if err != nil {
+		return fmt.Errorf("failed to render: %w", err)
+	}
+	stage.Done()
+	return nil
+}
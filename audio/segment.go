@@ -1,9 +1,10 @@
 package audio
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -16,6 +17,12 @@ type Segment = ffutil.Segment
 // ExtractSegment extracts a segment from the audio file.
 // Pass nil for config to use stream copy (fastest, no quality loss).
 func (a *Audio) ExtractSegment(outputPath string, startTime, endTime float64, config *ConvertConfig) error {
+	return a.ExtractSegmentContext(context.Background(), outputPath, startTime, endTime, config)
+}
+
+// ExtractSegmentContext is like ExtractSegment but stops ffmpeg when ctx is cancelled
+// or its deadline passes. The returned error then wraps ctx.Err().
+func (a *Audio) ExtractSegmentContext(ctx context.Context, outputPath string, startTime, endTime float64, config *ConvertConfig) error {
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
@@ -27,25 +34,70 @@ func (a *Audio) ExtractSegment(outputPath string, startTime, endTime float64, co
 		"-t", fmt.Sprintf("%.3f", endTime-startTime),
 	}
 
+	var stage *ffutil.StageProgress
 	if config != nil {
 		args = append(args, buildConvertArgs(config)...)
+		stage = ffutil.NewProgressTracker(config.OnProgress).Stage(StageConvert, 0, 100, endTime-startTime, 1)
 	} else {
 		args = append(args, "-c", "copy")
 	}
 
 	args = append(args, "-y", outputPath)
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
+	_, err := ffutil.RunWithProgress(ctx, a.runner, a.ffmpeg, args, stage.Reporter(0))
 	if err != nil {
-		return fmt.Errorf("FFmpeg error: %w - %s", err, string(output))
+		return fmt.Errorf("failed to extract segment: %w", err)
 	}
+	stage.Done()
 	return nil
 }
 
 // ConcatenateSegments concatenates multiple audio segment files into a single audio.
-// Pass nil for config to use stream copy (fastest, no quality loss).
-func ConcatenateSegments(segmentPaths []string, outputPath string, config *ConvertConfig) error {
+// Pass nil for config, or a config that only sets OnProgress, to use stream copy
+// (fastest, no quality loss). opts configure how ffmpeg is run, as for New.
+func ConcatenateSegments(segmentPaths []string, outputPath string, config *ConvertConfig, opts ...Option) error {
+	return ConcatenateSegmentsContext(context.Background(), segmentPaths, outputPath, config, opts...)
+}
+
+// ConcatenateSegmentsContext is like ConcatenateSegments but stops ffmpeg when ctx is
+// cancelled or its deadline passes. The returned error then wraps ctx.Err().
+func ConcatenateSegmentsContext(ctx context.Context, segmentPaths []string, outputPath string, config *ConvertConfig, opts ...Option) error {
+	base := newWithOptions("", opts)
+
+	var stage *ffutil.StageProgress
+	if config != nil && config.OnProgress != nil {
+		total, err := base.totalDuration(ctx, segmentPaths)
+		if err != nil {
+			return err
+		}
+		stage = ffutil.NewProgressTracker(config.OnProgress).Stage(StageConcat, 0, 100, total, 1)
+	}
+	return base.concatenate(ctx, segmentPaths, outputPath, config, "", stage)
+}
+
+// totalDuration returns the summed duration of the given media files, probing them
+// with the same options as a.
+func (a *Audio) totalDuration(ctx context.Context, paths []string) (float64, error) {
+	var total float64
+	for _, path := range paths {
+		seg, err := New(path, a.opts...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open segment: %w", err)
+		}
+		info, err := seg.getInfo(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get audio info: %w", err)
+		}
+		total += info.Duration
+	}
+	return total, nil
+}
+
+// concatenate joins segmentPaths into outputPath, reporting progress through stage.
+// When stream copying, every stream of the segments is kept. If metadataPath is set,
+// the output takes its global metadata and chapters from that ffmetadata file.
+// a only supplies the runner and options; its path is not used.
+func (a *Audio) concatenate(ctx context.Context, segmentPaths []string, outputPath string, config *ConvertConfig, metadataPath string, stage *ffutil.StageProgress) error {
 	if len(segmentPaths) == 0 {
 		return fmt.Errorf("no segments to concatenate")
 	}
@@ -69,8 +121,7 @@ func ConcatenateSegments(segmentPaths []string, outputPath string, config *Conve
 		if strings.ContainsAny(absPath, "\n\r") {
 			return fmt.Errorf("segment path contains invalid characters: %s", segmentPath)
 		}
-		escaped := strings.ReplaceAll(absPath, "'", "'\\''")
-		if _, err := fmt.Fprintf(fileList, "file '%s'\n", escaped); err != nil {
+		if _, err := io.WriteString(fileList, ffutil.ConcatListEntry(absPath)); err != nil {
 			return fmt.Errorf("failed to write file list: %w", err)
 		}
 	}
@@ -88,19 +139,25 @@ func ConcatenateSegments(segmentPaths []string, outputPath string, config *Conve
 		"-safe", "0",
 		"-i", fileListPath,
 	}
+	if metadataPath != "" {
+		args = append(args, metadataInputArgs(metadataPath)...)
+	}
 
-	if config != nil {
+	if config.needsReencoding() {
 		args = append(args, buildConvertArgs(config)...)
 	} else {
-		args = append(args, "-c", "copy")
+		args = append(args, "-map", "0", "-c", "copy")
+	}
+	if metadataPath != "" {
+		args = append(args, mapMetadataArgs(1)...)
 	}
 
 	args = append(args, "-y", outputPath)
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
+	_, err = ffutil.RunWithProgress(ctx, a.runner, a.ffmpeg, args, stage.Reporter(0))
 	if err != nil {
-		return fmt.Errorf("failed to concatenate segments: %w - %s", err, string(output))
+		return fmt.Errorf("failed to concatenate segments: %w", err)
 	}
+	stage.Done()
 	return nil
 }
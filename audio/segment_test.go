@@ -7,6 +7,7 @@ import (
 )
 
 func TestExtractSegment_StreamCopy(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -24,6 +25,7 @@ func TestExtractSegment_StreamCopy(t *testing.T) {
 }
 
 func TestExtractSegment_WithConfig(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -41,6 +43,7 @@ func TestExtractSegment_WithConfig(t *testing.T) {
 }
 
 func TestExtractSegment_OutputDirCreated(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -59,6 +62,7 @@ func TestExtractSegment_OutputDirCreated(t *testing.T) {
 }
 
 func TestConcatenateSegments_Basic(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -91,6 +95,7 @@ func TestConcatenateSegments_Basic(t *testing.T) {
 }
 
 func TestConcatenateSegments_SingleSegment(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -1,9 +1,9 @@
 package audio
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
-	"strings"
+	"math"
 
 	"github.com/meunomeebero/ffmpego/internal/ffutil"
 )
@@ -15,8 +15,34 @@ const (
 	SilenceThresholdModerate    = -30 // Balanced - good for most audio
 	SilenceThresholdRelaxed     = -20 // Only loud parts are considered non-silence
 	SilenceThresholdVeryRelaxed = -10 // Only very loud parts are considered non-silence
+
+	// SilenceThresholdAuto is no level but asks for one to be chosen per file, as
+	// AutoThreshold does, falling back to the default threshold.
+	SilenceThresholdAuto = math.MinInt32
+)
+
+// SilenceDetector selects how silence is told apart from sound.
+type SilenceDetector string
+
+const (
+	// DetectorLevel uses ffmpeg's silencedetect: anything louder than
+	// SilenceThreshold is sound (default).
+	DetectorLevel SilenceDetector = "level"
+	// DetectorVoice keeps only speech. The audio is band-limited to the voice range
+	// and every 20 ms frame is judged by its energy, its spectral flatness and whether
+	// the level around it rises and falls with syllables, so fans, hiss, keyboard
+	// clicks and music count as silence however loud they are. SilenceThreshold is
+	// then the lowest speech energy in dB RMS (default: -45).
+	DetectorVoice SilenceDetector = "voice"
 )
 
+// defaultVoiceThreshold is the lowest speech energy for DetectorVoice, in dB RMS
+const defaultVoiceThreshold = -45
+
+// ThresholdEstimate is the silence threshold chosen for SilenceConfig.AutoThreshold, with
+// the levels it was chosen from.
+type ThresholdEstimate = ffutil.ThresholdEstimate
+
 // Common minimum silence durations in milliseconds
 const (
 	SilenceDurationVeryShort = 200  // 0.2 seconds - very sensitive
@@ -26,43 +52,301 @@ const (
 	SilenceDurationVeryLong  = 2000 // 2 seconds - very conservative
 )
 
+// SilenceMode selects what RemoveSilence does with silent parts.
+type SilenceMode string
+
+const (
+	// SilenceModeCut removes silent parts (default).
+	SilenceModeCut SilenceMode = "cut"
+	// SilenceModeSpeedUp keeps silent parts but plays them faster, by SpeedUpFactor.
+	// The whole file is re-encoded in one pass with the output format's default encoders.
+	SilenceModeSpeedUp SilenceMode = "speedup"
+	// SilenceModeCrossfade removes silent parts like SilenceModeCut but overlaps the
+	// kept segments with a crossfade of CrossfadeDuration instead of fading each one
+	// out and in, so music and ambient sound carry on without a dip at the cuts.
+	// The whole file is re-encoded in one pass with the output format's default encoders.
+	SilenceModeCrossfade SilenceMode = "crossfade"
+)
+
+// CrossfadeCurve is the shape of the fades in SilenceModeCrossfade. Any curve of
+// ffmpeg's acrossfade filter can be used; these are the common ones.
+type CrossfadeCurve string
+
+const (
+	CrossfadeEqualPower  CrossfadeCurve = "qsin" // Quarter sine; keeps the loudness steady for unrelated material (default)
+	CrossfadeLinear      CrossfadeCurve = "tri"  // Straight line; best when both sides are the same continuous sound
+	CrossfadeExponential CrossfadeCurve = "exp"
+	CrossfadeLogarithmic CrossfadeCurve = "log"
+)
+
+// AudioStreamAll selects every audio stream for silence detection; a moment counts
+// as silent only when all of them are silent.
+const AudioStreamAll = ffutil.AudioStreamAll
+
+// defaultSpeedUpFactor is the playback speed of silent parts in SilenceModeSpeedUp
+const defaultSpeedUpFactor = 4.0
+
+// defaultCrossfadeDuration is the overlap at each cut in SilenceModeCrossfade, in milliseconds
+const defaultCrossfadeDuration = 200
+
+// defaultMinSegmentDuration is the shortest non-silent segment kept, in milliseconds
+const defaultMinSegmentDuration = 500
+
 // SilenceConfig contains configuration for silence detection
 type SilenceConfig struct {
 	MinSilenceDuration int // Minimum silence duration in milliseconds (use SilenceDuration constants)
 	SilenceThreshold   int // Silence threshold in dB (use SilenceThreshold constants)
+
+	// AutoThreshold measures the file before detecting silence and picks the threshold
+	// between its noise floor and its speech level instead of SilenceThreshold. When
+	// the file has no distinct quiet and loud parts, such as a recording without
+	// pauses, SilenceThreshold (or its default) is used after all. Setting
+	// SilenceThreshold to SilenceThresholdAuto does the same with the default.
+	AutoThreshold bool
+
+	// Detector selects how silence is detected (default: DetectorLevel).
+	Detector SilenceDetector
+
+	// AudioStream selects which audio stream silence is judged on: "" for ffmpeg's
+	// default stream, a position among the audio streams ("1" is the second track), a
+	// language tag ("eng"), a comma-separated list of these, or AudioStreamAll. With
+	// several streams a moment counts as silent only when all of them are silent.
+	AudioStream string
+	// Channel, if set, listens to a single channel of the selected streams, counting
+	// from 1 (1 is the left channel of a stereo track). 0 mixes all channels.
+	Channel int
+
+	// Segment refinement, all in milliseconds. Nearby segments are merged first, then
+	// short ones are dropped, then the rest are padded; overlaps after padding are merged.
+	PaddingBefore      int // Time kept before each non-silent segment, so word onsets and breaths aren't clipped
+	PaddingAfter       int // Time kept after each non-silent segment
+	MergeGap           int // Join segments separated by less than this
+	MinSegmentDuration int // Drop segments shorter than this (default: 500; negative keeps every segment)
+
+	// MaxPauseDuration, if set, shortens pauses longer than this many milliseconds to
+	// this length instead of removing them, keeping half on each side. Shorter pauses
+	// are left alone. The kept pause is part of the returned segments.
+	MaxPauseDuration int
+
+	// Mode selects what RemoveSilence does with silent parts (default: SilenceModeCut).
+	Mode SilenceMode
+	// SpeedUpFactor is how much faster silent parts play in SilenceModeSpeedUp (default: 4).
+	SpeedUpFactor float64
+	// CrossfadeDuration is the overlap at each cut in SilenceModeCrossfade, in
+	// milliseconds (default: 200). It is shortened where a segment is too short for it.
+	CrossfadeDuration int
+	// CrossfadeCurve is the fade shape in SilenceModeCrossfade (default: CrossfadeEqualPower).
+	CrossfadeCurve CrossfadeCurve
+	// CutMethod selects how SilenceModeCut joins the kept segments (default:
+	// CutMethodCopy). Use CutMethodAccurate for frame-exact cuts.
+	CutMethod CutMethod
+
+	// OnProgress, if set, receives progress updates while detecting silence and, for
+	// RemoveSilence, while extracting and joining segments.
+	OnProgress ProgressFunc
+	// OnThreshold, if set, receives the threshold chosen for AutoThreshold, before
+	// silence is detected with it. ThresholdEstimate.Fallback tells when it is
+	// SilenceThreshold because the file gave nothing to choose from. With
+	// DetectorVoice it is called once per analysed audio stream.
+	OnThreshold func(ThresholdEstimate)
 }
 
 // GetNonSilentSegments detects silent segments in the audio and returns non-silent segments.
 // If no silence is detected, returns the entire file as a single segment.
 func (a *Audio) GetNonSilentSegments(config SilenceConfig) ([]Segment, error) {
+	return a.GetNonSilentSegmentsContext(context.Background(), config)
+}
+
+// GetNonSilentSegmentsContext is like GetNonSilentSegments but stops ffmpeg when ctx
+// is cancelled or its deadline passes. The returned error then wraps ctx.Err().
+func (a *Audio) GetNonSilentSegmentsContext(ctx context.Context, config SilenceConfig) ([]Segment, error) {
+	tracker := ffutil.NewProgressTracker(config.OnProgress)
+	return a.nonSilentSegments(ctx, config, tracker, 100)
+}
+
+// nonSilentSegments runs silence detection, reporting progress through tracker as the
+// [0, progressEnd] percent range of the overall operation.
+func (a *Audio) nonSilentSegments(ctx context.Context, config SilenceConfig, tracker *ffutil.ProgressTracker, progressEnd float64) ([]Segment, error) {
+	config = config.resolveAutoThreshold()
 	if config.SilenceThreshold == 0 {
 		config.SilenceThreshold = SilenceThresholdModerate
+		if config.Detector == DetectorVoice {
+			config.SilenceThreshold = defaultVoiceThreshold
+		}
 	}
 	if config.MinSilenceDuration == 0 {
 		config.MinSilenceDuration = SilenceDurationMedium
 	}
+	switch config.Detector {
+	case "", DetectorLevel, DetectorVoice:
+	default:
+		return nil, fmt.Errorf("unknown silence detector %q", config.Detector)
+	}
 
-	info, err := a.GetInfo()
+	info, err := a.getInfo(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get audio info: %w", err)
 	}
+	opts, err := a.detectOptions(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	jobs := max(len(opts.Streams), 1)
+
+	if config.Detector == DetectorVoice {
+		// The voice detector chooses automatic thresholds from its own measurements
+		voice := ffutil.VoiceDetectOptions{
+			SilenceDetectOptions: opts,
+			AutoThreshold:        config.AutoThreshold,
+			OnThreshold:          config.OnThreshold,
+		}
+		stage := tracker.Stage(StageDetect, 0, progressEnd, info.Duration*float64(jobs), jobs)
+		segments, err := ffutil.DetectVoice(ctx, a.runner, a.ffmpeg, a.path, info.Duration, voice, stage)
+		if err != nil {
+			return nil, err
+		}
+		return ffutil.RefineSegments(segments, info.Duration, config.segmentOptions()), nil
+	}
+
+	// Measuring the loudness takes one more pass over the file, so it gets half of the range
+	detectFrom := 0.0
+	if config.AutoThreshold {
+		detectFrom = progressEnd / 2
+		stage := tracker.Stage(StageDetect, 0, detectFrom, info.Duration*float64(jobs), jobs)
+		estimate, err := ffutil.AutoSilenceThreshold(ctx, a.runner, a.ffmpeg, a.path, opts, stage)
+		if err != nil {
+			return nil, err
+		}
+		if config.OnThreshold != nil {
+			config.OnThreshold(estimate)
+		}
+		opts.Threshold = estimate.Threshold
+	}
 
-	silenceLenSec := float64(config.MinSilenceDuration) / 1000.0
+	stage := tracker.Stage(StageDetect, detectFrom, progressEnd, info.Duration*float64(jobs), jobs)
+	segments, err := ffutil.DetectNonSilent(ctx, a.runner, a.ffmpeg, a.path, info.Duration, opts, stage)
+	if err != nil {
+		return nil, err
+	}
+	return ffutil.RefineSegments(segments, info.Duration, config.segmentOptions()), nil
+}
 
-	cmd := exec.Command("ffmpeg",
-		"-i", a.path,
-		"-af", fmt.Sprintf("silencedetect=noise=%ddB:d=%.3f", config.SilenceThreshold, silenceLenSec),
-		"-f", "null", "-")
+// EstimateSilenceThreshold measures the audio and returns the threshold that
+// config.AutoThreshold would use with DetectorLevel, listening to config.AudioStream and config.Channel.
+// Files without distinct quiet and loud parts get config.SilenceThreshold (default:
+// SilenceThresholdModerate), with Fallback set.
+// Progress is reported through config.OnProgress.
+func (a *Audio) EstimateSilenceThreshold(config SilenceConfig) (ThresholdEstimate, error) {
+	return a.EstimateSilenceThresholdContext(context.Background(), config)
+}
 
-	output, err := cmd.CombinedOutput()
-	outputStr := string(output)
+// EstimateSilenceThresholdContext is like EstimateSilenceThreshold but stops ffmpeg
+// when ctx is cancelled or its deadline passes.
+func (a *Audio) EstimateSilenceThresholdContext(ctx context.Context, config SilenceConfig) (ThresholdEstimate, error) {
+	info, err := a.getInfo(ctx)
 	if err != nil {
-		// silencedetect always exits non-zero with -f null; only fail if no silence data was produced
-		if !strings.Contains(outputStr, "silence_start") && !strings.Contains(outputStr, "silence_end") {
-			return nil, fmt.Errorf("failed to detect silence: %w - %s", err, outputStr)
-		}
+		return ThresholdEstimate{}, fmt.Errorf("failed to get audio info: %w", err)
+	}
+	opts, err := a.detectOptions(ctx, config.resolveAutoThreshold())
+	if err != nil {
+		return ThresholdEstimate{}, err
 	}
+	if opts.Threshold == 0 {
+		opts.Threshold = SilenceThresholdModerate
+	}
+	jobs := max(len(opts.Streams), 1)
+	stage := ffutil.NewProgressTracker(config.OnProgress).Stage(StageDetect, 0, 100, info.Duration*float64(jobs), jobs)
+	return ffutil.AutoSilenceThreshold(ctx, a.runner, a.ffmpeg, a.path, opts, stage)
+}
+
+// detectOptions resolves the audio selection of config against the file's streams.
+func (a *Audio) detectOptions(ctx context.Context, config SilenceConfig) (ffutil.SilenceDetectOptions, error) {
+	probe, err := a.probe(ctx)
+	if err != nil {
+		return ffutil.SilenceDetectOptions{}, fmt.Errorf("failed to probe file: %w", err)
+	}
+	streams, err := ffutil.SelectAudioStreams(probe.AudioStreams(), config.AudioStream, config.Channel)
+	if err != nil {
+		return ffutil.SilenceDetectOptions{}, err
+	}
+	return ffutil.SilenceDetectOptions{
+		Threshold:   config.SilenceThreshold,
+		MinDuration: float64(config.MinSilenceDuration) / 1000.0,
+		Streams:     streams,
+		Channel:     config.Channel,
+	}, nil
+}
 
-	starts, ends := ffutil.ParseSilenceOutput(outputStr)
-	return ffutil.BuildNonSilentSegments(starts, ends, info.Duration, 0.5), nil
+// resolveAutoThreshold turns SilenceThresholdAuto into AutoThreshold with the
+// default threshold as the fallback.
+func (c SilenceConfig) resolveAutoThreshold() SilenceConfig {
+	if c.SilenceThreshold == SilenceThresholdAuto {
+		c.SilenceThreshold = 0
+		c.AutoThreshold = true
+	}
+	return c
+}
+
+// validateMode checks the settings that only RemoveSilence uses.
+func (c SilenceConfig) validateMode() error {
+	switch c.Mode {
+	case "", SilenceModeCut, SilenceModeSpeedUp, SilenceModeCrossfade:
+	default:
+		return fmt.Errorf("unknown silence mode %q", c.Mode)
+	}
+	if err := c.CutMethod.validate(); err != nil {
+		return err
+	}
+	if c.MaxPauseDuration < 0 {
+		return fmt.Errorf("invalid MaxPauseDuration %d: must not be negative", c.MaxPauseDuration)
+	}
+	if c.SpeedUpFactor != 0 && c.SpeedUpFactor < 1 {
+		return fmt.Errorf("invalid SpeedUpFactor %g: must be at least 1", c.SpeedUpFactor)
+	}
+	if c.CrossfadeDuration < 0 {
+		return fmt.Errorf("invalid CrossfadeDuration %d: must not be negative", c.CrossfadeDuration)
+	}
+	if c.CrossfadeCurve != "" && !ffutil.ValidCrossfadeCurve(string(c.CrossfadeCurve)) {
+		return fmt.Errorf("unknown crossfade curve %q", c.CrossfadeCurve)
+	}
+	return nil
+}
+
+// speedUpFactor returns the configured speed-up factor, applying the default.
+func (c SilenceConfig) speedUpFactor() float64 {
+	if c.SpeedUpFactor == 0 {
+		return defaultSpeedUpFactor
+	}
+	return c.SpeedUpFactor
+}
+
+// crossfade converts the crossfade settings, applying defaults.
+func (c SilenceConfig) crossfade() ffutil.Crossfade {
+	duration := c.CrossfadeDuration
+	if duration == 0 {
+		duration = defaultCrossfadeDuration
+	}
+	curve := c.CrossfadeCurve
+	if curve == "" {
+		curve = CrossfadeEqualPower
+	}
+	return ffutil.Crossfade{
+		Duration: float64(duration) / 1000.0,
+		Curve:    string(curve),
+	}
+}
+
+// segmentOptions converts the millisecond refinement settings, applying defaults.
+func (c SilenceConfig) segmentOptions() ffutil.SegmentOptions {
+	minSegment := c.MinSegmentDuration
+	if minSegment == 0 {
+		minSegment = defaultMinSegmentDuration
+	}
+	return ffutil.SegmentOptions{
+		PaddingBefore:      float64(c.PaddingBefore) / 1000.0,
+		PaddingAfter:       float64(c.PaddingAfter) / 1000.0,
+		MergeGap:           float64(c.MergeGap) / 1000.0,
+		MinSegmentDuration: float64(minSegment) / 1000.0,
+		MaxPause:           float64(c.MaxPauseDuration) / 1000.0,
+	}
 }
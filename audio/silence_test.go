@@ -5,6 +5,7 @@ import (
 )
 
 func TestGetNonSilentSegments_SilenceAtStart(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("silence-start.wav"))
@@ -36,6 +37,7 @@ func TestGetNonSilentSegments_SilenceAtStart(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_SilenceMiddle(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("silence-middle.wav"))
@@ -59,6 +61,7 @@ func TestGetNonSilentSegments_SilenceMiddle(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_SilenceAtEnd(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("silence-end.wav"))
@@ -96,6 +99,7 @@ func TestGetNonSilentSegments_SilenceAtEnd(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_NoSilence(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("no-silence.wav"))
@@ -130,6 +134,7 @@ func TestGetNonSilentSegments_NoSilence(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_AllSilence(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("all-silence.wav"))
@@ -153,6 +158,7 @@ func TestGetNonSilentSegments_AllSilence(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_ZeroValueConfig(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	a, err := New(fixture("silence-middle.wav"))
@@ -170,3 +176,149 @@ func TestGetNonSilentSegments_ZeroValueConfig(t *testing.T) {
 		t.Fatalf("expected at least 1 segment with zero-value config, got %d", len(segments))
 	}
 }
+
+func TestGetNonSilentSegments_Padding(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("silence-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+	}
+	plain, err := a.GetNonSilentSegments(config)
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+
+	config.PaddingBefore = 300
+	config.PaddingAfter = 300
+	padded, err := a.GetNonSilentSegments(config)
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments with padding: %v", err)
+	}
+
+	if len(plain) != 2 || len(padded) != 2 {
+		t.Fatalf("expected 2 segments with and without padding, got %d and %d", len(plain), len(padded))
+	}
+	const tolerance = 0.01
+	if diff := padded[0].EndTime - plain[0].EndTime; diff < 0.3-tolerance || diff > 0.3+tolerance {
+		t.Errorf("first segment end moved by %.3fs, want 0.3s", diff)
+	}
+	if diff := plain[1].StartTime - padded[1].StartTime; diff < 0.3-tolerance || diff > 0.3+tolerance {
+		t.Errorf("second segment start moved by %.3fs, want 0.3s", diff)
+	}
+	if padded[0].StartTime != 0 {
+		t.Errorf("padding before the first segment should clamp to 0, got %.3f", padded[0].StartTime)
+	}
+}
+
+func TestGetNonSilentSegments_MergeGap(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("silence-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// The 2s pause is shorter than MergeGap, so both tones end up in one segment
+	segments, err := a.GetNonSilentSegments(SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		MergeGap:           3000,
+	})
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+
+	if len(segments) != 1 {
+		t.Fatalf("expected segments to merge into 1, got %d: %+v", len(segments), segments)
+	}
+}
+
+func TestGetNonSilentSegments_AutoThreshold(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("noisy-middle.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// The noise never drops below the fixed threshold, so nothing counts as silent
+	fixed, err := a.GetNonSilentSegments(SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+	})
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+	if len(fixed) != 1 {
+		t.Fatalf("expected the noise to hide the pause at -30 dB, got %d segments", len(fixed))
+	}
+
+	var chosen ThresholdEstimate
+	segments, err := a.GetNonSilentSegments(SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		AutoThreshold:      true,
+		OnThreshold:        func(e ThresholdEstimate) { chosen = e },
+	})
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Errorf("expected 2 segments around the pause, got %d: %+v", len(segments), segments)
+	}
+	if chosen.Threshold <= int(chosen.NoiseFloor) || float64(chosen.Threshold) >= chosen.SpeechLevel {
+		t.Errorf("threshold %d dB is not between the noise floor %.1f dB and speech %.1f dB",
+			chosen.Threshold, chosen.NoiseFloor, chosen.SpeechLevel)
+	}
+
+	estimate, err := a.EstimateSilenceThreshold(SilenceConfig{})
+	if err != nil {
+		t.Fatalf("EstimateSilenceThreshold: %v", err)
+	}
+	if estimate != chosen {
+		t.Errorf("EstimateSilenceThreshold = %+v, want %+v", estimate, chosen)
+	}
+	preset, err := a.GetNonSilentSegments(SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdAuto,
+	})
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+	if len(preset) != len(segments) {
+		t.Errorf("SilenceThresholdAuto gave %d segments, AutoThreshold %d", len(preset), len(segments))
+	}
+}
+
+func TestGetNonSilentSegments_VoiceDetector(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	a, err := New(fixture("noisy-speech.wav"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	segments, err := a.GetNonSilentSegments(SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		Detector:           DetectorVoice,
+	})
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Errorf("expected 2 segments around the pause, got %d: %+v", len(segments), segments)
+	}
+
+	if _, err := a.GetNonSilentSegments(SilenceConfig{Detector: "psychic"}); err == nil {
+		t.Error("expected an error for an unknown detector, got nil")
+	}
+}
@@ -0,0 +1,46 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// writeMetadata writes the source's container metadata, and its chapters moved onto
+// the output timeline of clips, to an ffmetadata file in dir.
+func (a *Audio) writeMetadata(ctx context.Context, dir string, clips []ffutil.Clip) (string, error) {
+	probe, err := a.probe(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to probe file: %w", err)
+	}
+
+	path := filepath.Join(dir, "metadata.txt")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create metadata file: %w", err)
+	}
+	defer f.Close()
+
+	chapters := ffutil.RemapChapters(probe.Chapters, clips)
+	if err := ffutil.WriteFFMetadata(f, probe.Format.Tags, chapters); err != nil {
+		return "", fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	return path, nil
+}
+
+// metadataInputArgs adds an ffmetadata file written by writeMetadata as an input.
+func metadataInputArgs(path string) []string {
+	return []string{"-f", "ffmetadata", "-i", path}
+}
+
+// mapMetadataArgs takes the global metadata and chapters from the given input.
+func mapMetadataArgs(input int) []string {
+	n := fmt.Sprint(input)
+	return []string{"-map_metadata", n, "-map_chapters", n}
+}
@@ -0,0 +1,43 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// SyncConfig contains configuration for FindOffset. Zero values select the defaults.
+type SyncConfig struct {
+	// Start is where the compared window begins in the base file, in seconds.
+	Start float64
+	// Duration is the length of the compared window in seconds (default 60). Pick a
+	// part where both recordings hear the same sound, such as speech or a clap.
+	Duration float64
+	// MaxOffset is the largest offset searched for, either way, in seconds (default 30).
+	MaxOffset float64
+}
+
+// SyncEstimate is the offset found between two recordings, with how confident the
+// match is.
+type SyncEstimate = ffutil.SyncEstimate
+
+func (c SyncConfig) options() ffutil.SyncOptions {
+	return ffutil.SyncOptions{Start: c.Start, Duration: c.Duration, MaxOffset: c.MaxOffset}
+}
+
+// FindOffset estimates where other starts on the audio's timeline by cross-correlating
+// the first audio stream of each file: sound at t in other is at t+Offset in a. Video
+// files opened with New work as well.
+func (a *Audio) FindOffset(other *Audio, config SyncConfig) (SyncEstimate, error) {
+	return a.FindOffsetContext(context.Background(), other, config)
+}
+
+// FindOffsetContext is like FindOffset but stops ffmpeg when ctx is cancelled or its
+// deadline passes.
+func (a *Audio) FindOffsetContext(ctx context.Context, other *Audio, config SyncConfig) (SyncEstimate, error) {
+	if other == nil {
+		return SyncEstimate{}, fmt.Errorf("no recording to compare with")
+	}
+	return ffutil.EstimateOffset(ctx, a.runner, a.ffmpeg, a.path, other.path, config.options())
+}
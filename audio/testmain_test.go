@@ -12,12 +12,17 @@ import (
 
 var testFixtureDir string
 
+// haveFFmpeg reports whether ffmpeg is installed. Without it the fixtures aren't
+// generated and the tests that need them skip, while tests using a fake Runner
+// still run.
+var haveFFmpeg bool
+
 func TestMain(m *testing.M) {
-	_, err := exec.LookPath("ffmpeg")
-	if err != nil {
-		fmt.Println("skipping: ffmpeg not found in PATH")
-		os.Exit(0)
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		fmt.Println("ffmpeg not found in PATH: skipping tests that need fixtures")
+		os.Exit(m.Run())
 	}
+	haveFFmpeg = true
 
 	dir, err := os.MkdirTemp("", "ffmpego_audio_test_*")
 	if err != nil {
@@ -38,6 +43,9 @@ func TestMain(m *testing.M) {
 }
 
 func generateAudioFixtures(dir string) error {
+	// a 220 Hz tone with two harmonics, rising and falling four times a second
+	const syllablesSrc = "aevalsrc=exprs=(0.575-0.425*cos(8*PI*t))*(0.5*sin(440*PI*t)+0.25*sin(880*PI*t)+0.125*sin(1320*PI*t)):s=44100"
+
 	// no-silence.wav: 5s continuous 440Hz tone
 	cmd := exec.Command("ffmpeg",
 		"-f", "lavfi",
@@ -89,6 +97,32 @@ func generateAudioFixtures(dir string) error {
 		return fmt.Errorf("silence-middle.wav: %w - %s", err, out)
 	}
 
+	// noisy-middle.wav: 2s tone + 2s pause + 2s tone over white noise peaking around
+	// -26 dB, too loud for SilenceThresholdModerate
+	cmd = exec.Command("ffmpeg",
+		"-f", "lavfi", "-i", "sine=frequency=440:sample_rate=44100:duration=2",
+		"-f", "lavfi", "-i", "anullsrc=r=44100:cl=mono",
+		"-f", "lavfi", "-i", "sine=frequency=440:sample_rate=44100:duration=2",
+		"-f", "lavfi", "-i", "anoisesrc=r=44100:a=0.1:d=6",
+		"-filter_complex", "[1]atrim=duration=2[s];[0][s][2]concat=n=3:v=0:a=1,volume=6[t];[t][3]amix=inputs=2:duration=first",
+		"-y", filepath.Join(dir, "noisy-middle.wav"))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("noisy-middle.wav: %w - %s", err, out)
+	}
+
+	// noisy-speech.wav: noisy-middle.wav with the tones rising and falling four times a
+	// second, like syllables
+	cmd = exec.Command("ffmpeg",
+		"-f", "lavfi", "-i", syllablesSrc+":d=2",
+		"-f", "lavfi", "-i", "anullsrc=r=44100:cl=mono",
+		"-f", "lavfi", "-i", syllablesSrc+":d=2",
+		"-f", "lavfi", "-i", "anoisesrc=r=44100:a=0.1:d=6",
+		"-filter_complex", "[1]atrim=duration=2[s];[0][s][2]concat=n=3:v=0:a=1,volume=6[t];[t][3]amix=inputs=2:duration=first",
+		"-y", filepath.Join(dir, "noisy-speech.wav"))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("noisy-speech.wav: %w - %s", err, out)
+	}
+
 	// silence-end.wav: 3s tone + 2s silence
 	cmd = exec.Command("ffmpeg",
 		"-f", "lavfi", "-i", "sine=frequency=440:sample_rate=44100:duration=3",
@@ -102,6 +136,25 @@ func generateAudioFixtures(dir string) error {
 	return nil
 }
 
+// requireFFmpeg skips the test when ffmpeg is not installed.
+func requireFFmpeg(t *testing.T) {
+	t.Helper()
+	if !haveFFmpeg {
+		t.Skip("ffmpeg not found in PATH")
+	}
+}
+
+// placeholderFile creates an empty file for tests whose Runner never reads it, so
+// that the existence check in New passes.
+func placeholderFile(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	return path
+}
+
 func fixture(name string) string {
 	return filepath.Join(testFixtureDir, name)
 }
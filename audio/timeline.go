@@ -0,0 +1,18 @@
+package audio
+
+import "github.com/meunomeebero/ffmpego/internal/ffutil"
+
+// Timeline records which source ranges an edit kept and where they landed in the
+// output. SourceToOutput and OutputToSource translate times in either direction,
+// and the value marshals to JSON.
+type Timeline = ffutil.Timeline
+
+// TimelineSegment is one kept source range and its place in the output.
+type TimelineSegment = ffutil.TimelineSegment
+
+// NewTimeline returns the Timeline of an edit that keeps segments of a source of the
+// given duration, in source order. Segments are clamped and merged as in KeepSegments.
+func NewTimeline(segments []Segment, sourceDuration float64) *Timeline {
+	segments = ffutil.NormalizeSegments(segments, sourceDuration)
+	return ffutil.NewTimeline(ffutil.SegmentClips(segments), sourceDuration)
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/meunomeebero/ffmpego/audio"
+	"github.com/meunomeebero/ffmpego/export"
+	"github.com/meunomeebero/ffmpego/media"
 	"github.com/meunomeebero/ffmpego/video"
 )
 
@@ -14,11 +16,13 @@ const usage = `ffmpego - simple media processing from the terminal
 
 Usage:
   ffmpego -rs <input> <output>    Remove silence from video or audio
+  ffmpego -cuts <input> <file>    Export cuts as an .edl, .fcpxml or .otio timeline
   ffmpego -i <input>              Print file information
 
 Examples:
   ffmpego -rs recording.mp4 clean.mp4
   ffmpego -rs podcast.mp3 clean.mp3
+  ffmpego -cuts recording.mp4 cuts.fcpxml
   ffmpego -i video.mp4
 
 Install:
@@ -33,6 +37,8 @@ func main() {
 	switch os.Args[1] {
 	case "-rs":
 		removeSilence()
+	case "-cuts":
+		exportCuts()
 	case "-i":
 		printInfo()
 	case "-h", "--help", "help":
@@ -53,9 +59,16 @@ func removeSilence() {
 	output := os.Args[3]
 	start := time.Now()
 
-	// Detect file type by trying video first, fall back to audio
-	if isVideo(input) {
-		v, err := video.New(input)
+	m, err := media.Open(input)
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+	if !m.HasAudio() {
+		log.Fatalf("error: %s has no audio track to detect silence in", input)
+	}
+
+	if m.Type() == media.TypeVideo {
+		v, err := m.AsVideo()
 		if err != nil {
 			log.Fatalf("error: %v", err)
 		}
@@ -67,7 +80,8 @@ func removeSilence() {
 		fmt.Printf("Input: %s (%dx%d, %.0ffps, %.1fs)\n", input, info.Width, info.Height, info.FrameRate, info.Duration)
 		fmt.Println("Removing silence...")
 
-		err = v.RemoveSilence(output, video.SilenceConfig{})
+		err = v.RemoveSilence(output, video.SilenceConfig{OnProgress: printProgress})
+		fmt.Println()
 		if err != nil {
 			log.Fatalf("error: %v", err)
 		}
@@ -76,7 +90,7 @@ func removeSilence() {
 		outInfo, _ := outV.GetInfo()
 		fmt.Printf("Output: %s (%.1fs, saved %.1fs)\n", output, outInfo.Duration, info.Duration-outInfo.Duration)
 	} else {
-		a, err := audio.New(input)
+		a, err := m.AsAudio()
 		if err != nil {
 			log.Fatalf("error: %v", err)
 		}
@@ -88,7 +102,8 @@ func removeSilence() {
 		fmt.Printf("Input: %s (%.1fs, %dHz)\n", input, info.Duration, info.SampleRate)
 		fmt.Println("Removing silence...")
 
-		err = a.RemoveSilence(output, audio.SilenceConfig{})
+		err = a.RemoveSilence(output, audio.SilenceConfig{OnProgress: printProgress})
+		fmt.Println()
 		if err != nil {
 			log.Fatalf("error: %v", err)
 		}
@@ -101,6 +116,67 @@ func removeSilence() {
 	fmt.Printf("Done in %.1fs\n", time.Since(start).Seconds())
 }
 
+func exportCuts() {
+	if len(os.Args) < 4 {
+		fmt.Fprintln(os.Stderr, "usage: ffmpego -cuts <input> <timeline.edl|.fcpxml|.otio>")
+		os.Exit(1)
+	}
+
+	input := os.Args[2]
+	output := os.Args[3]
+	if _, err := export.FormatFromPath(output); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	m, err := media.Open(input)
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+	if !m.HasAudio() {
+		log.Fatalf("error: %s has no audio track to detect silence in", input)
+	}
+
+	var segments []video.Segment
+	var src export.Source
+	fmt.Println("Detecting silence...")
+	if m.Type() == media.TypeVideo {
+		v, err := m.AsVideo()
+		if err != nil {
+			log.Fatalf("error: %v", err)
+		}
+		info, err := v.GetInfo()
+		if err != nil {
+			log.Fatalf("error: %v", err)
+		}
+		segments, err = v.GetNonSilentSegments(video.SilenceConfig{OnProgress: printProgress})
+		fmt.Println()
+		if err != nil {
+			log.Fatalf("error: %v", err)
+		}
+		src = export.VideoSource(input, info)
+	} else {
+		a, err := m.AsAudio()
+		if err != nil {
+			log.Fatalf("error: %v", err)
+		}
+		info, err := a.GetInfo()
+		if err != nil {
+			log.Fatalf("error: %v", err)
+		}
+		segments, err = a.GetNonSilentSegments(audio.SilenceConfig{OnProgress: printProgress})
+		fmt.Println()
+		if err != nil {
+			log.Fatalf("error: %v", err)
+		}
+		src = export.AudioSource(input, info)
+	}
+
+	if err := export.WriteFile(output, segments, src); err != nil {
+		log.Fatalf("error: %v", err)
+	}
+	fmt.Printf("Wrote %d cuts to %s\n", len(segments), output)
+}
+
 func printInfo() {
 	if len(os.Args) < 3 {
 		fmt.Fprintln(os.Stderr, "usage: ffmpego -i <input>")
@@ -109,8 +185,13 @@ func printInfo() {
 
 	input := os.Args[2]
 
-	if isVideo(input) {
-		v, err := video.New(input)
+	m, err := media.Open(input)
+	if err != nil {
+		log.Fatalf("error: %v", err)
+	}
+
+	if m.Type() == media.TypeVideo {
+		v, err := m.AsVideo()
 		if err != nil {
 			log.Fatalf("error: %v", err)
 		}
@@ -125,11 +206,15 @@ func printInfo() {
 		fmt.Printf("Resolution:  %dx%d\n", info.Width, info.Height)
 		fmt.Printf("Frame rate:  %.2f fps\n", info.FrameRate)
 		fmt.Printf("Video codec: %s\n", info.VideoCodec)
-		fmt.Printf("Audio codec: %s\n", info.AudioCodec)
+		if info.AudioCodec != "" {
+			fmt.Printf("Audio codec: %s\n", info.AudioCodec)
+		} else {
+			fmt.Printf("Audio codec: none\n")
+		}
 		fmt.Printf("Pixel fmt:   %s\n", info.PixelFormat)
 		fmt.Printf("File size:   %s\n", formatBytes(info.FileSizeBytes))
 	} else {
-		a, err := audio.New(input)
+		a, err := m.AsAudio()
 		if err != nil {
 			log.Fatalf("error: %v", err)
 		}
@@ -151,6 +236,11 @@ func printInfo() {
 	}
 }
 
+// printProgress redraws a single status line with the overall percentage.
+func printProgress(p video.Progress) {
+	fmt.Printf("\r  %3.0f%% %-8s", p.Percent, p.Stage)
+}
+
 func formatBytes(b int64) string {
 	switch {
 	case b >= 1<<30:
@@ -163,15 +253,3 @@ func formatBytes(b int64) string {
 		return fmt.Sprintf("%d B", b)
 	}
 }
-
-func isVideo(path string) bool {
-	v, err := video.New(path)
-	if err != nil {
-		return false
-	}
-	info, err := v.GetInfo()
-	if err != nil {
-		return false
-	}
-	return info.Width > 0 && info.Height > 0
-}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// maxEDLEvents is the largest event number CMX3600 can express.
+const maxEDLEvents = 999
+
+// edlRecordStartHours is where the record timeline starts, 01:00:00:00 by convention.
+const edlRecordStartHours = 1
+
+// WriteEDL writes segments as a CMX3600 EDL with non-drop-frame timecode. Source
+// timecode counts from 00:00:00:00 at the start of the file and the record
+// timeline starts at 01:00:00:00. CMX3600 allows at most 999 events.
+func WriteEDL(w io.Writer, segments []Segment, src Source) error {
+	rate := newFrameRate(src.FrameRate)
+	ranges := snap(segments, rate, src.Duration)
+	if len(ranges) > maxEDLEvents {
+		return fmt.Errorf("too many segments for an EDL: %d (CMX3600 allows %d)", len(ranges), maxEDLEvents)
+	}
+
+	channels := "AA/V"
+	switch {
+	case !src.HasAudio:
+		channels = "V"
+	case !src.HasVideo:
+		channels = "AA"
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "TITLE: %s\n", src.name())
+	fmt.Fprintf(bw, "FCM: NON-DROP FRAME\n\n")
+
+	record := edlRecordStartHours * 3600 * rate.timebase()
+	for i, r := range ranges {
+		fmt.Fprintf(bw, "%03d  AX       %-4s  C        %s %s %s %s\n",
+			i+1, channels,
+			timecode(r.Start, rate), timecode(r.End, rate),
+			timecode(record, rate), timecode(record+r.frames(), rate))
+		fmt.Fprintf(bw, "* FROM CLIP NAME: %s\n", src.name())
+		fmt.Fprintf(bw, "* SOURCE FILE: %s\n\n", src.Path)
+		record += r.frames()
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to write EDL: %w", err)
+	}
+	return nil
+}
+
+// timecode formats a frame count as non-drop-frame HH:MM:SS:FF at the rate's
+// nominal timebase.
+func timecode(frames int64, rate frameRate) string {
+	fps := rate.timebase()
+	ff := frames % fps
+	totalSeconds := frames / fps
+	return fmt.Sprintf("%02d:%02d:%02d:%02d", totalSeconds/3600, totalSeconds/60%60, totalSeconds%60, ff)
+}
@@ -0,0 +1,225 @@
+// Package export writes cut lists, such as the segments returned by
+// GetNonSilentSegments, as timelines that editing software can import: CMX3600 EDL
+// (Premiere, Resolve, Avid), FCPXML (Final Cut Pro, Resolve) and OpenTimelineIO.
+//
+// Every format places the segments back to back on the timeline. Cut points are
+// snapped to the nearest frame of the source frame rate.
+package export
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meunomeebero/ffmpego/audio"
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+	"github.com/meunomeebero/ffmpego/video"
+)
+
+// Segment is a kept time range of the source, in seconds.
+type Segment = ffutil.Segment
+
+// DefaultFrameRate is used to snap cuts when the source has no frame rate, as for
+// audio files.
+const DefaultFrameRate = 30.0
+
+// Format is a timeline file format.
+type Format string
+
+const (
+	FormatEDL    Format = "edl"    // CMX3600 EDL
+	FormatFCPXML Format = "fcpxml" // Final Cut Pro XML 1.9
+	FormatOTIO   Format = "otio"   // OpenTimelineIO JSON
+)
+
+// Source describes the media file the segments were cut from.
+type Source struct {
+	Path          string  // File the timeline links to
+	Name          string  // Clip and timeline name (default: file name of Path)
+	FrameRate     float64 // Cuts are snapped to this rate (default: DefaultFrameRate)
+	Duration      float64 // Total duration in seconds
+	HasVideo      bool
+	HasAudio      bool
+	Width         int
+	Height        int
+	SampleRate    int // Audio sample rate in Hz, if known
+	AudioChannels int
+}
+
+// VideoSource describes a video file from its Info.
+func VideoSource(path string, info *video.Info) Source {
+	return Source{
+		Path:          path,
+		FrameRate:     info.FrameRate,
+		Duration:      info.Duration,
+		HasVideo:      true,
+		HasAudio:      info.AudioCodec != "",
+		Width:         info.Width,
+		Height:        info.Height,
+		SampleRate:    info.SampleRate,
+		AudioChannels: info.AudioChannels,
+	}
+}
+
+// AudioSource describes an audio file from its Info.
+func AudioSource(path string, info *audio.Info) Source {
+	return Source{
+		Path:          path,
+		Duration:      info.Duration,
+		HasAudio:      true,
+		SampleRate:    info.SampleRate,
+		AudioChannels: info.Channels,
+	}
+}
+
+// FormatFromPath picks the format from a file extension: .edl, .fcpxml or .otio.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".edl":
+		return FormatEDL, nil
+	case ".fcpxml":
+		return FormatFCPXML, nil
+	case ".otio":
+		return FormatOTIO, nil
+	default:
+		return "", fmt.Errorf("unsupported timeline format: %s (use .edl, .fcpxml or .otio)", path)
+	}
+}
+
+// Write writes segments of src to w as a timeline in the given format.
+func Write(w io.Writer, format Format, segments []Segment, src Source) error {
+	switch format {
+	case FormatEDL:
+		return WriteEDL(w, segments, src)
+	case FormatFCPXML:
+		return WriteFCPXML(w, segments, src)
+	case FormatOTIO:
+		return WriteOTIO(w, segments, src)
+	default:
+		return fmt.Errorf("unsupported timeline format: %q", format)
+	}
+}
+
+// WriteFile writes segments of src to path, picking the format from its extension.
+func WriteFile(path string, segments []Segment, src Source) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create timeline file: %w", err)
+	}
+	if err := Write(f, format, segments, src); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write timeline file: %w", err)
+	}
+	return nil
+}
+
+// frameRange is a segment snapped to frames: [Start, End).
+type frameRange struct {
+	Start int64
+	End   int64
+}
+
+func (r frameRange) frames() int64 {
+	return r.End - r.Start
+}
+
+// snap rounds segment boundaries to the nearest frame, clamped to the source
+// duration. Segments that become empty are dropped.
+func snap(segments []Segment, rate frameRate, duration float64) []frameRange {
+	last := int64(math.MaxInt64)
+	if duration > 0 {
+		last = rate.frames(duration)
+	}
+	var ranges []frameRange
+	for _, seg := range segments {
+		r := frameRange{
+			Start: min(rate.frames(seg.StartTime), last),
+			End:   min(rate.frames(seg.EndTime), last),
+		}
+		if n := len(ranges); n > 0 && r.Start < ranges[n-1].End {
+			r.Start = ranges[n-1].End
+		}
+		if r.frames() > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// frameRate is a frame rate as the rational Num/Den frames per second.
+type frameRate struct {
+	Num int64
+	Den int64
+}
+
+// ntscRates are the integer rates whose 1000/1001 variants are recognized.
+var ntscRates = []int64{24, 30, 48, 60, 120}
+
+// newFrameRate converts a probed frame rate into an exact rational, recognizing
+// the NTSC 1000/1001 rates.
+func newFrameRate(fps float64) frameRate {
+	if fps <= 0 {
+		fps = DefaultFrameRate
+	}
+	for _, n := range ntscRates {
+		if math.Abs(fps-float64(n)*1000/1001) < 0.01 {
+			return frameRate{Num: n * 1000, Den: 1001}
+		}
+	}
+	return frameRate{Num: int64(math.Round(fps)), Den: 1}
+}
+
+// frames returns the frame nearest to t seconds.
+func (r frameRate) frames(t float64) int64 {
+	return int64(math.Round(t * float64(r.Num) / float64(r.Den)))
+}
+
+// seconds returns the time of frame n.
+func (r frameRate) seconds(n int64) float64 {
+	return float64(n) * float64(r.Den) / float64(r.Num)
+}
+
+// timebase is the nominal integer rate used for timecode and by OTIO, e.g. 30 for 29.97.
+func (r frameRate) timebase() int64 {
+	return (r.Num + r.Den - 1) / r.Den
+}
+
+// float returns the rate in frames per second.
+func (r frameRate) float() float64 {
+	return float64(r.Num) / float64(r.Den)
+}
+
+// name returns src.Name, defaulting to the file name.
+func (s Source) name() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return filepath.Base(s.Path)
+}
+
+// url returns a file:// URL for the source path.
+func (s Source) url() string {
+	path := s.Path
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // Windows drive letters
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
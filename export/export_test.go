@@ -0,0 +1,239 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meunomeebero/ffmpego/video"
+)
+
+var testSegments = []Segment{
+	{StartTime: 1.01, EndTime: 3.49, Duration: 2.48},
+	{StartTime: 5.0, EndTime: 6.0, Duration: 1.0},
+	{StartTime: 7.0, EndTime: 7.01, Duration: 0.01}, // shorter than a frame, dropped
+}
+
+var testSource = Source{
+	Path:      "/media/my clip.mp4",
+	FrameRate: 25,
+	Duration:  10,
+	HasVideo:  true,
+	HasAudio:  true,
+	Width:     1920,
+	Height:    1080,
+}
+
+func TestVideoSource(t *testing.T) {
+	info := &video.Info{
+		Width: 1920, Height: 1080, Duration: 10, FrameRate: 25,
+		AudioCodec: "aac", SampleRate: 48000, AudioChannels: 2,
+	}
+	want := Source{
+		Path: "clip.mp4", FrameRate: 25, Duration: 10, HasVideo: true, HasAudio: true,
+		Width: 1920, Height: 1080, SampleRate: 48000, AudioChannels: 2,
+	}
+	if got := VideoSource("clip.mp4", info); got != want {
+		t.Errorf("VideoSource = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteEDL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteEDL(&buf, testSegments, testSource); err != nil {
+		t.Fatalf("WriteEDL: %v", err)
+	}
+
+	want := `TITLE: my clip.mp4
+FCM: NON-DROP FRAME
+
+001  AX       AA/V  C        00:00:01:00 00:00:03:12 01:00:00:00 01:00:02:12
+* FROM CLIP NAME: my clip.mp4
+* SOURCE FILE: /media/my clip.mp4
+
+002  AX       AA/V  C        00:00:05:00 00:00:06:00 01:00:02:12 01:00:03:12
+* FROM CLIP NAME: my clip.mp4
+* SOURCE FILE: /media/my clip.mp4
+
+`
+	if got := buf.String(); got != want {
+		t.Errorf("EDL =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteEDL_AudioOnlyUsesDefaultRate(t *testing.T) {
+	src := Source{Path: "podcast.mp3", Duration: 10, HasAudio: true}
+	var buf bytes.Buffer
+	if err := WriteEDL(&buf, []Segment{{StartTime: 0.5, EndTime: 1.5, Duration: 1}}, src); err != nil {
+		t.Fatalf("WriteEDL: %v", err)
+	}
+	if !strings.Contains(buf.String(), "001  AX       AA    C        00:00:00:15 00:00:01:15") {
+		t.Errorf("unexpected EDL event:\n%s", buf.String())
+	}
+}
+
+func TestWriteEDL_TooManyEvents(t *testing.T) {
+	var segments []Segment
+	for i := 0; i < 1000; i++ {
+		segments = append(segments, Segment{StartTime: float64(i), EndTime: float64(i) + 0.5, Duration: 0.5})
+	}
+	src := testSource
+	src.Duration = 1000
+	if err := WriteEDL(&bytes.Buffer{}, segments, src); err == nil {
+		t.Fatal("expected an error for more than 999 events")
+	}
+}
+
+func TestWriteFCPXML(t *testing.T) {
+	src := testSource
+	src.FrameRate = 29.97
+	var buf bytes.Buffer
+	if err := WriteFCPXML(&buf, testSegments, src); err != nil {
+		t.Fatalf("WriteFCPXML: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header+"<!DOCTYPE fcpxml>") {
+		t.Error("missing XML header and doctype")
+	}
+
+	var doc fcpxml
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v", err)
+	}
+	if doc.Version != "1.9" {
+		t.Errorf("version = %q, want 1.9", doc.Version)
+	}
+	if got := doc.Resources.Format.FrameDuration; got != "1001/30000s" {
+		t.Errorf("frameDuration = %q, want 1001/30000s", got)
+	}
+	if got := doc.Resources.Asset.MediaRep.Src; got != "file:///media/my%20clip.mp4" {
+		t.Errorf("media src = %q", got)
+	}
+
+	clips := doc.Library.Event.Project.Sequence.Spine
+	if len(clips) != 2 {
+		t.Fatalf("got %d clips, want 2", len(clips))
+	}
+	// 1.01s at 29.97 fps is frame 30, 3.49s is frame 105
+	want := fcpClip{Ref: "r2", Name: "my clip.mp4", Offset: "0s", Start: "30030/30000s", Duration: "75075/30000s", TCFormat: "NDF"}
+	if clips[0] != want {
+		t.Errorf("clip 0 = %+v, want %+v", clips[0], want)
+	}
+	if clips[1].Offset != clips[0].Duration {
+		t.Errorf("clip 1 offset = %q, want it to follow clip 0 (%q)", clips[1].Offset, clips[0].Duration)
+	}
+}
+
+func TestWriteFCPXML_AudioRate(t *testing.T) {
+	tests := map[int]string{
+		44100: "44.1k",
+		48000: "48k",
+		96000: "96k",
+		22050: "", // not in the DTD, left out
+		0:     "",
+	}
+	for rate, want := range tests {
+		src := testSource
+		src.SampleRate = rate
+		var buf bytes.Buffer
+		if err := WriteFCPXML(&buf, testSegments, src); err != nil {
+			t.Fatalf("WriteFCPXML: %v", err)
+		}
+		var doc fcpxml
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("output is not valid XML: %v", err)
+		}
+		if got := doc.Resources.Asset.AudioRate; got != want {
+			t.Errorf("sample rate %d: audioRate = %q, want %q", rate, got, want)
+		}
+		if want == "" && strings.Contains(buf.String(), "audioRate=") {
+			t.Errorf("sample rate %d: audioRate attribute should be left out", rate)
+		}
+	}
+}
+
+func TestWriteOTIO(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteOTIO(&buf, testSegments, testSource); err != nil {
+		t.Fatalf("WriteOTIO: %v", err)
+	}
+
+	var timeline otioTimeline
+	if err := json.Unmarshal(buf.Bytes(), &timeline); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if timeline.Schema != "Timeline.1" {
+		t.Errorf("schema = %q, want Timeline.1", timeline.Schema)
+	}
+	tracks := timeline.Tracks.Children
+	if len(tracks) != 2 || tracks[0].Kind != "Video" || tracks[1].Kind != "Audio" {
+		t.Fatalf("tracks = %+v, want a video and an audio track", tracks)
+	}
+	clips := tracks[0].Children
+	if len(clips) != 2 {
+		t.Fatalf("got %d clips, want 2", len(clips))
+	}
+	r := clips[0].SourceRange
+	if r.StartTime.Value != 25 || r.Duration.Value != 62 || r.StartTime.Rate != 25 {
+		t.Errorf("source range = %+v, want start 25 duration 62 at 25 fps", r)
+	}
+	if clips[0].MediaReference.TargetURL != "file:///media/my%20clip.mp4" {
+		t.Errorf("target_url = %q", clips[0].MediaReference.TargetURL)
+	}
+}
+
+func TestSnap_ClampsAndAvoidsOverlap(t *testing.T) {
+	rate := newFrameRate(10)
+	got := snap([]Segment{
+		{StartTime: 0.0, EndTime: 1.04},
+		{StartTime: 1.06, EndTime: 2.0}, // snaps to start at frame 11 but previous ends at 10
+		{StartTime: 4.0, EndTime: 9.0},
+	}, rate, 5.0)
+
+	want := []frameRange{{0, 10}, {11, 20}, {40, 50}}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("range %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNewFrameRate(t *testing.T) {
+	tests := map[float64]frameRate{
+		23.976: {24000, 1001},
+		29.97:  {30000, 1001},
+		59.94:  {60000, 1001},
+		25:     {25, 1},
+		30:     {30, 1},
+		0:      {30, 1},
+	}
+	for fps, want := range tests {
+		if got := newFrameRate(fps); got != want {
+			t.Errorf("newFrameRate(%g) = %+v, want %+v", fps, got, want)
+		}
+	}
+}
+
+func TestWriteFile_PicksFormatFromExtension(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"cuts.edl", "cuts.fcpxml", "cuts.otio"} {
+		path := filepath.Join(dir, name)
+		if err := WriteFile(path, testSegments, testSource); err != nil {
+			t.Fatalf("WriteFile(%s): %v", name, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || len(data) == 0 {
+			t.Errorf("%s was not written: %v", name, err)
+		}
+	}
+
+	if err := WriteFile(filepath.Join(dir, "cuts.txt"), testSegments, testSource); err == nil {
+		t.Error("expected an error for an unknown extension")
+	}
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+type fcpxml struct {
+	XMLName   xml.Name     `xml:"fcpxml"`
+	Version   string       `xml:"version,attr"`
+	Resources fcpResources `xml:"resources"`
+	Library   fcpLibrary   `xml:"library"`
+}
+
+type fcpResources struct {
+	Format fcpFormat `xml:"format"`
+	Asset  fcpAsset  `xml:"asset"`
+}
+
+type fcpFormat struct {
+	ID            string `xml:"id,attr"`
+	FrameDuration string `xml:"frameDuration,attr"`
+	Width         int    `xml:"width,attr,omitempty"`
+	Height        int    `xml:"height,attr,omitempty"`
+}
+
+type fcpAsset struct {
+	ID            string      `xml:"id,attr"`
+	Name          string      `xml:"name,attr"`
+	Start         string      `xml:"start,attr"`
+	Duration      string      `xml:"duration,attr"`
+	HasVideo      string      `xml:"hasVideo,attr"`
+	HasAudio      string      `xml:"hasAudio,attr"`
+	Format        string      `xml:"format,attr"`
+	AudioSources  string      `xml:"audioSources,attr,omitempty"`
+	AudioChannels int         `xml:"audioChannels,attr,omitempty"`
+	AudioRate     string      `xml:"audioRate,attr,omitempty"`
+	MediaRep      fcpMediaRep `xml:"media-rep"`
+}
+
+type fcpMediaRep struct {
+	Kind string `xml:"kind,attr"`
+	Src  string `xml:"src,attr"`
+}
+
+type fcpLibrary struct {
+	Event fcpEvent `xml:"event"`
+}
+
+type fcpEvent struct {
+	Name    string     `xml:"name,attr"`
+	Project fcpProject `xml:"project"`
+}
+
+type fcpProject struct {
+	Name     string      `xml:"name,attr"`
+	Sequence fcpSequence `xml:"sequence"`
+}
+
+type fcpSequence struct {
+	Format   string    `xml:"format,attr"`
+	Duration string    `xml:"duration,attr"`
+	TCStart  string    `xml:"tcStart,attr"`
+	TCFormat string    `xml:"tcFormat,attr"`
+	Spine    []fcpClip `xml:"spine>asset-clip"`
+}
+
+type fcpClip struct {
+	Ref      string `xml:"ref,attr"`
+	Name     string `xml:"name,attr"`
+	Offset   string `xml:"offset,attr"`
+	Start    string `xml:"start,attr"`
+	Duration string `xml:"duration,attr"`
+	TCFormat string `xml:"tcFormat,attr"`
+}
+
+// fcpAudioRates are the audioRate values the FCPXML DTD accepts, by sample rate.
+var fcpAudioRates = map[int]string{
+	32000:  "32k",
+	44100:  "44.1k",
+	48000:  "48k",
+	88200:  "88.2k",
+	96000:  "96k",
+	176400: "176.4k",
+	192000: "192k",
+}
+
+// fcpAudioRate returns the audioRate attribute for a sample rate in Hz, or "" to leave
+// it out when the DTD has no value for the rate.
+func fcpAudioRate(sampleRate int) string {
+	return fcpAudioRates[sampleRate]
+}
+
+// WriteFCPXML writes segments as an FCPXML 1.9 project with one asset-clip per
+// segment on the primary storyline.
+func WriteFCPXML(w io.Writer, segments []Segment, src Source) error {
+	rate := newFrameRate(src.FrameRate)
+	ranges := snap(segments, rate, src.Duration)
+
+	asset := fcpAsset{
+		ID:            "r2",
+		Name:          src.name(),
+		Start:         "0s",
+		Duration:      fcpTime(rate.frames(src.Duration), rate),
+		HasVideo:      boolAttr(src.HasVideo),
+		HasAudio:      boolAttr(src.HasAudio),
+		Format:        "r1",
+		AudioChannels: src.AudioChannels,
+		AudioRate:     fcpAudioRate(src.SampleRate),
+		MediaRep:      fcpMediaRep{Kind: "original-media", Src: src.url()},
+	}
+	if src.HasAudio {
+		asset.AudioSources = "1"
+	}
+
+	var clips []fcpClip
+	var offset int64
+	for _, r := range ranges {
+		clips = append(clips, fcpClip{
+			Ref:      "r2",
+			Name:     src.name(),
+			Offset:   fcpTime(offset, rate),
+			Start:    fcpTime(r.Start, rate),
+			Duration: fcpTime(r.frames(), rate),
+			TCFormat: "NDF",
+		})
+		offset += r.frames()
+	}
+
+	doc := fcpxml{
+		Version: "1.9",
+		Resources: fcpResources{
+			Format: fcpFormat{
+				ID:            "r1",
+				FrameDuration: fcpTime(1, rate),
+				Width:         src.Width,
+				Height:        src.Height,
+			},
+			Asset: asset,
+		},
+		Library: fcpLibrary{Event: fcpEvent{
+			Name: "ffmpego",
+			Project: fcpProject{
+				Name: src.name(),
+				Sequence: fcpSequence{
+					Format:   "r1",
+					Duration: fcpTime(offset, rate),
+					TCStart:  "0s",
+					TCFormat: "NDF",
+					Spine:    clips,
+				},
+			},
+		}},
+	}
+
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE fcpxml>\n\n"); err != nil {
+		return fmt.Errorf("failed to write FCPXML: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "    ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write FCPXML: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write FCPXML: %w", err)
+	}
+	return nil
+}
+
+// fcpTime formats a frame count as an FCPXML rational time, e.g. "3003/30000s".
+func fcpTime(frames int64, rate frameRate) string {
+	if frames == 0 {
+		return "0s"
+	}
+	return fmt.Sprintf("%d/%ds", frames*rate.Den, rate.Num)
+}
+
+func boolAttr(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
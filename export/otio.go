@@ -0,0 +1,121 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type otioTimeline struct {
+	Schema          string         `json:"OTIO_SCHEMA"`
+	Name            string         `json:"name"`
+	GlobalStartTime *otioTime      `json:"global_start_time"`
+	Metadata        map[string]any `json:"metadata"`
+	Tracks          otioStack      `json:"tracks"`
+}
+
+type otioStack struct {
+	Schema   string         `json:"OTIO_SCHEMA"`
+	Name     string         `json:"name"`
+	Metadata map[string]any `json:"metadata"`
+	Children []otioTrack    `json:"children"`
+}
+
+type otioTrack struct {
+	Schema   string         `json:"OTIO_SCHEMA"`
+	Name     string         `json:"name"`
+	Kind     string         `json:"kind"`
+	Metadata map[string]any `json:"metadata"`
+	Children []otioClip     `json:"children"`
+}
+
+type otioClip struct {
+	Schema         string            `json:"OTIO_SCHEMA"`
+	Name           string            `json:"name"`
+	Metadata       map[string]any    `json:"metadata"`
+	SourceRange    otioRange         `json:"source_range"`
+	MediaReference otioExternalMedia `json:"media_reference"`
+}
+
+type otioExternalMedia struct {
+	Schema         string         `json:"OTIO_SCHEMA"`
+	Name           string         `json:"name"`
+	Metadata       map[string]any `json:"metadata"`
+	TargetURL      string         `json:"target_url"`
+	AvailableRange *otioRange     `json:"available_range"`
+}
+
+type otioRange struct {
+	Schema    string   `json:"OTIO_SCHEMA"`
+	StartTime otioTime `json:"start_time"`
+	Duration  otioTime `json:"duration"`
+}
+
+type otioTime struct {
+	Schema string  `json:"OTIO_SCHEMA"`
+	Rate   float64 `json:"rate"`
+	Value  float64 `json:"value"`
+}
+
+// WriteOTIO writes segments as an OpenTimelineIO timeline, with a video track if
+// the source has video and an audio track if it has audio.
+func WriteOTIO(w io.Writer, segments []Segment, src Source) error {
+	rate := newFrameRate(src.FrameRate)
+	ranges := snap(segments, rate, src.Duration)
+
+	newTime := func(frames int64) otioTime {
+		return otioTime{Schema: "RationalTime.1", Rate: rate.float(), Value: float64(frames)}
+	}
+	newRange := func(start, frames int64) otioRange {
+		return otioRange{Schema: "TimeRange.1", StartTime: newTime(start), Duration: newTime(frames)}
+	}
+
+	var available *otioRange
+	if src.Duration > 0 {
+		r := newRange(0, rate.frames(src.Duration))
+		available = &r
+	}
+
+	clips := make([]otioClip, 0, len(ranges))
+	for _, r := range ranges {
+		clips = append(clips, otioClip{
+			Schema:      "Clip.1",
+			Name:        src.name(),
+			Metadata:    map[string]any{},
+			SourceRange: newRange(r.Start, r.frames()),
+			MediaReference: otioExternalMedia{
+				Schema:         "ExternalReference.1",
+				Metadata:       map[string]any{},
+				TargetURL:      src.url(),
+				AvailableRange: available,
+			},
+		})
+	}
+
+	tracks := []otioTrack{}
+	if src.HasVideo {
+		tracks = append(tracks, otioTrack{Schema: "Track.1", Name: "V1", Kind: "Video", Metadata: map[string]any{}, Children: clips})
+	}
+	if src.HasAudio {
+		tracks = append(tracks, otioTrack{Schema: "Track.1", Name: "A1", Kind: "Audio", Metadata: map[string]any{}, Children: clips})
+	}
+
+	timeline := otioTimeline{
+		Schema:   "Timeline.1",
+		Name:     src.name(),
+		Metadata: map[string]any{},
+		Tracks: otioStack{
+			Schema:   "Stack.1",
+			Name:     "tracks",
+			Metadata: map[string]any{},
+			Children: tracks,
+		},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(timeline); err != nil {
+		return fmt.Errorf("failed to write OTIO: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package ffutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrEncoderNotAvailable is wrapped by errors returned when an operation needs an
+// encoder that the configured ffmpeg build does not include.
+var ErrEncoderNotAvailable = errors.New("encoder not available")
+
+// Capabilities describes what an ffmpeg build can do.
+type Capabilities struct {
+	Version  string          // Version string from "ffmpeg -version", e.g. "6.1.1"
+	Encoders map[string]bool // Encoder names from "ffmpeg -encoders", e.g. "libx264"
+	Filters  map[string]bool // Filter names from "ffmpeg -filters", e.g. "silencedetect"
+}
+
+// HasEncoder reports whether the build includes the named encoder.
+func (c *Capabilities) HasEncoder(name string) bool {
+	return c != nil && c.Encoders[name]
+}
+
+// HasFilter reports whether the build includes the named filter.
+func (c *Capabilities) HasFilter(name string) bool {
+	return c != nil && c.Filters[name]
+}
+
+// RequireEncoders returns an error wrapping ErrEncoderNotAvailable for the first
+// encoder in names that the build lacks. "copy" and empty names are ignored. If the
+// encoder list could not be read at all, nothing is rejected and ffmpeg is left to
+// report the problem itself.
+func (c *Capabilities) RequireEncoders(names ...string) error {
+	if c == nil || len(c.Encoders) == 0 {
+		return nil
+	}
+	for _, name := range names {
+		if name == "" || name == "copy" || c.Encoders[name] {
+			continue
+		}
+		return fmt.Errorf("%w: %q is not compiled into ffmpeg %s", ErrEncoderNotAvailable, name, c.Version)
+	}
+	return nil
+}
+
+var (
+	capsMu    sync.Mutex
+	capsCache = map[string]*Capabilities{}
+)
+
+// ProbeCapabilities runs "ffmpeg -version", "-encoders" and "-filters" through r and
+// parses the results. When r is the default ExecRunner the result is cached per
+// binary for the lifetime of the process, since a binary on disk does not change
+// what it was compiled with.
+func ProbeCapabilities(ctx context.Context, r Runner, ffmpeg string) (*Capabilities, error) {
+	_, cacheable := r.(ExecRunner)
+	if cacheable {
+		capsMu.Lock()
+		caps, ok := capsCache[ffmpeg]
+		capsMu.Unlock()
+		if ok {
+			return caps, nil
+		}
+	}
+
+	version, err := Output(ctx, r, ffmpeg, "-hide_banner", "-version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ffmpeg version: %w", err)
+	}
+	encoders, err := Output(ctx, r, ffmpeg, "-hide_banner", "-encoders")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ffmpeg encoders: %w", err)
+	}
+	filters, err := Output(ctx, r, ffmpeg, "-hide_banner", "-filters")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ffmpeg filters: %w", err)
+	}
+
+	caps := &Capabilities{
+		Version:  ParseVersion(string(version)),
+		Encoders: ParseEncoders(string(encoders)),
+		Filters:  ParseFilters(string(filters)),
+	}
+
+	if cacheable {
+		capsMu.Lock()
+		capsCache[ffmpeg] = caps
+		capsMu.Unlock()
+	}
+	return caps, nil
+}
+
+// ParseVersion extracts the version from "ffmpeg -version" output, e.g. "6.1.1" from
+// "ffmpeg version 6.1.1 Copyright (c) 2000-2023 the FFmpeg developers".
+func ParseVersion(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[1] == "version" {
+			return fields[2]
+		}
+	}
+	return ""
+}
+
+// ParseEncoders extracts encoder names from "ffmpeg -encoders" output. Each entry
+// looks like " V....D libx264              libx264 H.264 / AVC ...", where the first
+// column holds capability flags and starts with V, A or S.
+func ParseEncoders(output string) map[string]bool {
+	encoders := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[1] == "=" || len(fields[0]) != 6 {
+			continue
+		}
+		if !strings.ContainsRune("VAS", rune(fields[0][0])) {
+			continue
+		}
+		encoders[fields[1]] = true
+	}
+	return encoders
+}
+
+// ParseFilters extracts filter names from "ffmpeg -filters" output. Each entry looks
+// like " TSC acrossfade        AA->A      Cross fade two input audio streams.", where
+// the third column describes inputs and outputs.
+func ParseFilters(output string) map[string]bool {
+	filters := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && strings.Contains(fields[2], "->") {
+			filters[fields[1]] = true
+		}
+	}
+	return filters
+}
@@ -0,0 +1,133 @@
+package ffutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+const encodersOutput = `Encoders:
+ V..... = Video
+ A..... = Audio
+ S..... = Subtitle
+ .F.... = Frame-level multithreading
+ ..S... = Slice-level multithreading
+ ...X.. = Codec is experimental
+ ....B. = Supports draw_horiz_band
+ .....D = Supports direct rendering method 1
+ ------
+ V....D libx264              libx264 H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10 (codec h264)
+ V....D libvpx-vp9           libvpx VP9 (codec vp9)
+ A....D aac                  AAC (Advanced Audio Coding)
+ A....D libmp3lame           libmp3lame MP3 (MPEG audio layer 3) (codec mp3)
+ S..... mov_text             3GPP Timed Text subtitle
+`
+
+const filtersOutput = `Filters:
+  T.. = Timeline support
+  .S. = Slice threading
+  ..C = Command support
+  A = Audio input/output
+  V = Video input/output
+  N = Dynamic number and/or type of input/output
+  | = Source or sink filter
+ TSC acrossfade        AA->A      Cross fade two input audio streams.
+ ... silencedetect     A->A       Detect silence.
+ ... concat            N->N       Concatenate audio and video streams.
+ ... anullsrc          |->A       Null audio source, return empty audio frames.
+`
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]string{
+		"ffmpeg version 6.1.1-3ubuntu5 Copyright (c) 2000-2023 the FFmpeg developers\nbuilt with gcc 13": "6.1.1-3ubuntu5",
+		"ffmpeg version n7.0 Copyright (c) 2000-2024 the FFmpeg developers":                              "n7.0",
+		"garbage": "",
+	}
+	for output, want := range tests {
+		if got := ParseVersion(output); got != want {
+			t.Errorf("ParseVersion(%q) = %q, want %q", output, got, want)
+		}
+	}
+}
+
+func TestParseEncoders(t *testing.T) {
+	encoders := ParseEncoders(encodersOutput)
+
+	for _, name := range []string{"libx264", "libvpx-vp9", "aac", "libmp3lame", "mov_text"} {
+		if !encoders[name] {
+			t.Errorf("expected encoder %q to be parsed", name)
+		}
+	}
+	if len(encoders) != 5 {
+		t.Errorf("expected 5 encoders (legend excluded), got %d: %v", len(encoders), encoders)
+	}
+}
+
+func TestParseFilters(t *testing.T) {
+	filters := ParseFilters(filtersOutput)
+
+	for _, name := range []string{"acrossfade", "silencedetect", "concat", "anullsrc"} {
+		if !filters[name] {
+			t.Errorf("expected filter %q to be parsed", name)
+		}
+	}
+	if len(filters) != 4 {
+		t.Errorf("expected 4 filters (legend excluded), got %d: %v", len(filters), filters)
+	}
+}
+
+func TestCapabilities_RequireEncoders(t *testing.T) {
+	caps := &Capabilities{Version: "6.1", Encoders: ParseEncoders(encodersOutput)}
+
+	if err := caps.RequireEncoders("libx264", "aac", "copy", ""); err != nil {
+		t.Errorf("RequireEncoders for available encoders: %v", err)
+	}
+
+	err := caps.RequireEncoders("libx264", "libaom-av1")
+	if !errors.Is(err, ErrEncoderNotAvailable) {
+		t.Fatalf("expected ErrEncoderNotAvailable, got %v", err)
+	}
+
+	// Unknown encoder list: do not block
+	empty := &Capabilities{}
+	if err := empty.RequireEncoders("libaom-av1"); err != nil {
+		t.Errorf("RequireEncoders with unknown encoder list should not fail: %v", err)
+	}
+}
+
+// capsRunner answers the three capability probes.
+type capsRunner struct{ calls int }
+
+func (c *capsRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	c.calls++
+	switch args[len(args)-1] {
+	case "-version":
+		io.WriteString(stdout, "ffmpeg version 7.1 Copyright (c) 2000-2024\n")
+	case "-encoders":
+		io.WriteString(stdout, encodersOutput)
+	case "-filters":
+		io.WriteString(stdout, filtersOutput)
+	}
+	return nil
+}
+
+func TestProbeCapabilities_FakeRunner(t *testing.T) {
+	r := &capsRunner{}
+	caps, err := ProbeCapabilities(context.Background(), r, "/opt/ffmpeg-7/bin/ffmpeg")
+	if err != nil {
+		t.Fatalf("ProbeCapabilities: %v", err)
+	}
+	if caps.Version != "7.1" {
+		t.Errorf("Version = %q, want 7.1", caps.Version)
+	}
+	if !caps.HasEncoder("libx264") || caps.HasEncoder("libopus") {
+		t.Errorf("unexpected encoders: %v", caps.Encoders)
+	}
+	if !caps.HasFilter("silencedetect") {
+		t.Errorf("expected silencedetect filter")
+	}
+	if r.calls != 3 {
+		t.Errorf("expected 3 runner calls, got %d", r.calls)
+	}
+}
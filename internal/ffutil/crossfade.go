@@ -0,0 +1,155 @@
+package ffutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CrossfadeCurves are the fade curves accepted by ffmpeg's acrossfade filter.
+var CrossfadeCurves = []string{
+	"tri", "qsin", "esin", "hsin", "log", "ipar", "qua", "cub", "squ", "cbr",
+	"par", "exp", "iqsin", "ihsin", "dese", "desi", "losi", "sinc", "isinc", "nofade",
+}
+
+// ValidCrossfadeCurve reports whether curve is one of CrossfadeCurves.
+func ValidCrossfadeCurve(curve string) bool {
+	for _, c := range CrossfadeCurves {
+		if c == curve {
+			return true
+		}
+	}
+	return false
+}
+
+// Crossfade configures CrossfadeFilterTracks.
+type Crossfade struct {
+	Duration float64 // Overlap at each cut, in seconds of output
+	Curve    string  // acrossfade curve used for both sides of each overlap
+	Video    bool    // Dissolve the video with xfade instead of cutting it in the middle of the overlap
+}
+
+// CrossfadeDurations returns the overlap at each of the len(clips)-1 joins. Each is
+// duration, shortened to half of the shorter neighbouring clip so that consecutive
+// overlaps never meet. Clips that continue exactly where the previous one ended get
+// no overlap.
+func CrossfadeDurations(clips []Clip, duration float64) []float64 {
+	if len(clips) < 2 {
+		return nil
+	}
+	durations := make([]float64, len(clips)-1)
+	for i := range durations {
+		if clips[i].EndTime == clips[i+1].StartTime {
+			continue
+		}
+		d := min(duration, clips[i].OutputDuration()/2, clips[i+1].OutputDuration()/2)
+		durations[i] = max(d, 0)
+	}
+	return durations
+}
+
+// CrossfadeClips returns what CrossfadeFilterTracks shows of each clip: the clip
+// with half of each neighbouring overlap trimmed off. Played back to back they last
+// as long as the crossfaded output, so they describe its timeline, chapters and the
+// hard video cuts made when the video isn't dissolved.
+func CrossfadeClips(clips []Clip, duration float64) []Clip {
+	durations := CrossfadeDurations(clips, duration)
+	visible := make([]Clip, len(clips))
+	for i, c := range clips {
+		if i > 0 {
+			c.StartTime += durations[i-1] / 2 * c.speed()
+		}
+		if i < len(clips)-1 {
+			c.EndTime -= durations[i] / 2 * c.speed()
+		}
+		visible[i] = c
+	}
+	return visible
+}
+
+// CrossfadeFilterTracks is like ConcatFilterTracks but overlaps neighbouring clips
+// with acrossfade instead of fading each one to silence, so the cuts have no dip in
+// volume. The overlap at each join is given by CrossfadeDurations, which also makes
+// the output shorter than the clips by the sum of the overlaps.
+//
+// The video is cut in the middle of each overlap (see CrossfadeClips), or dissolved
+// over the whole overlap with xfade when xf.Video is set. Outputs are labelled
+// [outv] and as given by AudioOutputLabel.
+func CrossfadeFilterTracks(clips []Clip, withVideo bool, audioTracks int, xf Crossfade) string {
+	durations := CrossfadeDurations(clips, xf.Duration)
+	var b strings.Builder
+
+	if withVideo {
+		video := clips
+		if !xf.Video {
+			video = CrossfadeClips(clips, xf.Duration)
+		}
+		labels := make([]string, len(video))
+		for i, c := range video {
+			labels[i] = fmt.Sprintf("[v%d]", i)
+			fmt.Fprintf(&b, "[0:v]trim=start=%.6f:end=%.6f,setpts=%s%s;", c.StartTime, c.EndTime, setptsExpr(c.speed()), labels[i])
+		}
+		if xf.Video {
+			writeCrossfadeChain(&b, clips, labels, durations, true, "[outv]", "xv", func(i int, offset float64) string {
+				return fmt.Sprintf("xfade=transition=fade:duration=%.6f:offset=%.6f", durations[i], offset)
+			})
+		} else {
+			fmt.Fprintf(&b, "%sconcat=n=%d:v=1:a=0[outv];", strings.Join(labels, ""), len(labels))
+		}
+	}
+
+	curve := xf.Curve
+	if curve == "" {
+		curve = "tri"
+	}
+	for track := 0; track < audioTracks; track++ {
+		input, prefix := "0:a", "a"
+		if audioTracks > 1 {
+			input = fmt.Sprintf("0:a:%d", track)
+		}
+		if track > 0 {
+			prefix = fmt.Sprintf("a%d_", track)
+		}
+		labels := make([]string, len(clips))
+		for i, c := range clips {
+			labels[i] = fmt.Sprintf("[%s%d]", prefix, i)
+			fmt.Fprintf(&b, "[%s]atrim=start=%.6f:end=%.6f,asetpts=PTS-STARTPTS", input, c.StartTime, c.EndTime)
+			if f := AtempoFilter(c.speed()); f != "" {
+				b.WriteString("," + f)
+			}
+			b.WriteString(labels[i] + ";")
+		}
+		writeCrossfadeChain(&b, clips, labels, durations, false, AudioOutputLabel(track), "x"+prefix, func(i int, _ float64) string {
+			return fmt.Sprintf("acrossfade=d=%.6f:c1=%s:c2=%s", durations[i], curve, curve)
+		})
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// writeCrossfadeChain joins the video or audio streams in labels pairwise from the
+// left: each join is overlapped with the filter from fade, or plainly concatenated
+// where its duration is 0. fade receives the join index and where the overlap starts
+// in the joined output so far. The result is labelled out; intermediate results are
+// named with prefix.
+func writeCrossfadeChain(b *strings.Builder, clips []Clip, labels []string, durations []float64, video bool, out, prefix string, fade func(i int, offset float64) string) {
+	concat, null := "concat=n=2:v=0:a=1", "anull"
+	if video {
+		concat, null = "concat=n=2:v=1:a=0", "null"
+	}
+	if len(labels) == 1 {
+		fmt.Fprintf(b, "%s%s%s;", labels[0], null, out)
+		return
+	}
+	acc, length := labels[0], clips[0].OutputDuration()
+	for i := range durations {
+		next := fmt.Sprintf("[%s%d]", prefix, i+1)
+		if i == len(durations)-1 {
+			next = out
+		}
+		filter := concat
+		if durations[i] > 0 {
+			filter = fade(i, length-durations[i])
+		}
+		fmt.Fprintf(b, "%s%s%s%s;", acc, labels[i+1], filter, next)
+		acc, length = next, length+clips[i+1].OutputDuration()-durations[i]
+	}
+}
@@ -0,0 +1,101 @@
+package ffutil
+
+import (
+	"strings"
+	"testing"
+)
+
+var crossfadeTestClips = []Clip{
+	{StartTime: 0, EndTime: 2, Speed: 1},
+	{StartTime: 3, EndTime: 3.2, Speed: 1}, // short: its overlaps are clamped to 0.1s
+	{StartTime: 4, EndTime: 6, Speed: 1},
+}
+
+func TestCrossfadeDurations(t *testing.T) {
+	got := CrossfadeDurations(crossfadeTestClips, 0.5)
+	if len(got) != 2 {
+		t.Fatalf("got %d durations, want 2", len(got))
+	}
+	assertFloat(t, got[0], 0.1, "join 0")
+	assertFloat(t, got[1], 0.1, "join 1")
+
+	contiguous := []Clip{{StartTime: 0, EndTime: 2}, {StartTime: 2, EndTime: 4}}
+	if d := CrossfadeDurations(contiguous, 0.5); d[0] != 0 {
+		t.Errorf("contiguous clips overlap by %v, want 0", d[0])
+	}
+	if d := CrossfadeDurations(crossfadeTestClips[:1], 0.5); d != nil {
+		t.Errorf("single clip durations = %v, want nil", d)
+	}
+}
+
+func TestCrossfadeClips(t *testing.T) {
+	clips := []Clip{{StartTime: 0, EndTime: 2, Speed: 1}, {StartTime: 4, EndTime: 6, Speed: 1}}
+	got := CrossfadeClips(clips, 0.4)
+	want := []Clip{{StartTime: 0, EndTime: 1.8, Speed: 1}, {StartTime: 4.2, EndTime: 6, Speed: 1}}
+	for i := range want {
+		assertFloat(t, got[i].StartTime, want[i].StartTime, "start")
+		assertFloat(t, got[i].EndTime, want[i].EndTime, "end")
+	}
+	// The visible clips last as long as the overlapped output: 4s minus one 0.4s overlap
+	assertFloat(t, ClipsDuration(got), 3.6, "duration")
+}
+
+func TestCrossfadeFilterTracks_Audio(t *testing.T) {
+	got := CrossfadeFilterTracks(crossfadeTestClips, false, 1, Crossfade{Duration: 0.5, Curve: "qsin"})
+	want := "[0:a]atrim=start=0.000000:end=2.000000,asetpts=PTS-STARTPTS[a0];" +
+		"[0:a]atrim=start=3.000000:end=3.200000,asetpts=PTS-STARTPTS[a1];" +
+		"[0:a]atrim=start=4.000000:end=6.000000,asetpts=PTS-STARTPTS[a2];" +
+		"[a0][a1]acrossfade=d=0.100000:c1=qsin:c2=qsin[xa1];" +
+		"[xa1][a2]acrossfade=d=0.100000:c1=qsin:c2=qsin[outa]"
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCrossfadeFilterTracks_VideoCutInMiddle(t *testing.T) {
+	clips := []Clip{{StartTime: 0, EndTime: 2, Speed: 1}, {StartTime: 4, EndTime: 6, Speed: 1}}
+	got := CrossfadeFilterTracks(clips, true, 2, Crossfade{Duration: 0.4})
+	for _, part := range []string{
+		"[0:v]trim=start=0.000000:end=1.800000,setpts=PTS-STARTPTS[v0];",
+		"[0:v]trim=start=4.200000:end=6.000000,setpts=PTS-STARTPTS[v1];",
+		"[v0][v1]concat=n=2:v=1:a=0[outv];",
+		"[0:a:1]atrim=start=4.000000:end=6.000000,asetpts=PTS-STARTPTS[a1_1];",
+		"[a0][a1]acrossfade=d=0.400000:c1=tri:c2=tri[outa];",
+		"[a1_0][a1_1]acrossfade=d=0.400000:c1=tri:c2=tri[outa1]",
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("graph is missing %q:\n%s", part, got)
+		}
+	}
+}
+
+func TestCrossfadeFilterTracks_VideoDissolve(t *testing.T) {
+	clips := []Clip{
+		{StartTime: 0, EndTime: 2, Speed: 1},
+		{StartTime: 4, EndTime: 6, Speed: 1},
+		{StartTime: 6, EndTime: 7, Speed: 1}, // continues the previous clip: no overlap
+		{StartTime: 9, EndTime: 10, Speed: 1},
+	}
+	got := CrossfadeFilterTracks(clips, true, 0, Crossfade{Duration: 0.4, Video: true})
+	for _, part := range []string{
+		"[0:v]trim=start=0.000000:end=2.000000,setpts=PTS-STARTPTS[v0];",
+		"[v0][v1]xfade=transition=fade:duration=0.400000:offset=1.600000[xv1];",
+		"[xv1][v2]concat=n=2:v=1:a=0[xv2];",
+		// 2 + 2 - 0.4 + 1 = 4.6s so far, overlapping its last 0.4s
+		"[xv2][v3]xfade=transition=fade:duration=0.400000:offset=4.200000[outv]",
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("graph is missing %q:\n%s", part, got)
+		}
+	}
+	if strings.Contains(got, "0:a") {
+		t.Errorf("graph has audio with 0 audio tracks:\n%s", got)
+	}
+}
+
+func TestCrossfadeFilterTracks_SingleClip(t *testing.T) {
+	got := CrossfadeFilterTracks(crossfadeTestClips[:1], true, 1, Crossfade{Duration: 0.4, Video: true})
+	if !strings.Contains(got, "[v0]null[outv];") || !strings.HasSuffix(got, "[a0]anull[outa]") {
+		t.Errorf("single clip graph should pass through:\n%s", got)
+	}
+}
@@ -0,0 +1,126 @@
+package ffutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// stderrTailSize is how much of the end of stderr an FFmpegError keeps. ffmpeg prints
+// the actual failure last, after banners and stream listings that can be very long.
+const stderrTailSize = 4096
+
+// ErrorKind classifies why an ffmpeg or ffprobe invocation failed.
+type ErrorKind string
+
+// Error kinds recognised from ffmpeg's diagnostics
+const (
+	ErrorKindUnknown          ErrorKind = "unknown"
+	ErrorKindInputNotFound    ErrorKind = "input not found"
+	ErrorKindUnsupportedCodec ErrorKind = "unsupported codec"
+	ErrorKindInvalidData      ErrorKind = "invalid data"
+	ErrorKindDiskFull         ErrorKind = "disk full"
+	ErrorKindEncoderMissing   ErrorKind = "encoder missing"
+	ErrorKindPermissionDenied ErrorKind = "permission denied"
+)
+
+// errorPatterns maps lower-cased stderr fragments to error kinds. The order matters:
+// resource problems are checked first because ffmpeg often follows them with generic
+// follow-up errors such as "Invalid argument".
+var errorPatterns = []struct {
+	kind      ErrorKind
+	fragments []string
+}{
+	{ErrorKindDiskFull, []string{"no space left on device", "disk quota exceeded", "file too large"}},
+	{ErrorKindPermissionDenied, []string{"permission denied", "operation not permitted", "read-only file system"}},
+	{ErrorKindInputNotFound, []string{"no such file or directory", "server returned 404", "404 not found"}},
+	{ErrorKindEncoderMissing, []string{"unknown encoder", "encoder not found", "encoder (codec", "automatic encoder selection failed"}},
+	{ErrorKindUnsupportedCodec, []string{"decoder (codec", "decoder not found", "unsupported codec", "not currently supported in container", "could not find tag for codec", "no decoder for"}},
+	{ErrorKindInvalidData, []string{"invalid data found when processing input", "moov atom not found", "error while decoding", "invalid nal unit"}},
+}
+
+// FFmpegError describes a failed ffmpeg or ffprobe invocation. Use errors.As to get
+// at it from any error returned by the library:
+//
+//	var ffErr *video.FFmpegError
+//	if errors.As(err, &ffErr) && ffErr.Kind == video.ErrorKindInvalidData {
+//		// reject the upload instead of retrying
+//	}
+type FFmpegError struct {
+	Binary   string    // Binary that was run, e.g. "ffmpeg"
+	Args     []string  // Full argument list, without the binary
+	ExitCode int       // Process exit code, or -1 if it did not exit normally (killed, failed to start)
+	Stderr   string    // Tail of the diagnostic output (at most 4 KiB)
+	Kind     ErrorKind // Classified cause, ErrorKindUnknown if not recognised
+	Err      error     // Underlying error; wraps ctx.Err() when the command was cancelled
+}
+
+// NewFFmpegError builds an FFmpegError for a failed command, classifying it from its
+// diagnostic output. err is the error returned by the Runner.
+func NewFFmpegError(binary string, args []string, output []byte, err error) *FFmpegError {
+	stderr := string(output)
+	if len(stderr) > stderrTailSize {
+		stderr = stderr[len(stderr)-stderrTailSize:]
+	}
+	return &FFmpegError{
+		Binary:   binary,
+		Args:     append([]string(nil), args...),
+		ExitCode: exitCode(err),
+		Stderr:   stderr,
+		Kind:     ClassifyError(stderr),
+		Err:      err,
+	}
+}
+
+// Error returns the binary, exit code, kind and the last diagnostic line.
+func (e *FFmpegError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Binary)
+	if e.ExitCode >= 0 {
+		fmt.Fprintf(&b, " exited with code %d", e.ExitCode)
+	} else {
+		fmt.Fprintf(&b, " failed: %v", e.Err)
+	}
+	if e.Kind != ErrorKindUnknown {
+		fmt.Fprintf(&b, " (%s)", e.Kind)
+	}
+	if line := lastLine(e.Stderr); line != "" {
+		b.WriteString(": ")
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error, so errors.Is(err, context.Canceled) works.
+func (e *FFmpegError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError returns the kind of failure described by ffmpeg's diagnostic output.
+func ClassifyError(stderr string) ErrorKind {
+	lower := strings.ToLower(stderr)
+	for _, p := range errorPatterns {
+		for _, fragment := range p.fragments {
+			if strings.Contains(lower, fragment) {
+				return p.kind
+			}
+		}
+	}
+	return ErrorKindUnknown
+}
+
+// exitCode extracts the process exit code from err. Runners that are not backed by
+// os/exec can report one by returning an error with an ExitCode() int method.
+func exitCode(err error) int {
+	var coder interface{ ExitCode() int }
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	return -1
+}
+
+// lastLine returns the last non-empty line of s, trimmed.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,99 @@
+package ffutil
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   ErrorKind
+	}{
+		{"missing.mp4: No such file or directory", ErrorKindInputNotFound},
+		{"[in#0 @ 0x1] Error opening input: Server returned 404 Not Found", ErrorKindInputNotFound},
+		{"Unknown encoder 'libfdk_aac'", ErrorKindEncoderMissing},
+		{"[vost#0:0 @ 0x1] Encoder not found", ErrorKindEncoderMissing},
+		{"Decoder (codec av1) not found for input stream #0:0", ErrorKindUnsupportedCodec},
+		{"Could not find tag for codec pcm_s16le in stream #1, codec not currently supported in container", ErrorKindUnsupportedCodec},
+		{"corrupt.mp4: Invalid data found when processing input", ErrorKindInvalidData},
+		{"[mov,mp4 @ 0x1] moov atom not found", ErrorKindInvalidData},
+		{"av_interleaved_write_frame(): No space left on device", ErrorKindDiskFull},
+		{"out.mp4: Permission denied", ErrorKindPermissionDenied},
+		{"Conversion failed!", ErrorKindUnknown},
+		{"", ErrorKindUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ClassifyError(tt.stderr); got != tt.want {
+			t.Errorf("ClassifyError(%q) = %q, want %q", tt.stderr, got, tt.want)
+		}
+	}
+}
+
+func TestClassifyError_DiskFullBeatsInvalidArgument(t *testing.T) {
+	stderr := "av_interleaved_write_frame(): No space left on device\nError writing trailer: Invalid argument"
+	if got := ClassifyError(stderr); got != ErrorKindDiskFull {
+		t.Errorf("ClassifyError = %q, want %q", got, ErrorKindDiskFull)
+	}
+}
+
+func TestNewFFmpegError_TruncatesStderr(t *testing.T) {
+	long := strings.Repeat("x", stderrTailSize*2) + "\nlast line"
+	e := NewFFmpegError("ffmpeg", []string{"-i", "in.mp4"}, []byte(long), errors.New("exit status 1"))
+
+	if len(e.Stderr) != stderrTailSize {
+		t.Errorf("len(Stderr) = %d, want %d", len(e.Stderr), stderrTailSize)
+	}
+	if !strings.HasSuffix(e.Stderr, "last line") {
+		t.Errorf("Stderr should keep the tail, got ...%q", e.Stderr[len(e.Stderr)-20:])
+	}
+}
+
+func TestFFmpegError_FromExec(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	args := []string{"-c", "echo 'in.mp4: Invalid data found when processing input' >&2; exit 3"}
+	_, err := CombinedOutput(context.Background(), ExecRunner{}, "sh", args...)
+
+	var ffErr *FFmpegError
+	if !errors.As(err, &ffErr) {
+		t.Fatalf("expected *FFmpegError, got %T: %v", err, err)
+	}
+	if ffErr.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, want 3", ffErr.ExitCode)
+	}
+	if ffErr.Kind != ErrorKindInvalidData {
+		t.Errorf("Kind = %q, want %q", ffErr.Kind, ErrorKindInvalidData)
+	}
+	if ffErr.Binary != "sh" || len(ffErr.Args) != 2 {
+		t.Errorf("Binary/Args = %q %v", ffErr.Binary, ffErr.Args)
+	}
+	want := "sh exited with code 3 (invalid data): in.mp4: Invalid data found when processing input"
+	if ffErr.Error() != want {
+		t.Errorf("Error() = %q, want %q", ffErr.Error(), want)
+	}
+}
+
+func TestFFmpegError_UnwrapsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := CombinedOutput(ctx, &fakeRunner{err: errors.New("signal: killed")}, "ffmpeg")
+
+	var ffErr *FFmpegError
+	if !errors.As(err, &ffErr) {
+		t.Fatalf("expected *FFmpegError, got %T: %v", err, err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error to wrap context.Canceled: %v", err)
+	}
+	if ffErr.ExitCode != -1 {
+		t.Errorf("ExitCode = %d, want -1 when the runner reports no exit code", ffErr.ExitCode)
+	}
+}
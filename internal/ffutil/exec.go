@@ -0,0 +1,105 @@
+package ffutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// waitDelay bounds how long Wait blocks on I/O after the process has been killed.
+// Without it, a grandchild that inherited stdout/stderr could keep the command
+// blocked long after the context was cancelled.
+const waitDelay = 5 * time.Second
+
+// Runner executes external commands on behalf of the library. Every ffmpeg and
+// ffprobe invocation goes through a Runner, so callers can execute them in a
+// container or sandbox, record them, or replace them with fakes in tests.
+//
+// Run must block until the command exits. stdin may be nil. stdout and stderr may
+// be the same writer. When ctx is done, Run should stop the command and return an
+// error; the library reports cancellation using ctx.Err() either way.
+type Runner interface {
+	Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+// ExecRunner is the default Runner. It runs commands as local processes.
+type ExecRunner struct{}
+
+// Run implements Runner using os/exec. See Command for how cancellation is handled.
+func (ExecRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := Command(ctx, name, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// Command returns an exec.Cmd that runs name with args and is bound to ctx.
+//
+// The command runs in its own process group. When ctx is cancelled or its deadline
+// passes, the whole group is killed rather than only the direct child, so helper
+// processes spawned by ffmpeg cannot outlive the operation.
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+	return cmd
+}
+
+// CombinedOutput runs name with args through r and returns its combined stdout and
+// stderr. Failures are reported as *FFmpegError. If ctx is done before the command
+// finishes, the returned error wraps ctx.Err().
+func CombinedOutput(ctx context.Context, r Runner, name string, args ...string) ([]byte, error) {
+	var output lockedBuffer
+	err := r.Run(ctx, name, args, nil, &output, &output)
+	return output.Bytes(), commandError(ctx, name, args, output.Bytes(), err)
+}
+
+// Output runs name with args through r and returns its stdout. Failures are reported
+// as *FFmpegError carrying the command's stderr. If ctx is done before the command
+// finishes, the returned error wraps ctx.Err().
+func Output(ctx context.Context, r Runner, name string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	err := r.Run(ctx, name, args, nil, &stdout, &stderr)
+	return stdout.Bytes(), commandError(ctx, name, args, stderr.Bytes(), err)
+}
+
+// commandError turns a Runner error into an *FFmpegError, or returns nil on success.
+func commandError(ctx context.Context, name string, args []string, stderr []byte, err error) error {
+	if err == nil {
+		return nil
+	}
+	return NewFFmpegError(name, args, stderr, contextError(ctx, err))
+}
+
+// contextError makes sure a failure caused by cancellation is reported as such.
+// A killed process only reports "signal: killed", which hides the real cause.
+func contextError(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	return fmt.Errorf("%w (%v)", ctx.Err(), err)
+}
+
+// lockedBuffer is a bytes.Buffer that is safe to use as both stdout and stderr of a
+// Runner that copies the two streams from separate goroutines.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Bytes()
+}
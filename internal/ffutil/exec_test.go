@@ -0,0 +1,121 @@
+package ffutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestCombinedOutput_KilledOnDeadline(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := CombinedOutput(ctx, ExecRunner{}, "sleep", "10")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected error wrapping context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("command was not killed promptly: took %v", elapsed)
+	}
+}
+
+func TestCombinedOutput_NoContextErrorOnFailure(t *testing.T) {
+	if _, err := exec.LookPath("false"); err != nil {
+		t.Skip("false not available")
+	}
+
+	_, err := CombinedOutput(context.Background(), ExecRunner{}, "false")
+	if err == nil {
+		t.Fatal("expected error from failing command, got nil")
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error should not wrap a context error: %v", err)
+	}
+}
+
+// fakeRunner writes canned output instead of running a process.
+type fakeRunner struct {
+	stdout, stderr string
+	err            error
+
+	name string
+	args []string
+}
+
+func (f *fakeRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	f.name = name
+	f.args = args
+	io.WriteString(stdout, f.stdout)
+	io.WriteString(stderr, f.stderr)
+	return f.err
+}
+
+func TestCombinedOutput_FakeRunner(t *testing.T) {
+	r := &fakeRunner{stdout: "out\n", stderr: "err\n"}
+
+	output, err := CombinedOutput(context.Background(), r, "ffmpeg", "-i", "in.mp4")
+	if err != nil {
+		t.Fatalf("CombinedOutput: %v", err)
+	}
+	if string(output) != "out\nerr\n" {
+		t.Errorf("output = %q, want stdout and stderr combined", output)
+	}
+	if r.name != "ffmpeg" || len(r.args) != 2 || r.args[1] != "in.mp4" {
+		t.Errorf("runner called with %s %v", r.name, r.args)
+	}
+}
+
+func TestOutput_FakeRunnerStdoutOnly(t *testing.T) {
+	r := &fakeRunner{stdout: "duration=1.0\n", stderr: "warning\n"}
+
+	output, err := Output(context.Background(), r, "ffprobe")
+	if err != nil {
+		t.Fatalf("Output: %v", err)
+	}
+	if string(output) != "duration=1.0\n" {
+		t.Errorf("output = %q, want stdout only", output)
+	}
+}
+
+func TestRunWithProgress_FakeRunner(t *testing.T) {
+	r := &fakeRunner{
+		stdout: "out_time_us=500000\nprogress=continue\nout_time_us=1000000\nprogress=end\n",
+		stderr: "[silencedetect] silence_start: 1.0\n",
+	}
+
+	var reports []ProgressReport
+	stderr, err := RunWithProgress(context.Background(), r, "ffmpeg", []string{"-i", "in.mp4"}, func(p ProgressReport) {
+		reports = append(reports, p)
+	})
+	if err != nil {
+		t.Fatalf("RunWithProgress: %v", err)
+	}
+	if len(reports) != 2 || !reports[1].End {
+		t.Fatalf("expected 2 reports ending with progress=end, got %+v", reports)
+	}
+	if string(stderr) != r.stderr {
+		t.Errorf("stderr = %q, want %q", stderr, r.stderr)
+	}
+	if r.args[0] != "-progress" || r.args[1] != "pipe:1" {
+		t.Errorf("expected -progress pipe:1 to be prepended, got %v", r.args)
+	}
+}
+
+func TestRunWithProgress_ContextError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r := &fakeRunner{err: errors.New("signal: killed")}
+
+	_, err := RunWithProgress(ctx, r, "ffmpeg", nil, func(ProgressReport) {})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error wrapping context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package ffutil
+
+import (
+	"math"
+	"math/bits"
+)
+
+// FFT computes the discrete Fourier transform of x in place. len(x) must be a power
+// of two.
+func FFT(x []complex128) {
+	fft(x, false)
+}
+
+// InverseFFT computes the inverse discrete Fourier transform of x in place, scaled so
+// that InverseFFT(FFT(x)) returns x. len(x) must be a power of two.
+func InverseFFT(x []complex128) {
+	fft(x, true)
+	scale := complex(1/float64(len(x)), 0)
+	for i := range x {
+		x[i] *= scale
+	}
+}
+
+// fft is an iterative radix-2 Cooley-Tukey transform.
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+	if n&(n-1) != 0 {
+		panic("ffutil: FFT length must be a power of two")
+	}
+
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i := range x {
+		if j := int(bits.Reverse64(uint64(i)) >> shift); j > i {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := sign * 2 * math.Pi / float64(size)
+		// Twiddles are computed directly rather than by repeated multiplication, which
+		// drifts on long transforms
+		for k := 0; k < size/2; k++ {
+			sin, cos := math.Sincos(step * float64(k))
+			twiddle := complex(cos, sin)
+			for start := k; start < n; start += size {
+				a, b := x[start], x[start+size/2]*twiddle
+				x[start], x[start+size/2] = a+b, a-b
+			}
+		}
+	}
+}
+
+// NextPowerOfTwo returns the smallest power of two that is at least n.
+func NextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
@@ -0,0 +1,43 @@
+package ffutil
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+func TestFFT_MatchesDFT(t *testing.T) {
+	x := make([]complex128, 16)
+	for i := range x {
+		x[i] = complex(math.Sin(float64(i))+0.5*float64(i%3), 0)
+	}
+	want := make([]complex128, len(x))
+	for k := range want {
+		for n, v := range x {
+			want[k] += v * cmplx.Exp(complex(0, -2*math.Pi*float64(k*n)/float64(len(x))))
+		}
+	}
+
+	got := append([]complex128(nil), x...)
+	FFT(got)
+	for k := range want {
+		if cmplx.Abs(got[k]-want[k]) > 1e-9 {
+			t.Errorf("bin %d = %v, want %v", k, got[k], want[k])
+		}
+	}
+
+	InverseFFT(got)
+	for i := range x {
+		if cmplx.Abs(got[i]-x[i]) > 1e-9 {
+			t.Errorf("inverse sample %d = %v, want %v", i, got[i], x[i])
+		}
+	}
+}
+
+func TestNextPowerOfTwo(t *testing.T) {
+	for n, want := range map[int]int{0: 1, 1: 1, 2: 2, 3: 4, 512: 512, 513: 1024} {
+		if got := NextPowerOfTwo(n); got != want {
+			t.Errorf("NextPowerOfTwo(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
@@ -4,26 +4,51 @@ import (
 	"fmt"
 	"os/exec"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 )
 
-var depsOnce sync.Once
-var depsErr error
+// Default binary names, resolved through PATH
+const (
+	DefaultFFmpeg  = "ffmpeg"
+	DefaultFFprobe = "ffprobe"
+)
+
+var (
+	lookPathMu    sync.Mutex
+	lookPathCache = map[string]error{}
+)
 
 // CheckDependencies verifies that ffmpeg and ffprobe are available in PATH.
 // The check runs only once per process; subsequent calls return the cached result.
 func CheckDependencies() error {
-	depsOnce.Do(func() {
-		if _, err := exec.LookPath("ffmpeg"); err != nil {
-			depsErr = fmt.Errorf("ffmpeg not found in PATH: %w", err)
-			return
-		}
-		if _, err := exec.LookPath("ffprobe"); err != nil {
-			depsErr = fmt.Errorf("ffprobe not found in PATH: %w", err)
-		}
-	})
-	return depsErr
+	return CheckBinaries(DefaultFFmpeg, DefaultFFprobe)
+}
+
+// CheckBinaries verifies that the given ffmpeg and ffprobe binaries can be found.
+// Each may be a bare name resolved through PATH or a path to an executable. Results
+// are cached per binary, so several ffmpeg builds can be checked side by side.
+func CheckBinaries(ffmpeg, ffprobe string) error {
+	if err := lookPath(ffmpeg); err != nil {
+		return fmt.Errorf("ffmpeg not found: %w", err)
+	}
+	if err := lookPath(ffprobe); err != nil {
+		return fmt.Errorf("ffprobe not found: %w", err)
+	}
+	return nil
+}
+
+func lookPath(name string) error {
+	lookPathMu.Lock()
+	defer lookPathMu.Unlock()
+	if err, ok := lookPathCache[name]; ok {
+		return err
+	}
+	_, err := exec.LookPath(name)
+	lookPathCache[name] = err
+	return err
 }
 
 var (
@@ -100,6 +125,117 @@ func BuildNonSilentSegments(starts, ends []float64, totalDuration, minSegmentDur
 	return segments
 }
 
+// SegmentOptions controls how RefineSegments post-processes non-silent segments.
+// All values are in seconds.
+type SegmentOptions struct {
+	PaddingBefore      float64 // Extra time kept before each segment
+	PaddingAfter       float64 // Extra time kept after each segment
+	MergeGap           float64 // Segments separated by less than this are joined
+	MinSegmentDuration float64 // Segments shorter than this (after merging) are dropped
+	MaxPause           float64 // If > 0, pauses longer than this are shortened to it instead of removed
+}
+
+// RefineSegments merges segments separated by less than opts.MergeGap, drops those
+// shorter than opts.MinSegmentDuration, then pads the rest and merges any that
+// overlap after padding. Padding is clamped to [0, totalDuration].
+//
+// With opts.MaxPause set, pauses no longer than MaxPause are kept whole and longer
+// ones are shortened to MaxPause, half of it kept after the preceding segment and
+// half before the next. Leading and trailing silence keeps MaxPause/2 next to the
+// speech.
+//
+// segments must be sorted by StartTime and not overlap.
+func RefineSegments(segments []Segment, totalDuration float64, opts SegmentOptions) []Segment {
+	merged := mergeSegments(segments, opts.MergeGap)
+
+	var kept []Segment
+	for _, seg := range merged {
+		if seg.Duration >= opts.MinSegmentDuration {
+			kept = append(kept, seg)
+		}
+	}
+
+	kept = padSegments(kept, totalDuration, opts.PaddingBefore, opts.PaddingAfter)
+	if opts.MaxPause > 0 {
+		// Growing both neighbours by half the limit closes every pause up to the limit
+		// and leaves exactly the limit of any longer one
+		kept = padSegments(kept, totalDuration, opts.MaxPause/2, opts.MaxPause/2)
+	}
+	return kept
+}
+
+// padSegments extends each segment by before and after, clamped to
+// [0, totalDuration], and merges segments that overlap as a result.
+func padSegments(segments []Segment, totalDuration, before, after float64) []Segment {
+	for i := range segments {
+		segments[i].StartTime = max(segments[i].StartTime-before, 0)
+		segments[i].EndTime = min(segments[i].EndTime+after, totalDuration)
+		segments[i].Duration = segments[i].EndTime - segments[i].StartTime
+	}
+	return mergeSegments(segments, 0)
+}
+
+// mergeSegments joins consecutive segments whose gap is below gap, and always
+// joins overlapping or touching ones.
+func mergeSegments(segments []Segment, gap float64) []Segment {
+	var merged []Segment
+	for _, seg := range segments {
+		if n := len(merged); n > 0 {
+			last := &merged[n-1]
+			if seg.StartTime-last.EndTime < gap || seg.StartTime <= last.EndTime {
+				last.EndTime = max(last.EndTime, seg.EndTime)
+				last.Duration = last.EndTime - last.StartTime
+				continue
+			}
+		}
+		merged = append(merged, seg)
+	}
+	return merged
+}
+
+// ConcatListEntry returns the line of an ffmpeg concat demuxer list that names path,
+// quoted so that paths containing single quotes survive.
+func ConcatListEntry(path string) string {
+	return "file '" + strings.ReplaceAll(path, "'", `'\''`) + "'\n"
+}
+
+// NormalizeSegments prepares caller-supplied ranges for cutting: it clamps them to
+// [0, totalDuration], fills in Duration, drops empty ranges, sorts them by start
+// and merges any that overlap or touch.
+func NormalizeSegments(segments []Segment, totalDuration float64) []Segment {
+	var cleaned []Segment
+	for _, seg := range segments {
+		start := max(seg.StartTime, 0)
+		end := seg.EndTime
+		if totalDuration > 0 {
+			end = min(end, totalDuration)
+		}
+		if end > start {
+			cleaned = append(cleaned, Segment{StartTime: start, EndTime: end, Duration: end - start})
+		}
+	}
+	sort.Slice(cleaned, func(i, j int) bool {
+		return cleaned[i].StartTime < cleaned[j].StartTime
+	})
+	return mergeSegments(cleaned, 0)
+}
+
+// InvertSegments returns the parts of [0, totalDuration] not covered by segments.
+func InvertSegments(segments []Segment, totalDuration float64) []Segment {
+	var inverted []Segment
+	pos := 0.0
+	for _, seg := range NormalizeSegments(segments, totalDuration) {
+		if seg.StartTime > pos {
+			inverted = append(inverted, Segment{StartTime: pos, EndTime: seg.StartTime, Duration: seg.StartTime - pos})
+		}
+		pos = seg.EndTime
+	}
+	if pos < totalDuration {
+		inverted = append(inverted, Segment{StartTime: pos, EndTime: totalDuration, Duration: totalDuration - pos})
+	}
+	return inverted
+}
+
 // Segment represents a time-based segment of media.
 type Segment struct {
 	StartTime float64
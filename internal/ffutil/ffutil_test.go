@@ -166,6 +166,173 @@ func TestBuildNonSilentSegments_DurationField(t *testing.T) {
 	}
 }
 
+func TestRefineSegments_PaddingClampedToBounds(t *testing.T) {
+	segs := []Segment{
+		{StartTime: 0.1, EndTime: 2.0, Duration: 1.9},
+		{StartTime: 5.0, EndTime: 9.9, Duration: 4.9},
+	}
+	got := RefineSegments(segs, 10.0, SegmentOptions{PaddingBefore: 0.2, PaddingAfter: 0.3})
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(got))
+	}
+	assertFloat(t, got[0].StartTime, 0, "seg[0].StartTime clamped to 0")
+	assertFloat(t, got[0].EndTime, 2.3, "seg[0].EndTime")
+	assertFloat(t, got[1].StartTime, 4.8, "seg[1].StartTime")
+	assertFloat(t, got[1].EndTime, 10.0, "seg[1].EndTime clamped to total")
+	assertFloat(t, got[1].Duration, 5.2, "seg[1].Duration")
+}
+
+func TestRefineSegments_OverlapAfterPaddingMerged(t *testing.T) {
+	segs := []Segment{
+		{StartTime: 1.0, EndTime: 2.0, Duration: 1.0},
+		{StartTime: 2.4, EndTime: 3.0, Duration: 0.6},
+	}
+	got := RefineSegments(segs, 10.0, SegmentOptions{PaddingBefore: 0.25, PaddingAfter: 0.25})
+
+	if len(got) != 1 {
+		t.Fatalf("expected padded segments to merge into 1, got %d: %+v", len(got), got)
+	}
+	assertFloat(t, got[0].StartTime, 0.75, "StartTime")
+	assertFloat(t, got[0].EndTime, 3.25, "EndTime")
+	assertFloat(t, got[0].Duration, 2.5, "Duration")
+}
+
+func TestRefineSegments_MergeGap(t *testing.T) {
+	segs := []Segment{
+		{StartTime: 0.0, EndTime: 1.0, Duration: 1.0},
+		{StartTime: 1.2, EndTime: 2.0, Duration: 0.8},
+		{StartTime: 3.0, EndTime: 4.0, Duration: 1.0},
+	}
+	got := RefineSegments(segs, 10.0, SegmentOptions{MergeGap: 0.5})
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 segments, got %d: %+v", len(got), got)
+	}
+	assertFloat(t, got[0].EndTime, 2.0, "merged EndTime")
+	assertFloat(t, got[1].StartTime, 3.0, "untouched StartTime")
+}
+
+func TestRefineSegments_MinDurationAppliesAfterMerge(t *testing.T) {
+	segs := []Segment{
+		{StartTime: 0.0, EndTime: 0.3, Duration: 0.3},
+		{StartTime: 0.4, EndTime: 0.7, Duration: 0.3},
+		{StartTime: 5.0, EndTime: 5.2, Duration: 0.2},
+	}
+	got := RefineSegments(segs, 10.0, SegmentOptions{MergeGap: 0.2, MinSegmentDuration: 0.5})
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 segment, got %d: %+v", len(got), got)
+	}
+	assertFloat(t, got[0].Duration, 0.7, "merged Duration")
+}
+
+func TestRefineSegments_Empty(t *testing.T) {
+	if got := RefineSegments(nil, 10.0, SegmentOptions{PaddingBefore: 1}); len(got) != 0 {
+		t.Errorf("expected no segments, got %+v", got)
+	}
+}
+
+func TestRefineSegments_MaxPause(t *testing.T) {
+	segs := []Segment{
+		{StartTime: 2.0, EndTime: 3.0, Duration: 1.0},
+		{StartTime: 3.5, EndTime: 4.0, Duration: 0.5},
+		{StartTime: 7.0, EndTime: 8.0, Duration: 1.0},
+	}
+	got := RefineSegments(segs, 9.0, SegmentOptions{MaxPause: 1.0})
+
+	// The 0.5s pause is kept whole, the 3s pause shrinks to 1s split over both sides,
+	// and leading/trailing silence keeps 0.5s next to the speech
+	want := []Segment{
+		{StartTime: 1.5, EndTime: 4.5, Duration: 3.0},
+		{StartTime: 6.5, EndTime: 8.5, Duration: 2.0},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d segments, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		assertFloat(t, got[i].StartTime, want[i].StartTime, "StartTime")
+		assertFloat(t, got[i].EndTime, want[i].EndTime, "EndTime")
+		assertFloat(t, got[i].Duration, want[i].Duration, "Duration")
+	}
+
+	var removed float64
+	for i := 1; i < len(got); i++ {
+		removed += got[i].StartTime - got[i-1].EndTime
+	}
+	assertFloat(t, removed, 2.0, "time removed from the long pause")
+}
+
+func TestRefineSegments_MaxPauseWithPadding(t *testing.T) {
+	segs := []Segment{
+		{StartTime: 1.0, EndTime: 2.0, Duration: 1.0},
+		{StartTime: 6.0, EndTime: 7.0, Duration: 1.0},
+	}
+	got := RefineSegments(segs, 8.0, SegmentOptions{PaddingBefore: 0.2, PaddingAfter: 0.2, MaxPause: 1.0})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d segments, want 2: %+v", len(got), got)
+	}
+	// Padding counts as speech: the 3.6s pause left after padding is shortened to 1s,
+	// so 2.6s is removed
+	assertFloat(t, got[1].StartTime-got[0].EndTime, 2.6, "removed time")
+}
+
+func TestConcatListEntry(t *testing.T) {
+	got := ConcatListEntry("/tmp/it's here/seg.mp4")
+	want := "file '/tmp/it'\\''s here/seg.mp4'\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeSegments(t *testing.T) {
+	segs := []Segment{
+		{StartTime: 6.0, EndTime: 12.0},
+		{StartTime: -1.0, EndTime: 1.0},
+		{StartTime: 0.5, EndTime: 2.0},
+		{StartTime: 3.0, EndTime: 3.0},
+	}
+	got := NormalizeSegments(segs, 10.0)
+
+	want := []Segment{
+		{StartTime: 0, EndTime: 2, Duration: 2},
+		{StartTime: 6, EndTime: 10, Duration: 4},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("segment %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestInvertSegments(t *testing.T) {
+	got := InvertSegments([]Segment{
+		{StartTime: 4.0, EndTime: 5.0},
+		{StartTime: 0.0, EndTime: 1.0},
+	}, 10.0)
+
+	want := []Segment{
+		{StartTime: 1, EndTime: 4, Duration: 3},
+		{StartTime: 5, EndTime: 10, Duration: 5},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("segment %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if all := InvertSegments([]Segment{{StartTime: 0, EndTime: 10}}, 10.0); len(all) != 0 {
+		t.Errorf("removing everything should leave nothing, got %+v", all)
+	}
+}
+
 func assertFloat(t *testing.T, got, want float64, name string) {
 	t.Helper()
 	if math.Abs(got-want) > 0.001 {
@@ -0,0 +1,197 @@
+package ffutil
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// levelWindow is the length, in seconds, of the windows MeasurePeakLevels reports.
+const levelWindow = 0.1
+
+// minLevel is the lowest level measured, in dB. Digital silence (-inf) counts as this.
+const minLevel = -100.0
+
+// minLoudnessRange is the smallest gap between the noise floor and the speech level,
+// in dB, that EstimateSilenceThreshold can place a threshold in.
+const minLoudnessRange = 6.0
+
+// ThresholdEstimate is a silence threshold chosen from a file's loudness.
+type ThresholdEstimate struct {
+	Threshold   int     // Silence threshold in dB, for silencedetect
+	NoiseFloor  float64 // Typical peak level of the quiet parts, in dB
+	SpeechLevel float64 // Typical peak level of the loud parts, in dB
+	// Fallback is set when the file has no distinct quiet and loud parts, such as a
+	// recording without pauses or one of steady noise. Threshold is then the
+	// configured one rather than an estimate.
+	Fallback bool
+}
+
+// PeakLevelArgs returns the ffmpeg arguments that print the peak level of every
+// levelWindow of path. stream and opts.Channel select the audio as in
+// SilenceDetectArgs. The audio is resampled to 48 kHz so windows have a fixed
+// number of samples.
+func PeakLevelArgs(path string, stream int, opts SilenceDetectOptions) []string {
+	args := []string{"-i", path}
+	if stream >= 0 {
+		args = append(args, "-map", fmt.Sprintf("0:a:%d", stream))
+	}
+
+	filter := fmt.Sprintf("aresample=48000,asetnsamples=n=%d:p=0,astats=metadata=1:reset=1,"+
+		"ametadata=mode=print:key=lavfi.astats.Overall.Peak_level", int(48000*levelWindow))
+	if opts.Channel > 0 {
+		filter = fmt.Sprintf("pan=mono|c0=c%d,", opts.Channel-1) + filter
+	}
+	return append(args, "-af", filter, "-f", "null", "-")
+}
+
+// ParsePeakLevels reads the window levels printed by a PeakLevelArgs run. The result
+// is indexed by window: level i covers [i, i+1) * levelWindow seconds. Windows that
+// weren't reported are left at minLevel.
+func ParsePeakLevels(output string) []float64 {
+	var levels []float64
+	window := -1
+	for _, line := range strings.Split(output, "\n") {
+		if i := strings.Index(line, "pts_time:"); i >= 0 {
+			fields := strings.Fields(line[i+len("pts_time:"):])
+			if len(fields) == 0 {
+				continue
+			}
+			t, err := strconv.ParseFloat(fields[0], 64)
+			if err != nil {
+				continue
+			}
+			window = int(math.Round(t / levelWindow))
+			continue
+		}
+		_, value, ok := strings.Cut(line, "lavfi.astats.Overall.Peak_level=")
+		if !ok || window < 0 {
+			continue
+		}
+		level, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(level) {
+			continue
+		}
+		for len(levels) <= window {
+			levels = append(levels, minLevel)
+		}
+		levels[window] = max(level, minLevel)
+	}
+	return levels
+}
+
+// MeasurePeakLevels returns the peak level of every levelWindow of path, in dB. With
+// several streams in opts.Streams each window takes the loudest of them, because a
+// moment is only silent when every stream is. Progress is reported through stage,
+// with one job per stream.
+func MeasurePeakLevels(ctx context.Context, r Runner, ffmpeg, path string, opts SilenceDetectOptions, stage *StageProgress) ([]float64, error) {
+	streams := opts.Streams
+	if len(streams) == 0 {
+		streams = []int{-1}
+	}
+
+	var levels []float64
+	for job, stream := range streams {
+		output, err := RunWithProgress(ctx, r, ffmpeg, PeakLevelArgs(path, stream, opts), stage.Reporter(job))
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("loudness measurement interrupted: %w", err)
+		}
+		measured := ParsePeakLevels(string(output))
+		if err != nil && len(measured) == 0 {
+			return nil, fmt.Errorf("failed to measure loudness: %w", err)
+		}
+		for i, level := range measured {
+			if i < len(levels) {
+				levels[i] = max(levels[i], level)
+			} else {
+				levels = append(levels, level)
+			}
+		}
+	}
+	stage.Done()
+	return levels, nil
+}
+
+// EstimateSilenceThreshold picks a silence threshold from window levels in dB. The
+// levels are split into a quiet and a loud group where the two are best separated
+// (Otsu's method); the median of each group is the noise floor and the speech level.
+// The threshold sits a third of the way from the noise floor to the speech level,
+// so quiet words stay above it while noise peaks stay below. When the groups are less
+// than minLoudnessRange apart there is nothing to separate, and the estimate falls
+// back to the fallback threshold.
+func EstimateSilenceThreshold(levels []float64, fallback int) ThresholdEstimate {
+	if len(levels) == 0 {
+		return ThresholdEstimate{Threshold: fallback, NoiseFloor: minLevel, SpeechLevel: minLevel, Fallback: true}
+	}
+	sorted := make([]float64, len(levels))
+	for i, l := range levels {
+		sorted[i] = max(l, minLevel)
+	}
+	sort.Float64s(sorted)
+
+	split := otsuSplit(sorted)
+	estimate := ThresholdEstimate{
+		NoiseFloor:  median(sorted[:split]),
+		SpeechLevel: median(sorted[split:]),
+	}
+	gap := estimate.SpeechLevel - estimate.NoiseFloor
+	if split == 0 || gap < minLoudnessRange {
+		estimate.Threshold, estimate.Fallback = fallback, true
+		return estimate
+	}
+	threshold := math.Round(estimate.NoiseFloor + gap/3)
+	estimate.Threshold = int(min(max(threshold, minLevel), -1))
+	return estimate
+}
+
+// otsuSplit returns the index that splits sorted into the two groups with the
+// largest between-group variance. It returns 0 when all values are equal.
+func otsuSplit(sorted []float64) int {
+	n := len(sorted)
+	var total float64
+	for _, v := range sorted {
+		total += v
+	}
+
+	best, bestVariance := 0, 0.0
+	var lowSum float64
+	for i := 1; i < n; i++ {
+		lowSum += sorted[i-1]
+		if sorted[i] == sorted[i-1] {
+			continue
+		}
+		lowMean := lowSum / float64(i)
+		highMean := (total - lowSum) / float64(n-i)
+		variance := float64(i) * float64(n-i) * (highMean - lowMean) * (highMean - lowMean)
+		if variance > bestVariance {
+			best, bestVariance = i, variance
+		}
+	}
+	return best
+}
+
+// median returns the middle value of sorted, or minLevel for an empty slice.
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return minLevel
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// AutoSilenceThreshold measures path and estimates its silence threshold, listening
+// to the streams and channel selected in opts. opts.Threshold is the fallback when the
+// levels don't separate.
+func AutoSilenceThreshold(ctx context.Context, r Runner, ffmpeg, path string, opts SilenceDetectOptions, stage *StageProgress) (ThresholdEstimate, error) {
+	levels, err := MeasurePeakLevels(ctx, r, ffmpeg, path, opts, stage)
+	if err != nil {
+		return ThresholdEstimate{}, err
+	}
+	return EstimateSilenceThreshold(levels, opts.Threshold), nil
+}
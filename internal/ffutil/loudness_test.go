@@ -0,0 +1,90 @@
+package ffutil
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const peakLevelOutput = `[Parsed_ametadata_3 @ 0x5581] frame:0    pts:0       pts_time:0
+[Parsed_ametadata_3 @ 0x5581] lavfi.astats.Overall.Peak_level=-inf
+[Parsed_ametadata_3 @ 0x5581] frame:1    pts:4800    pts_time:0.1
+[Parsed_ametadata_3 @ 0x5581] lavfi.astats.Overall.Peak_level=-12.5
+[Parsed_ametadata_3 @ 0x5581] frame:3    pts:14400   pts_time:0.3
+[Parsed_ametadata_3 @ 0x5581] lavfi.astats.Overall.Peak_level=-40.25
+`
+
+func TestPeakLevelArgs(t *testing.T) {
+	got := strings.Join(PeakLevelArgs("in.wav", 1, SilenceDetectOptions{Channel: 2}), " ")
+	want := "-i in.wav -map 0:a:1 -af pan=mono|c0=c1,aresample=48000,asetnsamples=n=4800:p=0," +
+		"astats=metadata=1:reset=1,ametadata=mode=print:key=lavfi.astats.Overall.Peak_level -f null -"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestParsePeakLevels(t *testing.T) {
+	got := ParsePeakLevels(peakLevelOutput)
+	want := []float64{minLevel, -12.5, minLevel, -40.25}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		assertFloat(t, got[i], want[i], "level")
+	}
+}
+
+func TestEstimateSilenceThreshold(t *testing.T) {
+	// A noisy room around -38 dB with speech peaking around -14 dB
+	var levels []float64
+	for i := 0; i < 60; i++ {
+		levels = append(levels, -38+float64(i%5)-2)
+	}
+	for i := 0; i < 40; i++ {
+		levels = append(levels, -14+float64(i%7)-3)
+	}
+
+	got := EstimateSilenceThreshold(levels, -50)
+	if got.Fallback {
+		t.Error("Fallback set for a file with pauses")
+	}
+	assertFloat(t, got.NoiseFloor, -38, "noise floor")
+	assertFloat(t, got.SpeechLevel, -14, "speech level")
+	if got.Threshold != -30 {
+		t.Errorf("Threshold = %d, want -30", got.Threshold)
+	}
+}
+
+func TestEstimateSilenceThreshold_NoContrast(t *testing.T) {
+	for name, levels := range map[string][]float64{
+		"no levels":       nil,
+		"constant levels": {-20, -20, -20},
+		"a 2 dB range":    {-22, -21, -20, -19},
+	} {
+		got := EstimateSilenceThreshold(levels, -30)
+		if !got.Fallback || got.Threshold != -30 {
+			t.Errorf("%s: got %+v, want the -30 dB fallback", name, got)
+		}
+	}
+}
+
+func TestMeasurePeakLevels_LoudestStreamPerWindow(t *testing.T) {
+	r := &scriptedRunner{stderr: map[string]string{
+		"0:a:0": "pts_time:0\nlavfi.astats.Overall.Peak_level=-50\npts_time:0.1\nlavfi.astats.Overall.Peak_level=-10\n",
+		"0:a:1": "pts_time:0\nlavfi.astats.Overall.Peak_level=-20\npts_time:0.1\nlavfi.astats.Overall.Peak_level=-60\npts_time:0.2\nlavfi.astats.Overall.Peak_level=-30\n",
+	}}
+	got, err := MeasurePeakLevels(context.Background(), r, "ffmpeg", "in.mkv", SilenceDetectOptions{Streams: []int{0, 1}}, nil)
+	if err != nil {
+		t.Fatalf("MeasurePeakLevels: %v", err)
+	}
+	if len(r.calls) != 2 {
+		t.Errorf("ran ffmpeg %d times, want once per stream", len(r.calls))
+	}
+	want := []float64{-20, -10, -30}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		assertFloat(t, got[i], want[i], "level")
+	}
+}
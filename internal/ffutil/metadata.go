@@ -0,0 +1,95 @@
+package ffutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// muxerTags are container tags that muxers write themselves; copying them over from
+// the source would describe the wrong file.
+var muxerTags = map[string]bool{
+	"major_brand":       true,
+	"minor_version":     true,
+	"compatible_brands": true,
+	"encoder":           true,
+}
+
+// RemapChapters moves chapters onto the output timeline of clips. Each chapter keeps
+// the parts of its range that clips cover, so its start moves to the first kept
+// moment and its end to the last; chapters that fall entirely inside removed ranges
+// are dropped. clips must be sorted and not overlap.
+func RemapChapters(chapters []Chapter, clips []Clip) []Chapter {
+	var remapped []Chapter
+	for _, ch := range chapters {
+		start, end := -1.0, -1.0
+		var offset float64
+		for _, c := range clips {
+			from, to := max(ch.StartTime, c.StartTime), min(ch.EndTime, c.EndTime)
+			if to > from {
+				if start < 0 {
+					start = offset + (from-c.StartTime)/c.speed()
+				}
+				end = offset + (to-c.StartTime)/c.speed()
+			}
+			offset += c.OutputDuration()
+		}
+		if start < 0 || end <= start {
+			continue
+		}
+		ch.StartTime, ch.EndTime = start, end
+		remapped = append(remapped, ch)
+	}
+	return remapped
+}
+
+// WriteFFMetadata writes global tags and chapters in ffmpeg's FFMETADATA1 format, for
+// use as an extra "-f ffmetadata -i" input with -map_metadata and -map_chapters.
+// Tags that muxers set themselves, such as major_brand and encoder, are skipped.
+func WriteFFMetadata(w io.Writer, tags map[string]string, chapters []Chapter) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(";FFMETADATA1\n")
+	writeMetadataTags(bw, tags)
+
+	for _, ch := range chapters {
+		bw.WriteString("[CHAPTER]\nTIMEBASE=1/1000000\n")
+		fmt.Fprintf(bw, "START=%d\nEND=%d\n", int64(ch.StartTime*1e6+0.5), int64(ch.EndTime*1e6+0.5))
+		chapterTags := ch.Tags
+		if tag(chapterTags, "title") == "" && ch.Title != "" {
+			chapterTags = map[string]string{"title": ch.Title}
+			for k, v := range ch.Tags {
+				chapterTags[k] = v
+			}
+		}
+		writeMetadataTags(bw, chapterTags)
+	}
+	return bw.Flush()
+}
+
+func writeMetadataTags(w *bufio.Writer, tags map[string]string) {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		if !muxerTags[strings.ToLower(k)] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s=%s\n", escapeMetadata(k), escapeMetadata(tags[k]))
+	}
+}
+
+// escapeMetadata backslash-escapes the characters FFMETADATA1 treats as special.
+func escapeMetadata(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '=', ';', '#', '\\', '\n':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package ffutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRemapChapters(t *testing.T) {
+	t.Parallel()
+
+	chapters := []Chapter{
+		{ID: 0, StartTime: 0, EndTime: 2, Title: "Intro"},
+		{ID: 1, StartTime: 2.3, EndTime: 3.7, Title: "Pause"},
+		{ID: 2, StartTime: 3.7, EndTime: 10, Title: "Main"},
+	}
+	// Keep 0-2 and 4-10: the pause chapter is removed entirely
+	clips := SegmentClips([]Segment{{StartTime: 0, EndTime: 2}, {StartTime: 4, EndTime: 10}})
+
+	got := RemapChapters(chapters, clips)
+	if len(got) != 2 {
+		t.Fatalf("got %d chapters, want 2: %+v", len(got), got)
+	}
+	if got[0].Title != "Intro" || got[1].Title != "Main" {
+		t.Errorf("titles = %q, %q; want Intro, Main", got[0].Title, got[1].Title)
+	}
+	assertFloat(t, got[0].StartTime, 0, "Intro start")
+	assertFloat(t, got[0].EndTime, 2, "Intro end")
+	// Main started inside the removed range, so it now starts where 4s lands
+	assertFloat(t, got[1].StartTime, 2, "Main start")
+	assertFloat(t, got[1].EndTime, 8, "Main end")
+}
+
+func TestRemapChapters_SpeedUp(t *testing.T) {
+	t.Parallel()
+
+	chapters := []Chapter{{StartTime: 1, EndTime: 5, Title: "All"}}
+	clips := []Clip{
+		{StartTime: 0, EndTime: 2, Speed: 1},
+		{StartTime: 2, EndTime: 6, Speed: 4}, // plays in 1s
+	}
+	got := RemapChapters(chapters, clips)
+	if len(got) != 1 {
+		t.Fatalf("got %d chapters, want 1", len(got))
+	}
+	assertFloat(t, got[0].StartTime, 1, "start")
+	assertFloat(t, got[0].EndTime, 2.75, "end")
+}
+
+func TestWriteFFMetadata(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	tags := map[string]string{
+		"title":       "Talk = part 1; #2",
+		"major_brand": "isom",
+		"artist":      "Someone",
+	}
+	chapters := []Chapter{{StartTime: 0, EndTime: 1.5, Title: "Intro", Tags: map[string]string{"title": "Intro"}}}
+	if err := WriteFFMetadata(&b, tags, chapters); err != nil {
+		t.Fatalf("WriteFFMetadata: %v", err)
+	}
+
+	want := ";FFMETADATA1\n" +
+		"artist=Someone\n" +
+		`title=Talk \= part 1\; \#2` + "\n" +
+		"[CHAPTER]\nTIMEBASE=1/1000000\nSTART=0\nEND=1500000\n" +
+		"title=Intro\n"
+	if got := b.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package ffutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+)
+
+// Packet is one demuxed packet of a stream as reported by ffprobe -show_packets.
+// Times are in seconds.
+type Packet struct {
+	PTS      float64
+	DTS      float64
+	Duration float64
+	Size     int64  // Bytes
+	Pos      int64  // Byte offset in the file; -1 if unknown
+	Flags    string // ffprobe flags, e.g. "K__" for a keyframe, "__D" for a discarded packet
+}
+
+// Keyframe reports whether the packet starts a frame that can be decoded on its own.
+func (p Packet) Keyframe() bool {
+	return strings.Contains(p.Flags, "K")
+}
+
+// ProbePackets lists the packets of the stream selected by streamSpec (an ffmpeg
+// stream specifier such as "v:0") in demux order. Only the container is read, so
+// this is much faster than decoding.
+func ProbePackets(ctx context.Context, r Runner, ffprobe, path, streamSpec string) ([]Packet, error) {
+	output, err := Output(ctx, r, ffprobe,
+		"-v", "error",
+		"-select_streams", streamSpec,
+		"-show_entries", "packet=pts_time,dts_time,duration_time,size,pos,flags",
+		"-of", "compact=p=0",
+		path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePackets(output), nil
+}
+
+// ParsePackets parses the output of "ffprobe -show_entries packet=... -of compact=p=0".
+// Missing values ("N/A") are left at zero, except PTS, which falls back to DTS, and
+// Pos, which becomes -1.
+func ParsePackets(data []byte) []Packet {
+	var packets []Packet
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		p := Packet{Pos: -1}
+		var hasPTS bool
+		for _, field := range strings.Split(line, "|") {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok || value == "N/A" {
+				continue
+			}
+			switch key {
+			case "pts_time":
+				p.PTS = parseFloat(value)
+				hasPTS = true
+			case "dts_time":
+				p.DTS = parseFloat(value)
+			case "duration_time":
+				p.Duration = parseFloat(value)
+			case "size":
+				p.Size = parseInt(value)
+			case "pos":
+				p.Pos = parseInt(value)
+			case "flags":
+				p.Flags = value
+			}
+		}
+		if !hasPTS {
+			p.PTS = p.DTS
+		}
+		packets = append(packets, p)
+	}
+	return packets
+}
+
+// KeyframeTimes returns the presentation times of the keyframe packets, sorted.
+func KeyframeTimes(packets []Packet) []float64 {
+	var times []float64
+	for _, p := range packets {
+		if p.Keyframe() {
+			times = append(times, p.PTS)
+		}
+	}
+	sort.Float64s(times)
+	return times
+}
+
+// ClosedKeyframeTimes is like KeyframeTimes but leaves out keyframes that start an
+// open GOP, such as HEVC CRA pictures or H.264 recovery points. Those are followed in
+// decode order by leading pictures that display before them and reference the
+// previous GOP, so a stream copy starting at them decodes with artifacts. packets
+// must be in decode order, as ProbePackets returns them.
+func ClosedKeyframeTimes(packets []Packet) []float64 {
+	var times []float64
+	for i, p := range packets {
+		if !p.Keyframe() {
+			continue
+		}
+		closed := true
+		for _, next := range packets[i+1:] {
+			if next.Keyframe() {
+				break
+			}
+			if next.PTS < p.PTS {
+				closed = false
+				break
+			}
+		}
+		if closed {
+			times = append(times, p.PTS)
+		}
+	}
+	sort.Float64s(times)
+	return times
+}
+
+// NextKeyframe returns the first keyframe time at or after t, allowing for tolerance
+// seconds of timestamp rounding. keyframes must be sorted. ok is false if there is none.
+func NextKeyframe(keyframes []float64, t, tolerance float64) (k float64, ok bool) {
+	i := sort.SearchFloat64s(keyframes, t-tolerance)
+	if i == len(keyframes) {
+		return 0, false
+	}
+	return keyframes[i], true
+}
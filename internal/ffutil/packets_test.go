@@ -0,0 +1,119 @@
+package ffutil
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const samplePackets = `pts_time=0.000000|dts_time=-0.066667|duration_time=0.033333|size=21054|pos=48|flags=K__
+pts_time=0.133333|dts_time=-0.033333|duration_time=0.033333|size=812|pos=21102|flags=___
+pts_time=0.066667|dts_time=0.000000|duration_time=0.033333|size=301|pos=21914|flags=___
+pts_time=2.000000|dts_time=1.933333|duration_time=0.033333|size=19876|pos=N/A|flags=K__
+pts_time=N/A|dts_time=4.000000|duration_time=0.033333|size=20011|pos=90210|flags=K_D
+`
+
+func TestParsePackets(t *testing.T) {
+	t.Parallel()
+
+	packets := ParsePackets([]byte(samplePackets))
+	if len(packets) != 5 {
+		t.Fatalf("got %d packets, want 5", len(packets))
+	}
+
+	p := packets[0]
+	assertFloat(t, p.PTS, 0, "PTS")
+	assertFloat(t, p.DTS, -0.066667, "DTS")
+	assertFloat(t, p.Duration, 0.033333, "Duration")
+	if p.Size != 21054 || p.Pos != 48 || p.Flags != "K__" {
+		t.Errorf("packet 0 = %+v", p)
+	}
+	if !p.Keyframe() || packets[1].Keyframe() {
+		t.Error("keyframe flags not detected")
+	}
+	if packets[3].Pos != -1 {
+		t.Errorf("Pos = %d for N/A, want -1", packets[3].Pos)
+	}
+	assertFloat(t, packets[4].PTS, 4.0, "PTS falls back to DTS")
+}
+
+func TestKeyframeTimes(t *testing.T) {
+	t.Parallel()
+
+	got := KeyframeTimes(ParsePackets([]byte(samplePackets)))
+	want := []float64{0, 2, 4}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		assertFloat(t, got[i], want[i], "keyframe")
+	}
+}
+
+func TestClosedKeyframeTimes(t *testing.T) {
+	t.Parallel()
+
+	// The keyframe at 2s is followed by two B-frames shown before it: an open GOP
+	packets := ParsePackets([]byte(`pts_time=0.000000|dts_time=-0.080000|flags=K__
+pts_time=0.120000|dts_time=-0.040000|flags=___
+pts_time=0.040000|dts_time=0.000000|flags=___
+pts_time=2.000000|dts_time=1.920000|flags=K__
+pts_time=1.920000|dts_time=1.960000|flags=___
+pts_time=1.960000|dts_time=2.000000|flags=___
+pts_time=4.000000|dts_time=3.960000|flags=K__
+pts_time=4.080000|dts_time=4.000000|flags=___
+`))
+	got := ClosedKeyframeTimes(packets)
+	want := []float64{0, 4}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		assertFloat(t, got[i], want[i], "keyframe")
+	}
+	if all := KeyframeTimes(packets); len(all) != 3 {
+		t.Errorf("KeyframeTimes = %v, want all 3 keyframes", all)
+	}
+}
+
+func TestNextKeyframe(t *testing.T) {
+	t.Parallel()
+
+	keyframes := []float64{0, 2, 4}
+	tests := []struct {
+		t      float64
+		want   float64
+		wantOK bool
+	}{
+		{0, 0, true},
+		{0.5, 2, true},
+		{1.99, 2, true}, // within tolerance of the keyframe
+		{2.01, 2, true},
+		{2.5, 4, true},
+		{4.5, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := NextKeyframe(keyframes, tt.t, 0.02)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("NextKeyframe(%.2f) = %.2f, %v; want %.2f, %v", tt.t, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestProbePackets_Args(t *testing.T) {
+	t.Parallel()
+
+	r := &fakeRunner{stdout: samplePackets}
+	packets, err := ProbePackets(context.Background(), r, "ffprobe", "in.mp4", "v:0")
+	if err != nil {
+		t.Fatalf("ProbePackets: %v", err)
+	}
+	if len(packets) != 5 {
+		t.Errorf("got %d packets, want 5", len(packets))
+	}
+	got := strings.Join(r.args, " ")
+	want := "-v error -select_streams v:0 -show_entries packet=pts_time,dts_time,duration_time,size,pos,flags -of compact=p=0 in.mp4"
+	if got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+}
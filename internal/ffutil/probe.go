@@ -0,0 +1,374 @@
+package ffutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Stream types reported in Stream.CodecType
+const (
+	StreamTypeVideo      = "video"
+	StreamTypeAudio      = "audio"
+	StreamTypeSubtitle   = "subtitle"
+	StreamTypeData       = "data"
+	StreamTypeAttachment = "attachment"
+)
+
+// ProbeResult is the full ffprobe description of a media file.
+type ProbeResult struct {
+	Streams  []Stream
+	Format   Format
+	Chapters []Chapter
+}
+
+// Stream describes one stream of a media file. Fields that do not apply to the
+// stream's type are left at their zero value.
+type Stream struct {
+	Index         int    // Stream index within the file
+	CodecType     string // StreamTypeVideo, StreamTypeAudio, StreamTypeSubtitle, ...
+	CodecName     string // Short decoder name, e.g. "h264", "aac"
+	CodecLongName string
+	Profile       string // e.g. "High", "LC"
+	Level         int    // Codec level as ffprobe reports it, e.g. 41 for H.264 4.1 or 123 for HEVC 4.1; 0 if unknown
+	BitRate       int64  // Bits per second; 0 if unknown
+	Duration      float64
+	StartTime     float64
+	TimeBase      string // e.g. "1/15360"
+	NumFrames     int64  // 0 if unknown
+	Language      string // ISO 639 language tag, e.g. "eng"
+	Title         string
+	Tags          map[string]string
+	Disposition   Disposition
+
+	// Video
+	Width              int
+	Height             int
+	PixelFormat        string
+	FrameRate          float64 // Real base frame rate (r_frame_rate)
+	FrameRateFraction  string  // r_frame_rate as ffprobe reports it, e.g. "30000/1001"
+	AvgFrameRate       float64
+	SampleAspectRatio  string
+	DisplayAspectRatio string
+	FieldOrder         string
+	Rotation           int // Display rotation in degrees, from the display matrix or the rotate tag
+	ColorRange         string
+	ColorSpace         string
+	ColorTransfer      string
+	ColorPrimaries     string
+
+	// Audio
+	SampleRate    int
+	Channels      int
+	ChannelLayout string // e.g. "stereo", "5.1(side)"
+	SampleFormat  string // e.g. "fltp", "s16"
+}
+
+// IsVideo reports whether the stream carries moving pictures. Cover art and other
+// attached pictures are video streams to ffprobe but are excluded here.
+func (s *Stream) IsVideo() bool {
+	return s.CodecType == StreamTypeVideo && !s.Disposition.AttachedPic
+}
+
+// IsAudio reports whether the stream is an audio stream.
+func (s *Stream) IsAudio() bool {
+	return s.CodecType == StreamTypeAudio
+}
+
+// IsSubtitle reports whether the stream is a subtitle stream.
+func (s *Stream) IsSubtitle() bool {
+	return s.CodecType == StreamTypeSubtitle
+}
+
+// Disposition holds a stream's disposition flags.
+type Disposition struct {
+	Default         bool
+	Dub             bool
+	Original        bool
+	Comment         bool
+	Lyrics          bool
+	Karaoke         bool
+	Forced          bool
+	HearingImpaired bool
+	VisualImpaired  bool
+	CleanEffects    bool
+	AttachedPic     bool // Cover art or thumbnail, not actual video
+	TimedThumbnails bool
+	Captions        bool
+	Descriptions    bool
+	Metadata        bool
+}
+
+// Format describes the container of a media file.
+type Format struct {
+	Filename       string
+	FormatName     string // e.g. "mov,mp4,m4a,3gp,3g2,mj2"
+	FormatLongName string
+	NumStreams     int
+	StartTime      float64
+	Duration       float64
+	Size           int64 // Bytes
+	BitRate        int64 // Bits per second
+	ProbeScore     int
+	Tags           map[string]string
+}
+
+// Chapter describes a chapter marker.
+type Chapter struct {
+	ID        int64
+	StartTime float64
+	EndTime   float64
+	Title     string
+	Tags      map[string]string
+}
+
+// VideoStreams returns the streams for which IsVideo is true.
+func (r *ProbeResult) VideoStreams() []Stream {
+	return r.filter((*Stream).IsVideo)
+}
+
+// AudioStreams returns the audio streams.
+func (r *ProbeResult) AudioStreams() []Stream {
+	return r.filter((*Stream).IsAudio)
+}
+
+// SubtitleStreams returns the subtitle streams.
+func (r *ProbeResult) SubtitleStreams() []Stream {
+	return r.filter((*Stream).IsSubtitle)
+}
+
+func (r *ProbeResult) filter(keep func(*Stream) bool) []Stream {
+	var streams []Stream
+	for i := range r.Streams {
+		if keep(&r.Streams[i]) {
+			streams = append(streams, r.Streams[i])
+		}
+	}
+	return streams
+}
+
+// Clone returns a copy that shares no slices with r. Tag maps are shared.
+func (r *ProbeResult) Clone() *ProbeResult {
+	c := *r
+	c.Streams = append([]Stream(nil), r.Streams...)
+	c.Chapters = append([]Chapter(nil), r.Chapters...)
+	return &c
+}
+
+// Probe runs ffprobe on path through r and parses the JSON description of all
+// streams, the container format and chapters.
+func Probe(ctx context.Context, r Runner, ffprobe, path string) (*ProbeResult, error) {
+	output, err := Output(ctx, r, ffprobe,
+		"-v", "error",
+		"-of", "json",
+		"-show_streams",
+		"-show_format",
+		"-show_chapters",
+		path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProbe(output)
+}
+
+// rawProbe mirrors ffprobe's JSON. ffprobe prints most numbers as strings and
+// flags as 0/1, so values are converted in ParseProbe.
+type rawProbe struct {
+	Streams []struct {
+		Index              int               `json:"index"`
+		CodecName          string            `json:"codec_name"`
+		CodecLongName      string            `json:"codec_long_name"`
+		Profile            string            `json:"profile"`
+		Level              int               `json:"level"`
+		CodecType          string            `json:"codec_type"`
+		Width              int               `json:"width"`
+		Height             int               `json:"height"`
+		PixFmt             string            `json:"pix_fmt"`
+		RFrameRate         string            `json:"r_frame_rate"`
+		AvgFrameRate       string            `json:"avg_frame_rate"`
+		SampleAspectRatio  string            `json:"sample_aspect_ratio"`
+		DisplayAspectRatio string            `json:"display_aspect_ratio"`
+		FieldOrder         string            `json:"field_order"`
+		ColorRange         string            `json:"color_range"`
+		ColorSpace         string            `json:"color_space"`
+		ColorTransfer      string            `json:"color_transfer"`
+		ColorPrimaries     string            `json:"color_primaries"`
+		SampleFmt          string            `json:"sample_fmt"`
+		SampleRate         string            `json:"sample_rate"`
+		Channels           int               `json:"channels"`
+		ChannelLayout      string            `json:"channel_layout"`
+		BitRate            string            `json:"bit_rate"`
+		Duration           string            `json:"duration"`
+		StartTime          string            `json:"start_time"`
+		TimeBase           string            `json:"time_base"`
+		NbFrames           string            `json:"nb_frames"`
+		Disposition        map[string]int    `json:"disposition"`
+		Tags               map[string]string `json:"tags"`
+		SideDataList       []struct {
+			SideDataType string  `json:"side_data_type"`
+			Rotation     float64 `json:"rotation"`
+		} `json:"side_data_list"`
+	} `json:"streams"`
+	Format struct {
+		Filename       string            `json:"filename"`
+		NbStreams      int               `json:"nb_streams"`
+		FormatName     string            `json:"format_name"`
+		FormatLongName string            `json:"format_long_name"`
+		StartTime      string            `json:"start_time"`
+		Duration       string            `json:"duration"`
+		Size           string            `json:"size"`
+		BitRate        string            `json:"bit_rate"`
+		ProbeScore     int               `json:"probe_score"`
+		Tags           map[string]string `json:"tags"`
+	} `json:"format"`
+	Chapters []struct {
+		ID        int64             `json:"id"`
+		StartTime string            `json:"start_time"`
+		EndTime   string            `json:"end_time"`
+		Tags      map[string]string `json:"tags"`
+	} `json:"chapters"`
+}
+
+// ParseProbe parses the output of "ffprobe -of json -show_streams -show_format
+// -show_chapters".
+func ParseProbe(data []byte) (*ProbeResult, error) {
+	var raw rawProbe
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	result := &ProbeResult{
+		Format: Format{
+			Filename:       raw.Format.Filename,
+			FormatName:     raw.Format.FormatName,
+			FormatLongName: raw.Format.FormatLongName,
+			NumStreams:     raw.Format.NbStreams,
+			StartTime:      parseFloat(raw.Format.StartTime),
+			Duration:       parseFloat(raw.Format.Duration),
+			Size:           parseInt(raw.Format.Size),
+			BitRate:        parseInt(raw.Format.BitRate),
+			ProbeScore:     raw.Format.ProbeScore,
+			Tags:           raw.Format.Tags,
+		},
+	}
+
+	for _, s := range raw.Streams {
+		stream := Stream{
+			Index:              s.Index,
+			CodecType:          s.CodecType,
+			CodecName:          s.CodecName,
+			CodecLongName:      s.CodecLongName,
+			Profile:            s.Profile,
+			Level:              max(s.Level, 0),
+			BitRate:            parseInt(s.BitRate),
+			Duration:           parseFloat(s.Duration),
+			StartTime:          parseFloat(s.StartTime),
+			TimeBase:           s.TimeBase,
+			NumFrames:          parseInt(s.NbFrames),
+			Language:           tag(s.Tags, "language"),
+			Title:              tag(s.Tags, "title"),
+			Tags:               s.Tags,
+			Disposition:        parseDisposition(s.Disposition),
+			Width:              s.Width,
+			Height:             s.Height,
+			PixelFormat:        s.PixFmt,
+			FrameRate:          ParseRational(s.RFrameRate),
+			FrameRateFraction:  s.RFrameRate,
+			AvgFrameRate:       ParseRational(s.AvgFrameRate),
+			SampleAspectRatio:  s.SampleAspectRatio,
+			DisplayAspectRatio: s.DisplayAspectRatio,
+			FieldOrder:         s.FieldOrder,
+			ColorRange:         s.ColorRange,
+			ColorSpace:         s.ColorSpace,
+			ColorTransfer:      s.ColorTransfer,
+			ColorPrimaries:     s.ColorPrimaries,
+			SampleRate:         int(parseInt(s.SampleRate)),
+			Channels:           s.Channels,
+			ChannelLayout:      s.ChannelLayout,
+			SampleFormat:       s.SampleFmt,
+		}
+		for _, sd := range s.SideDataList {
+			if sd.SideDataType == "Display Matrix" {
+				stream.Rotation = int(sd.Rotation)
+			}
+		}
+		if stream.Rotation == 0 {
+			stream.Rotation = int(parseInt(tag(s.Tags, "rotate")))
+		}
+		result.Streams = append(result.Streams, stream)
+	}
+
+	for _, c := range raw.Chapters {
+		result.Chapters = append(result.Chapters, Chapter{
+			ID:        c.ID,
+			StartTime: parseFloat(c.StartTime),
+			EndTime:   parseFloat(c.EndTime),
+			Title:     tag(c.Tags, "title"),
+			Tags:      c.Tags,
+		})
+	}
+
+	return result, nil
+}
+
+// ParseRational parses a ffprobe rational such as "30000/1001" or a plain number.
+// Returns 0 for "0/0", "N/A" and malformed input.
+func ParseRational(s string) float64 {
+	num, den, ok := strings.Cut(s, "/")
+	if !ok {
+		return parseFloat(s)
+	}
+	n, d := parseFloat(num), parseFloat(den)
+	if d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+func parseDisposition(flags map[string]int) Disposition {
+	return Disposition{
+		Default:         flags["default"] != 0,
+		Dub:             flags["dub"] != 0,
+		Original:        flags["original"] != 0,
+		Comment:         flags["comment"] != 0,
+		Lyrics:          flags["lyrics"] != 0,
+		Karaoke:         flags["karaoke"] != 0,
+		Forced:          flags["forced"] != 0,
+		HearingImpaired: flags["hearing_impaired"] != 0,
+		VisualImpaired:  flags["visual_impaired"] != 0,
+		CleanEffects:    flags["clean_effects"] != 0,
+		AttachedPic:     flags["attached_pic"] != 0,
+		TimedThumbnails: flags["timed_thumbnails"] != 0,
+		Captions:        flags["captions"] != 0,
+		Descriptions:    flags["descriptions"] != 0,
+		Metadata:        flags["metadata"] != 0,
+	}
+}
+
+// tag looks up a tag case-insensitively; containers disagree on tag name casing.
+func tag(tags map[string]string, key string) string {
+	if v, ok := tags[key]; ok {
+		return v
+	}
+	for k, v := range tags {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+func parseFloat(s string) float64 {
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+func parseInt(s string) int64 {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return int64(parseFloat(s))
+	}
+	return i
+}
@@ -0,0 +1,252 @@
+package ffutil
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const sampleProbeJSON = `{
+    "streams": [
+        {
+            "index": 0,
+            "codec_name": "h264",
+            "codec_long_name": "H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10",
+            "profile": "High",
+            "level": 40,
+            "codec_type": "video",
+            "width": 1920,
+            "height": 1080,
+            "pix_fmt": "yuv420p",
+            "color_range": "tv",
+            "color_space": "bt709",
+            "color_transfer": "bt709",
+            "color_primaries": "bt709",
+            "field_order": "progressive",
+            "r_frame_rate": "30000/1001",
+            "avg_frame_rate": "30000/1001",
+            "time_base": "1/30000",
+            "start_time": "0.000000",
+            "duration": "10.010000",
+            "bit_rate": "4500000",
+            "nb_frames": "300",
+            "disposition": {"default": 1, "attached_pic": 0},
+            "tags": {"language": "und", "handler_name": "VideoHandler"},
+            "side_data_list": [{"side_data_type": "Display Matrix", "rotation": -90}]
+        },
+        {
+            "index": 1,
+            "codec_name": "aac",
+            "profile": "LC",
+            "codec_type": "audio",
+            "sample_fmt": "fltp",
+            "sample_rate": "48000",
+            "channels": 2,
+            "channel_layout": "stereo",
+            "bit_rate": "128000",
+            "disposition": {"default": 1},
+            "tags": {"language": "eng", "title": "Main"}
+        },
+        {
+            "index": 2,
+            "codec_name": "ac3",
+            "codec_type": "audio",
+            "sample_rate": "48000",
+            "channels": 6,
+            "channel_layout": "5.1(side)",
+            "disposition": {"default": 0},
+            "tags": {"LANGUAGE": "por"}
+        },
+        {
+            "index": 3,
+            "codec_name": "mov_text",
+            "codec_type": "subtitle",
+            "disposition": {"forced": 1},
+            "tags": {"language": "eng"}
+        },
+        {
+            "index": 4,
+            "codec_name": "mjpeg",
+            "codec_type": "video",
+            "width": 600,
+            "height": 600,
+            "r_frame_rate": "90000/1",
+            "disposition": {"attached_pic": 1}
+        }
+    ],
+    "chapters": [
+        {"id": 0, "time_base": "1/1000", "start_time": "0.000000", "end_time": "5.000000", "tags": {"title": "Intro"}},
+        {"id": 1, "time_base": "1/1000", "start_time": "5.000000", "end_time": "10.010000", "tags": {"title": "Outro"}}
+    ],
+    "format": {
+        "filename": "input.mp4",
+        "nb_streams": 5,
+        "format_name": "mov,mp4,m4a,3gp,3g2,mj2",
+        "start_time": "0.000000",
+        "duration": "10.010000",
+        "size": "6000000",
+        "bit_rate": "4795204",
+        "probe_score": 100,
+        "tags": {"title": "Sample"}
+    }
+}`
+
+func TestParseProbe(t *testing.T) {
+	t.Parallel()
+
+	result, err := ParseProbe([]byte(sampleProbeJSON))
+	if err != nil {
+		t.Fatalf("ParseProbe: %v", err)
+	}
+
+	if len(result.Streams) != 5 {
+		t.Fatalf("got %d streams, want 5", len(result.Streams))
+	}
+
+	v := result.Streams[0]
+	if v.CodecName != "h264" || v.Profile != "High" || v.Level != 40 || v.Width != 1920 || v.Height != 1080 {
+		t.Errorf("video stream = %+v", v)
+	}
+	assertFloat(t, v.FrameRate, 29.97, "FrameRate")
+	if v.FrameRateFraction != "30000/1001" {
+		t.Errorf("FrameRateFraction = %q, want 30000/1001", v.FrameRateFraction)
+	}
+	if v.BitRate != 4500000 || v.NumFrames != 300 {
+		t.Errorf("BitRate/NumFrames = %d/%d, want 4500000/300", v.BitRate, v.NumFrames)
+	}
+	if v.Rotation != -90 {
+		t.Errorf("Rotation = %d, want -90", v.Rotation)
+	}
+	if v.ColorSpace != "bt709" || v.ColorRange != "tv" || v.ColorTransfer != "bt709" || v.ColorPrimaries != "bt709" {
+		t.Errorf("color metadata = %q/%q/%q/%q", v.ColorSpace, v.ColorRange, v.ColorTransfer, v.ColorPrimaries)
+	}
+	if !v.Disposition.Default || v.Disposition.AttachedPic {
+		t.Errorf("video disposition = %+v", v.Disposition)
+	}
+
+	a := result.Streams[1]
+	if a.SampleRate != 48000 || a.Channels != 2 || a.ChannelLayout != "stereo" || a.SampleFormat != "fltp" {
+		t.Errorf("audio stream = %+v", a)
+	}
+	if a.Language != "eng" || a.Title != "Main" {
+		t.Errorf("audio tags = %q/%q, want eng/Main", a.Language, a.Title)
+	}
+	if result.Streams[2].Language != "por" {
+		t.Errorf("upper-case language tag = %q, want por", result.Streams[2].Language)
+	}
+	if !result.Streams[3].Disposition.Forced {
+		t.Error("subtitle stream should be forced")
+	}
+
+	if got := len(result.VideoStreams()); got != 1 {
+		t.Errorf("VideoStreams() = %d, want 1 (attached picture excluded)", got)
+	}
+	if got := len(result.AudioStreams()); got != 2 {
+		t.Errorf("AudioStreams() = %d, want 2", got)
+	}
+	if got := len(result.SubtitleStreams()); got != 1 {
+		t.Errorf("SubtitleStreams() = %d, want 1", got)
+	}
+
+	f := result.Format
+	assertFloat(t, f.Duration, 10.01, "Format.Duration")
+	if f.Size != 6000000 || f.BitRate != 4795204 || f.NumStreams != 5 || f.Tags["title"] != "Sample" {
+		t.Errorf("format = %+v", f)
+	}
+
+	if len(result.Chapters) != 2 {
+		t.Fatalf("got %d chapters, want 2", len(result.Chapters))
+	}
+	c := result.Chapters[1]
+	if c.ID != 1 || c.Title != "Outro" {
+		t.Errorf("chapter = %+v", c)
+	}
+	assertFloat(t, c.StartTime, 5, "Chapter.StartTime")
+	assertFloat(t, c.EndTime, 10.01, "Chapter.EndTime")
+}
+
+func TestParseProbe_RotateTag(t *testing.T) {
+	t.Parallel()
+
+	result, err := ParseProbe([]byte(`{"streams": [{"codec_type": "video", "tags": {"rotate": "90"}}], "format": {}}`))
+	if err != nil {
+		t.Fatalf("ParseProbe: %v", err)
+	}
+	if got := result.Streams[0].Rotation; got != 90 {
+		t.Errorf("Rotation = %d, want 90", got)
+	}
+}
+
+func TestParseProbe_NotAvailableValues(t *testing.T) {
+	t.Parallel()
+
+	result, err := ParseProbe([]byte(`{"streams": [{"codec_type": "video", "r_frame_rate": "0/0", "bit_rate": "N/A"}], "format": {"duration": "N/A"}}`))
+	if err != nil {
+		t.Fatalf("ParseProbe: %v", err)
+	}
+	if s := result.Streams[0]; s.FrameRate != 0 || s.BitRate != 0 {
+		t.Errorf("FrameRate/BitRate = %v/%d, want zero values", s.FrameRate, s.BitRate)
+	}
+	if result.Format.Duration != 0 {
+		t.Errorf("Duration = %v, want 0", result.Format.Duration)
+	}
+}
+
+func TestParseProbe_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	if _, err := ParseProbe([]byte("width=1920")); err == nil {
+		t.Fatal("expected error for non-JSON output")
+	}
+}
+
+func TestProbe_Args(t *testing.T) {
+	t.Parallel()
+
+	r := &fakeRunner{stdout: sampleProbeJSON}
+	result, err := Probe(context.Background(), r, "/opt/ffprobe", "in.mp4")
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if len(result.Streams) != 5 {
+		t.Errorf("got %d streams, want 5", len(result.Streams))
+	}
+	if r.name != "/opt/ffprobe" {
+		t.Errorf("name = %q, want /opt/ffprobe", r.name)
+	}
+	got := strings.Join(r.args, " ")
+	want := "-v error -of json -show_streams -show_format -show_chapters in.mp4"
+	if got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+}
+
+func TestProbeResult_CloneDoesNotShareStreams(t *testing.T) {
+	t.Parallel()
+
+	result, err := ParseProbe([]byte(sampleProbeJSON))
+	if err != nil {
+		t.Fatalf("ParseProbe: %v", err)
+	}
+	clone := result.Clone()
+	clone.Streams[0].CodecName = "changed"
+	if result.Streams[0].CodecName != "h264" {
+		t.Error("modifying the clone changed the original")
+	}
+}
+
+func TestParseRational(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]float64{
+		"30/1":       30,
+		"30000/1001": 30000.0 / 1001.0,
+		"25":         25,
+		"0/0":        0,
+		"N/A":        0,
+		"":           0,
+	}
+	for in, want := range tests {
+		assertFloat(t, ParseRational(in), want, in)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package ffutil
+
+import "os/exec"
+
+// setProcessGroup is a no-op on platforms without process groups; cancellation
+// falls back to exec's default of killing the direct child.
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,210 @@
+package ffutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Stage identifies which part of an operation a Progress value refers to.
+type Stage string
+
+// Stages reported by long-running operations
+const (
+	StageConvert Stage = "convert" // re-encoding a whole file (Convert, ExtractSegment)
+	StageDetect  Stage = "detect"  // running silence detection over the input
+	StageExtract Stage = "extract" // cutting the kept segments out of the input
+	StageConcat  Stage = "concat"  // joining segments into the final output
+	StageRender  Stage = "render"  // rendering a whole edit in one ffmpeg pass
+)
+
+// Progress describes how far a long-running operation has come.
+type Progress struct {
+	Stage   Stage   // Part of the operation currently running
+	OutTime float64 // Seconds of media processed so far within the current stage
+	Percent float64 // Overall completion of the whole operation, from 0 to 100
+	Speed   float64 // Processing speed relative to real time (2.0 = twice as fast); 0 if unknown
+	FPS     float64 // Frames processed per second; 0 for audio-only work
+}
+
+// ProgressFunc receives progress updates. Calls are serialized, so the function does
+// not need to be safe for concurrent use, but it should return quickly.
+type ProgressFunc func(Progress)
+
+// ProgressReport is one block of key=value lines written by ffmpeg's -progress option.
+type ProgressReport struct {
+	OutTime float64 // Seconds of output written so far
+	Speed   float64 // Speed relative to real time; 0 when ffmpeg reports N/A
+	FPS     float64 // Frames per second
+	End     bool    // True for the final report of the process
+}
+
+// ParseProgress reads ffmpeg -progress output from r and calls fn once per complete
+// report block (each block ends with a "progress=continue" or "progress=end" line).
+// It returns when r is exhausted.
+func ParseProgress(r io.Reader, fn func(ProgressReport)) {
+	var report ProgressReport
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "out_time_us", "out_time_ms":
+			// Despite its name, out_time_ms is also in microseconds
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil && us >= 0 {
+				report.OutTime = float64(us) / 1e6
+			}
+		case "speed":
+			if x, err := strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64); err == nil {
+				report.Speed = x
+			}
+		case "fps":
+			if fps, err := strconv.ParseFloat(value, 64); err == nil {
+				report.FPS = fps
+			}
+		case "progress":
+			report.End = value == "end"
+			fn(report)
+			report = ProgressReport{}
+		}
+	}
+	// Keep draining so ffmpeg never blocks on a full pipe after a scanner error
+	_, _ = io.Copy(io.Discard, r)
+}
+
+// RunWithProgress runs name with args through r, adding "-progress pipe:1 -nostats"
+// so ffmpeg writes machine-readable progress to stdout, and calls onReport for every
+// report. It returns the command's stderr; failures are reported as *FFmpegError.
+// If onReport is nil it behaves like CombinedOutput. If ctx is done before the
+// command finishes, the returned error wraps ctx.Err().
+func RunWithProgress(ctx context.Context, r Runner, name string, args []string, onReport func(ProgressReport)) ([]byte, error) {
+	if onReport == nil {
+		return CombinedOutput(ctx, r, name, args...)
+	}
+
+	args = append([]string{"-progress", "pipe:1", "-nostats"}, args...)
+
+	pr, pw := io.Pipe()
+	parsed := make(chan struct{})
+	go func() {
+		defer close(parsed)
+		ParseProgress(pr, onReport)
+	}()
+
+	var stderr bytes.Buffer
+	err := r.Run(ctx, name, args, nil, pw, &stderr)
+	pw.Close()
+	<-parsed
+	return stderr.Bytes(), commandError(ctx, name, args, stderr.Bytes(), err)
+}
+
+// ProgressTracker combines reports from the ffmpeg processes of an operation into a
+// single overall percentage. Each stage of the operation owns a slice of the 0-100
+// range. A nil *ProgressTracker is valid and discards everything, so callers can
+// thread it through unconditionally.
+type ProgressTracker struct {
+	mu      sync.Mutex
+	fn      ProgressFunc
+	percent float64
+}
+
+// NewProgressTracker returns a tracker that forwards updates to fn, or nil if fn is nil.
+func NewProgressTracker(fn ProgressFunc) *ProgressTracker {
+	if fn == nil {
+		return nil
+	}
+	return &ProgressTracker{fn: fn}
+}
+
+// Stage starts a stage spanning the [from, to] percent range of the overall operation.
+// total is the amount of media, in seconds, the stage will process across all of its
+// jobs; jobs is the number of ffmpeg processes that contribute to it.
+func (t *ProgressTracker) Stage(stage Stage, from, to, total float64, jobs int) *StageProgress {
+	if t == nil {
+		return nil
+	}
+	if jobs < 1 {
+		jobs = 1
+	}
+	return &StageProgress{
+		tracker: t,
+		stage:   stage,
+		from:    from,
+		to:      to,
+		total:   total,
+		done:    make([]float64, jobs),
+	}
+}
+
+func (t *ProgressTracker) emit(p Progress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Parallel jobs can report slightly out of order; never move backwards
+	if p.Percent < t.percent {
+		p.Percent = t.percent
+	}
+	t.percent = p.Percent
+	t.fn(p)
+}
+
+// StageProgress tracks a single stage of an operation. A nil *StageProgress is valid
+// and discards everything.
+type StageProgress struct {
+	tracker  *ProgressTracker
+	stage    Stage
+	from, to float64
+	total    float64
+
+	mu   sync.Mutex
+	done []float64
+}
+
+// Reporter returns the callback to pass to RunWithProgress for the given job, or nil
+// when progress is not being tracked.
+func (s *StageProgress) Reporter(job int) func(ProgressReport) {
+	if s == nil {
+		return nil
+	}
+	return func(r ProgressReport) {
+		s.mu.Lock()
+		s.done[job] = r.OutTime
+		var sum float64
+		for _, d := range s.done {
+			sum += d
+		}
+		s.mu.Unlock()
+
+		s.tracker.emit(Progress{
+			Stage:   s.stage,
+			OutTime: sum,
+			Percent: s.percentAt(sum),
+			Speed:   r.Speed,
+			FPS:     r.FPS,
+		})
+	}
+}
+
+// Done marks the stage as complete, reporting the end of its range.
+func (s *StageProgress) Done() {
+	if s == nil {
+		return
+	}
+	s.tracker.emit(Progress{Stage: s.stage, OutTime: s.total, Percent: s.to})
+}
+
+func (s *StageProgress) percentAt(processed float64) float64 {
+	if s.total <= 0 {
+		return s.from
+	}
+	fraction := processed / s.total
+	if fraction > 1 {
+		fraction = 1
+	}
+	return s.from + (s.to-s.from)*fraction
+}
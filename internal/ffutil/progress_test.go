@@ -0,0 +1,110 @@
+package ffutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProgress_Blocks(t *testing.T) {
+	output := `frame=30
+fps=29.97
+out_time_us=1000000
+out_time_ms=1000000
+out_time=00:00:01.000000
+speed=2.5x
+progress=continue
+frame=60
+fps=30.00
+out_time_us=2500000
+speed=N/A
+progress=end
+`
+	var reports []ProgressReport
+	ParseProgress(strings.NewReader(output), func(r ProgressReport) {
+		reports = append(reports, r)
+	})
+
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+	assertFloat(t, reports[0].OutTime, 1.0, "reports[0].OutTime")
+	assertFloat(t, reports[0].Speed, 2.5, "reports[0].Speed")
+	assertFloat(t, reports[0].FPS, 29.97, "reports[0].FPS")
+	if reports[0].End {
+		t.Error("reports[0].End should be false")
+	}
+	assertFloat(t, reports[1].OutTime, 2.5, "reports[1].OutTime")
+	assertFloat(t, reports[1].Speed, 0, "reports[1].Speed (N/A)")
+	if !reports[1].End {
+		t.Error("reports[1].End should be true")
+	}
+}
+
+func TestParseProgress_IgnoresNegativeOutTime(t *testing.T) {
+	// ffmpeg reports a negative out_time before the first packet is written
+	output := "out_time_us=-9223372036854775807\nprogress=continue\n"
+	var got ProgressReport
+	ParseProgress(strings.NewReader(output), func(r ProgressReport) { got = r })
+	assertFloat(t, got.OutTime, 0, "OutTime")
+}
+
+func TestProgressTracker_Nil(t *testing.T) {
+	tracker := NewProgressTracker(nil)
+	if tracker != nil {
+		t.Fatal("expected nil tracker for nil func")
+	}
+	stage := tracker.Stage(StageDetect, 0, 100, 10, 1)
+	if stage.Reporter(0) != nil {
+		t.Fatal("expected nil reporter from nil stage")
+	}
+	stage.Done() // must not panic
+}
+
+func TestProgressTracker_StageRanges(t *testing.T) {
+	var updates []Progress
+	tracker := NewProgressTracker(func(p Progress) { updates = append(updates, p) })
+
+	detect := tracker.Stage(StageDetect, 0, 20, 10, 1)
+	detect.Reporter(0)(ProgressReport{OutTime: 5})
+	detect.Done()
+
+	extract := tracker.Stage(StageExtract, 20, 100, 8, 2)
+	extract.Reporter(0)(ProgressReport{OutTime: 2})
+	extract.Reporter(1)(ProgressReport{OutTime: 2})
+
+	want := []struct {
+		stage   Stage
+		percent float64
+	}{
+		{StageDetect, 10},
+		{StageDetect, 20},
+		{StageExtract, 40},
+		{StageExtract, 60},
+	}
+	if len(updates) != len(want) {
+		t.Fatalf("expected %d updates, got %d", len(want), len(updates))
+	}
+	for i, w := range want {
+		if updates[i].Stage != w.stage {
+			t.Errorf("update %d: stage %q, want %q", i, updates[i].Stage, w.stage)
+		}
+		assertFloat(t, updates[i].Percent, w.percent, "percent")
+	}
+}
+
+func TestProgressTracker_NeverMovesBackwards(t *testing.T) {
+	var last float64
+	tracker := NewProgressTracker(func(p Progress) {
+		if p.Percent < last {
+			t.Errorf("percent moved backwards: %.1f -> %.1f", last, p.Percent)
+		}
+		last = p.Percent
+	})
+
+	stage := tracker.Stage(StageConvert, 0, 100, 10, 1)
+	report := stage.Reporter(0)
+	report(ProgressReport{OutTime: 6})
+	report(ProgressReport{OutTime: 4})
+	report(ProgressReport{OutTime: 20})
+	assertFloat(t, last, 100, "final percent (clamped)")
+}
@@ -0,0 +1,213 @@
+package ffutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// minSpeedUpGap is the shortest silent gap that SpeedUpClips speeds up. Shorter gaps
+// would last only a frame or two after speeding up, so they play at normal speed.
+const minSpeedUpGap = 0.1
+
+// maxInlineFilterGraph is the longest filter graph passed directly on the command
+// line. Linux limits a single argument to 128 KiB; longer graphs go through a file.
+const maxInlineFilterGraph = 64 * 1024
+
+// Clip is a range of the source placed on the output timeline, played at Speed.
+type Clip struct {
+	StartTime float64
+	EndTime   float64
+	Speed     float64 // Playback speed; 0 is treated as 1 (normal speed)
+}
+
+func (c Clip) speed() float64 {
+	if c.Speed <= 0 {
+		return 1
+	}
+	return c.Speed
+}
+
+// OutputDuration returns how long the clip lasts in the output.
+func (c Clip) OutputDuration() float64 {
+	return (c.EndTime - c.StartTime) / c.speed()
+}
+
+// ClipsDuration returns the output duration of clips played back to back.
+func ClipsDuration(clips []Clip) float64 {
+	var total float64
+	for _, c := range clips {
+		total += c.OutputDuration()
+	}
+	return total
+}
+
+// SegmentClips returns one normal-speed clip per segment.
+func SegmentClips(segments []Segment) []Clip {
+	clips := make([]Clip, len(segments))
+	for i, seg := range segments {
+		clips[i] = Clip{StartTime: seg.StartTime, EndTime: seg.EndTime, Speed: 1}
+	}
+	return clips
+}
+
+// SpeedUpClips covers [0, totalDuration] with clips: segments play at normal speed
+// and the gaps between them at factor. Gaps shorter than minSpeedUpGap play at
+// normal speed as part of the neighbouring clip.
+func SpeedUpClips(segments []Segment, totalDuration, factor float64) []Clip {
+	var clips []Clip
+	add := func(start, end, speed float64) {
+		if end <= start {
+			return
+		}
+		if speed != 1 && end-start < minSpeedUpGap {
+			speed = 1
+		}
+		if n := len(clips); n > 0 && clips[n-1].Speed == speed {
+			clips[n-1].EndTime = end
+			return
+		}
+		clips = append(clips, Clip{StartTime: start, EndTime: end, Speed: speed})
+	}
+
+	pos := 0.0
+	for _, seg := range segments {
+		add(pos, seg.StartTime, factor)
+		add(seg.StartTime, seg.EndTime, 1)
+		pos = seg.EndTime
+	}
+	add(pos, totalDuration, factor)
+	return clips
+}
+
+// ConcatFilter builds a filter_complex graph that trims each clip out of input 0,
+// retimes it to its speed and concatenates the results. Video is included if
+// withVideo and audio if withAudio; the outputs are labelled [outv] and [outa].
+//
+// Where a clip does not continue exactly where the previous one ended, the audio
+// fades out and back in over fadeDuration so the cut doesn't click.
+func ConcatFilter(clips []Clip, withVideo, withAudio bool, fadeDuration float64) string {
+	audioTracks := 0
+	if withAudio {
+		audioTracks = 1
+	}
+	return ConcatFilterTracks(clips, withVideo, audioTracks, fadeDuration)
+}
+
+// ConcatFilterTracks is like ConcatFilter but includes the first audioTracks audio
+// streams of input 0, each cut the same way. Their outputs are labelled as given by
+// AudioOutputLabel.
+func ConcatFilterTracks(clips []Clip, withVideo bool, audioTracks int, fadeDuration float64) string {
+	var b strings.Builder
+	var pads strings.Builder
+
+	for i, c := range clips {
+		start, end := c.StartTime, c.EndTime
+		if withVideo {
+			fmt.Fprintf(&b, "[0:v]trim=start=%.6f:end=%.6f,setpts=%s[v%d];", start, end, setptsExpr(c.speed()), i)
+			fmt.Fprintf(&pads, "[v%d]", i)
+		}
+		for track := 0; track < audioTracks; track++ {
+			input, label := "0:a", fmt.Sprintf("a%d", i)
+			if audioTracks > 1 {
+				input = fmt.Sprintf("0:a:%d", track)
+			}
+			if track > 0 {
+				label = fmt.Sprintf("a%d_%d", track, i)
+			}
+			fmt.Fprintf(&b, "[%s]atrim=start=%.6f:end=%.6f,asetpts=PTS-STARTPTS", input, start, end)
+			fadeIn := i > 0 && clips[i-1].EndTime != start
+			fadeOut := i < len(clips)-1 && clips[i+1].StartTime != end
+			if f := edgeFadeFilter(end-start, fadeDuration, fadeIn, fadeOut); f != "" {
+				b.WriteString("," + f)
+			}
+			if f := AtempoFilter(c.speed()); f != "" {
+				b.WriteString("," + f)
+			}
+			fmt.Fprintf(&b, "[%s];", label)
+			fmt.Fprintf(&pads, "[%s]", label)
+		}
+	}
+
+	v := 0
+	var outputs string
+	if withVideo {
+		v = 1
+		outputs += "[outv]"
+	}
+	for track := 0; track < audioTracks; track++ {
+		outputs += AudioOutputLabel(track)
+	}
+	fmt.Fprintf(&b, "%sconcat=n=%d:v=%d:a=%d%s", pads.String(), len(clips), v, audioTracks, outputs)
+	return b.String()
+}
+
+// AudioOutputLabel returns the ConcatFilterTracks output label of an audio track:
+// [outa] for the first, then [outa1], [outa2], ...
+func AudioOutputLabel(track int) string {
+	if track == 0 {
+		return "[outa]"
+	}
+	return fmt.Sprintf("[outa%d]", track)
+}
+
+// setptsExpr returns the setpts expression that restarts a clip at zero and plays
+// it at speed.
+func setptsExpr(speed float64) string {
+	if speed == 1 {
+		return "PTS-STARTPTS"
+	}
+	return fmt.Sprintf("(PTS-STARTPTS)/%g", speed)
+}
+
+// edgeFadeFilter is like AudioFadeFilter but only fades the requested edges.
+func edgeFadeFilter(segmentDuration, fadeDuration float64, in, out bool) string {
+	if fadeDuration <= 0 {
+		fadeDuration = DefaultFadeDurationSec
+	}
+	var filters []string
+	if in {
+		filters = append(filters, fmt.Sprintf("afade=t=in:d=%.3f", fadeDuration))
+	}
+	if out {
+		filters = append(filters, fmt.Sprintf("afade=t=out:st=%.3f:d=%.3f", max(segmentDuration-fadeDuration, 0), fadeDuration))
+	}
+	return strings.Join(filters, ",")
+}
+
+// AtempoFilter returns an atempo chain that changes audio speed by speed without
+// changing pitch, or "" for normal speed. Older ffmpeg builds only accept factors
+// between 0.5 and 2 per atempo instance, so larger changes are chained.
+func AtempoFilter(speed float64) string {
+	if speed <= 0 || speed == 1 {
+		return ""
+	}
+	var filters []string
+	for speed > 2 {
+		filters = append(filters, "atempo=2.0")
+		speed /= 2
+	}
+	for speed < 0.5 {
+		filters = append(filters, "atempo=0.5")
+		speed *= 2
+	}
+	if speed != 1 {
+		filters = append(filters, fmt.Sprintf("atempo=%g", speed))
+	}
+	return strings.Join(filters, ",")
+}
+
+// FilterComplexArgs returns the ffmpeg arguments that pass graph as the filter
+// graph. Graphs too long for one command-line argument are written to a file in dir
+// and passed with -filter_complex_script.
+func FilterComplexArgs(graph, dir string) ([]string, error) {
+	if len(graph) <= maxInlineFilterGraph {
+		return []string{"-filter_complex", graph}, nil
+	}
+	path := filepath.Join(dir, "filter_complex.txt")
+	if err := os.WriteFile(path, []byte(graph), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write filter script: %w", err)
+	}
+	return []string{"-filter_complex_script", path}, nil
+}
@@ -0,0 +1,140 @@
+package ffutil
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpeedUpClips_CoversWholeFile(t *testing.T) {
+	segments := []Segment{
+		{StartTime: 1.0, EndTime: 3.0, Duration: 2.0},
+		{StartTime: 5.0, EndTime: 6.0, Duration: 1.0},
+	}
+	clips := SpeedUpClips(segments, 10.0, 4)
+
+	want := []Clip{
+		{StartTime: 0, EndTime: 1, Speed: 4},
+		{StartTime: 1, EndTime: 3, Speed: 1},
+		{StartTime: 3, EndTime: 5, Speed: 4},
+		{StartTime: 5, EndTime: 6, Speed: 1},
+		{StartTime: 6, EndTime: 10, Speed: 4},
+	}
+	if len(clips) != len(want) {
+		t.Fatalf("got %d clips, want %d: %+v", len(clips), len(want), clips)
+	}
+	for i := range want {
+		if clips[i] != want[i] {
+			t.Errorf("clip %d = %+v, want %+v", i, clips[i], want[i])
+		}
+	}
+	assertFloat(t, ClipsDuration(clips), 3+(1+2+4)/4.0, "ClipsDuration")
+}
+
+func TestSpeedUpClips_ShortGapPlaysAtNormalSpeed(t *testing.T) {
+	segments := []Segment{
+		{StartTime: 0.0, EndTime: 2.0, Duration: 2.0},
+		{StartTime: 2.05, EndTime: 4.0, Duration: 1.95},
+	}
+	clips := SpeedUpClips(segments, 4.0, 4)
+
+	if len(clips) != 1 {
+		t.Fatalf("expected the short gap to join its neighbours, got %+v", clips)
+	}
+	if clips[0] != (Clip{StartTime: 0, EndTime: 4, Speed: 1}) {
+		t.Errorf("clip = %+v, want 0-4 at normal speed", clips[0])
+	}
+}
+
+func TestConcatFilter_VideoAndAudio(t *testing.T) {
+	clips := []Clip{
+		{StartTime: 0, EndTime: 1, Speed: 1},
+		{StartTime: 1, EndTime: 3, Speed: 4},
+	}
+	got := ConcatFilter(clips, true, true, 0.03)
+	want := "[0:v]trim=start=0.000000:end=1.000000,setpts=PTS-STARTPTS[v0];" +
+		"[0:a]atrim=start=0.000000:end=1.000000,asetpts=PTS-STARTPTS[a0];" +
+		"[0:v]trim=start=1.000000:end=3.000000,setpts=(PTS-STARTPTS)/4[v1];" +
+		"[0:a]atrim=start=1.000000:end=3.000000,asetpts=PTS-STARTPTS,atempo=2.0,atempo=2[a1];" +
+		"[v0][a0][v1][a1]concat=n=2:v=1:a=1[outv][outa]"
+	if got != want {
+		t.Errorf("graph =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConcatFilter_FadesOnlyAtCuts(t *testing.T) {
+	clips := []Clip{
+		{StartTime: 0, EndTime: 1},
+		{StartTime: 2, EndTime: 3},
+	}
+	got := ConcatFilter(clips, false, true, 0.03)
+	want := "[0:a]atrim=start=0.000000:end=1.000000,asetpts=PTS-STARTPTS,afade=t=out:st=0.970:d=0.030[a0];" +
+		"[0:a]atrim=start=2.000000:end=3.000000,asetpts=PTS-STARTPTS,afade=t=in:d=0.030[a1];" +
+		"[a0][a1]concat=n=2:v=0:a=1[outa]"
+	if got != want {
+		t.Errorf("graph =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestConcatFilterTracks_SeveralAudioTracks(t *testing.T) {
+	clips := []Clip{
+		{StartTime: 0, EndTime: 1},
+		{StartTime: 1, EndTime: 2},
+	}
+	got := ConcatFilterTracks(clips, true, 2, 0.03)
+	want := "[0:v]trim=start=0.000000:end=1.000000,setpts=PTS-STARTPTS[v0];" +
+		"[0:a:0]atrim=start=0.000000:end=1.000000,asetpts=PTS-STARTPTS[a0];" +
+		"[0:a:1]atrim=start=0.000000:end=1.000000,asetpts=PTS-STARTPTS[a1_0];" +
+		"[0:v]trim=start=1.000000:end=2.000000,setpts=PTS-STARTPTS[v1];" +
+		"[0:a:0]atrim=start=1.000000:end=2.000000,asetpts=PTS-STARTPTS[a1];" +
+		"[0:a:1]atrim=start=1.000000:end=2.000000,asetpts=PTS-STARTPTS[a1_1];" +
+		"[v0][a0][a1_0][v1][a1][a1_1]concat=n=2:v=1:a=2[outv][outa][outa1]"
+	if got != want {
+		t.Errorf("graph =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestAtempoFilter(t *testing.T) {
+	tests := map[float64]string{
+		1:    "",
+		0:    "",
+		1.5:  "atempo=1.5",
+		2:    "atempo=2",
+		3:    "atempo=2.0,atempo=1.5",
+		8:    "atempo=2.0,atempo=2.0,atempo=2",
+		0.25: "atempo=0.5,atempo=0.5",
+	}
+	for speed, want := range tests {
+		if got := AtempoFilter(speed); got != want {
+			t.Errorf("AtempoFilter(%g) = %q, want %q", speed, got, want)
+		}
+	}
+}
+
+func TestFilterComplexArgs_LongGraphUsesScript(t *testing.T) {
+	dir := t.TempDir()
+
+	args, err := FilterComplexArgs("anull", dir)
+	if err != nil {
+		t.Fatalf("FilterComplexArgs: %v", err)
+	}
+	if strings.Join(args, " ") != "-filter_complex anull" {
+		t.Errorf("short graph args = %v", args)
+	}
+
+	long := strings.Repeat("anull,", maxInlineFilterGraph/6+1) + "anull"
+	args, err = FilterComplexArgs(long, dir)
+	if err != nil {
+		t.Fatalf("FilterComplexArgs: %v", err)
+	}
+	if len(args) != 2 || args[0] != "-filter_complex_script" {
+		t.Fatalf("long graph args = %v, want -filter_complex_script <file>", args)
+	}
+	data, err := os.ReadFile(args[1])
+	if err != nil {
+		t.Fatalf("failed to read filter script: %v", err)
+	}
+	if string(data) != long {
+		t.Error("filter script content differs from the graph")
+	}
+}
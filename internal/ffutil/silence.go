@@ -0,0 +1,139 @@
+package ffutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AudioStreamAll selects every audio stream for silence detection.
+const AudioStreamAll = "all"
+
+// SilenceDetectOptions controls a silencedetect run.
+type SilenceDetectOptions struct {
+	Threshold   int     // Noise threshold in dB
+	MinDuration float64 // Shortest silence reported, in seconds
+	Streams     []int   // Positions among the audio streams (0:a:N); nil uses ffmpeg's default stream
+	Channel     int     // 1-based channel to listen to; 0 mixes all channels
+}
+
+// SelectAudioStreams resolves an audio stream selector against the audio streams of
+// a file and returns their positions among the audio streams. The selector is ""
+// for ffmpeg's default stream (nil is returned), AudioStreamAll, or a comma-separated
+// list of positions ("0", "1") and language tags ("eng"); a language selects the
+// first stream tagged with it. channel, if not 0, must exist on every selected stream.
+func SelectAudioStreams(audio []Stream, selector string, channel int) ([]int, error) {
+	if channel < 0 {
+		return nil, fmt.Errorf("invalid channel %d: channels are numbered from 1", channel)
+	}
+
+	var selected []int
+	switch selector = strings.TrimSpace(selector); selector {
+	case "":
+		if len(audio) > 0 && channel > 0 && audio[0].Channels > 0 && channel > audio[0].Channels {
+			return nil, fmt.Errorf("channel %d not found: audio stream has %d channels", channel, audio[0].Channels)
+		}
+		return nil, nil
+	case AudioStreamAll:
+		if len(audio) == 0 {
+			return nil, fmt.Errorf("no audio streams found")
+		}
+		for i := range audio {
+			selected = append(selected, i)
+		}
+	default:
+		for _, item := range strings.Split(selector, ",") {
+			i, err := findAudioStream(audio, strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			if !containsInt(selected, i) {
+				selected = append(selected, i)
+			}
+		}
+	}
+
+	for _, i := range selected {
+		if channel > 0 && audio[i].Channels > 0 && channel > audio[i].Channels {
+			return nil, fmt.Errorf("channel %d not found: audio stream %d has %d channels", channel, i, audio[i].Channels)
+		}
+	}
+	return selected, nil
+}
+
+// findAudioStream returns the position of the audio stream matching item, a position
+// or a language tag.
+func findAudioStream(audio []Stream, item string) (int, error) {
+	if n, err := strconv.Atoi(item); err == nil {
+		if n < 0 || n >= len(audio) {
+			return 0, fmt.Errorf("audio stream %d not found: file has %d audio streams", n, len(audio))
+		}
+		return n, nil
+	}
+	for i, s := range audio {
+		if strings.EqualFold(s.Language, item) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("no audio stream with language %q", item)
+}
+
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// SilenceDetectArgs returns the ffmpeg arguments that run silencedetect on path.
+// stream is a position among the audio streams, or -1 for ffmpeg's default stream.
+func SilenceDetectArgs(path string, stream int, opts SilenceDetectOptions) []string {
+	args := []string{"-i", path}
+	if stream >= 0 {
+		args = append(args, "-map", fmt.Sprintf("0:a:%d", stream))
+	}
+
+	filter := fmt.Sprintf("silencedetect=noise=%ddB:d=%.3f", opts.Threshold, opts.MinDuration)
+	if opts.Channel > 0 {
+		filter = fmt.Sprintf("pan=mono|c0=c%d,", opts.Channel-1) + filter
+	}
+	return append(args, "-af", filter, "-f", "null", "-")
+}
+
+// DetectNonSilent runs silencedetect on path once per selected stream and returns
+// the parts of [0, totalDuration] where at least one of them is not silent, so a
+// moment counts as silent only when every selected stream is. Progress is reported
+// through stage, with one job per stream.
+func DetectNonSilent(ctx context.Context, r Runner, ffmpeg, path string, totalDuration float64, opts SilenceDetectOptions, stage *StageProgress) ([]Segment, error) {
+	streams := opts.Streams
+	if len(streams) == 0 {
+		streams = []int{-1}
+	}
+
+	var all []Segment
+	for job, stream := range streams {
+		output, err := RunWithProgress(ctx, r, ffmpeg, SilenceDetectArgs(path, stream, opts), stage.Reporter(job))
+		outputStr := string(output)
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("silence detection interrupted: %w", err)
+		}
+		if err != nil {
+			// silencedetect always exits non-zero with -f null; only fail if no silence data was produced
+			if !strings.Contains(outputStr, "silence_start") && !strings.Contains(outputStr, "silence_end") {
+				return nil, fmt.Errorf("failed to detect silence: %w", err)
+			}
+		}
+
+		starts, ends := ParseSilenceOutput(outputStr)
+		all = append(all, BuildNonSilentSegments(starts, ends, totalDuration, 0)...)
+	}
+	stage.Done()
+
+	if len(streams) == 1 {
+		return all, nil
+	}
+	return NormalizeSegments(all, totalDuration), nil
+}
@@ -0,0 +1,130 @@
+package ffutil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var sampleAudioStreams = []Stream{
+	{Index: 1, CodecType: StreamTypeAudio, Channels: 2},
+	{Index: 2, CodecType: StreamTypeAudio, Channels: 1, Language: "eng"},
+	{Index: 3, CodecType: StreamTypeAudio, Channels: 1, Language: "por"},
+}
+
+func TestSelectAudioStreams(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		selector string
+		channel  int
+		want     []int
+	}{
+		{"", 0, nil},
+		{"", 2, nil},
+		{"1", 0, []int{1}},
+		{"ENG", 0, []int{1}},
+		{"por, 0", 1, []int{2, 0}},
+		{"0,0", 0, []int{0}},
+		{AudioStreamAll, 0, []int{0, 1, 2}},
+	}
+	for _, tt := range tests {
+		got, err := SelectAudioStreams(sampleAudioStreams, tt.selector, tt.channel)
+		if err != nil {
+			t.Errorf("SelectAudioStreams(%q, %d): %v", tt.selector, tt.channel, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SelectAudioStreams(%q, %d) = %v, want %v", tt.selector, tt.channel, got, tt.want)
+		}
+	}
+}
+
+func TestSelectAudioStreams_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		selector string
+		channel  int
+	}{
+		{"3", 0},            // out of range
+		{"-1", 0},           // negative position
+		{"fra", 0},          // no such language
+		{"", 3},             // default stream is stereo
+		{AudioStreamAll, 2}, // the mono tracks have no second channel
+		{"0", -1},           // negative channel
+	}
+	for _, tt := range tests {
+		if _, err := SelectAudioStreams(sampleAudioStreams, tt.selector, tt.channel); err == nil {
+			t.Errorf("SelectAudioStreams(%q, %d): expected an error", tt.selector, tt.channel)
+		}
+	}
+	if _, err := SelectAudioStreams(nil, AudioStreamAll, 0); err == nil {
+		t.Error("expected an error selecting all streams of a file without audio")
+	}
+}
+
+func TestSilenceDetectArgs(t *testing.T) {
+	t.Parallel()
+
+	opts := SilenceDetectOptions{Threshold: -30, MinDuration: 0.5}
+	got := strings.Join(SilenceDetectArgs("in.mp4", -1, opts), " ")
+	want := "-i in.mp4 -af silencedetect=noise=-30dB:d=0.500 -f null -"
+	if got != want {
+		t.Errorf("default stream: got %q, want %q", got, want)
+	}
+
+	opts.Channel = 2
+	got = strings.Join(SilenceDetectArgs("in.mp4", 1, opts), " ")
+	want = "-i in.mp4 -map 0:a:1 -af pan=mono|c0=c1,silencedetect=noise=-30dB:d=0.500 -f null -"
+	if got != want {
+		t.Errorf("stream 1, channel 2: got %q, want %q", got, want)
+	}
+}
+
+// scriptedRunner answers each call with the stderr of the stream it maps.
+// scriptedRunner writes the scripted stderr of the stream each run maps, and fails
+// runs that map no scripted stream.
+type scriptedRunner struct {
+	stderr map[string]string // keyed by the -map argument
+	calls  [][]string
+}
+
+func (r *scriptedRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	r.calls = append(r.calls, args)
+	i := slices.Index(args, "-map")
+	if i < 0 || i+1 >= len(args) {
+		return fmt.Errorf("unexpected call without -map: %v", args)
+	}
+	out, ok := r.stderr[args[i+1]]
+	if !ok {
+		return fmt.Errorf("unexpected call for stream %s: %v", args[i+1], args)
+	}
+	io.WriteString(stderr, out)
+	return nil
+}
+
+func TestDetectNonSilent_CombinesStreams(t *testing.T) {
+	t.Parallel()
+
+	// Stream 0 is silent from 2s to 5s, stream 1 from 4s to 8s: only 4-5s is silent in both
+	r := &scriptedRunner{stderr: map[string]string{
+		"0:a:0": "[silencedetect @ 0x1] silence_start: 2\n[silencedetect @ 0x1] silence_end: 5 | silence_duration: 3\n",
+		"0:a:1": "[silencedetect @ 0x2] silence_start: 4\n[silencedetect @ 0x2] silence_end: 8 | silence_duration: 4\n",
+	}}
+	opts := SilenceDetectOptions{Threshold: -30, MinDuration: 0.5, Streams: []int{0, 1}}
+	segments, err := DetectNonSilent(context.Background(), r, "ffmpeg", "in.mp4", 10, opts, nil)
+	if err != nil {
+		t.Fatalf("DetectNonSilent: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Fatalf("got %d segments, want 2: %+v", len(segments), segments)
+	}
+	assertFloat(t, segments[0].StartTime, 0, "segments[0].StartTime")
+	assertFloat(t, segments[0].EndTime, 4, "segments[0].EndTime")
+	assertFloat(t, segments[1].StartTime, 5, "segments[1].StartTime")
+	assertFloat(t, segments[1].EndTime, 10, "segments[1].EndTime")
+}
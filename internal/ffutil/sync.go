@@ -0,0 +1,208 @@
+package ffutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Sync offset detection settings. Both recordings are decoded to syncSampleRate mono
+// PCM, high-passed at syncLowCut to drop hum and handling noise.
+const (
+	syncSampleRate = 8000
+	syncLowCut     = 100 // Hz
+
+	// syncPeakWidth is how far, in seconds, a correlation peak spreads. The runner-up
+	// used for the confidence is the highest value outside this distance of the peak.
+	syncPeakWidth = 0.1
+
+	defaultSyncDuration  = 60
+	defaultSyncMaxOffset = 30
+)
+
+// SyncOptions controls EstimateOffset. Zero values select the defaults.
+type SyncOptions struct {
+	// Start is where the compared window begins in the base recording, in seconds.
+	Start float64
+	// Duration is the length of the compared window in seconds (default 60).
+	Duration float64
+	// MaxOffset is the largest offset searched for, either way, in seconds (default 30).
+	MaxOffset float64
+}
+
+// SyncEstimate is the offset found between two recordings.
+type SyncEstimate struct {
+	// Offset is where the other recording starts on the base recording's timeline,
+	// in seconds: sound at t in the other is at t+Offset in the base. It is negative
+	// when the other recording started first.
+	Offset float64
+	// Confidence tells how clearly the offset stands out, from 0 (any other offset
+	// matches as well) to 1 (nothing else matches at all). Below about 0.25 the
+	// recordings probably don't share the same sound.
+	Confidence float64
+}
+
+// SyncDecodeArgs returns the ffmpeg arguments that decode duration seconds of the
+// first audio stream of path, from start, to high-passed 8 kHz mono PCM on stdout.
+func SyncDecodeArgs(path string, start, duration float64) []string {
+	var args []string
+	if start > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%.3f", start))
+	}
+	return append(args,
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-i", path,
+		"-map", "0:a:0",
+		"-af", fmt.Sprintf("highpass=f=%d,aresample=%d", syncLowCut, syncSampleRate),
+		"-ac", "1", "-c:a", "pcm_s16le", "-f", "s16le", "-",
+	)
+}
+
+// EstimateOffset finds where the audio of other starts on the timeline of base by
+// cross-correlating their waveforms. A window of base is compared against the part of
+// other it could match within opts.MaxOffset.
+func EstimateOffset(ctx context.Context, r Runner, ffmpeg, base, other string, opts SyncOptions) (SyncEstimate, error) {
+	duration := opts.Duration
+	if duration <= 0 {
+		duration = defaultSyncDuration
+	}
+	maxOffset := opts.MaxOffset
+	if maxOffset <= 0 {
+		maxOffset = defaultSyncMaxOffset
+	}
+	start := max(opts.Start, 0)
+
+	a, err := decodeSyncPCM(ctx, r, ffmpeg, base, start, duration)
+	if err != nil {
+		return SyncEstimate{}, err
+	}
+	otherStart := max(start-maxOffset, 0)
+	b, err := decodeSyncPCM(ctx, r, ffmpeg, other, otherStart, duration+2*maxOffset)
+	if err != nil {
+		return SyncEstimate{}, err
+	}
+
+	// a[i] lines up with b[i+lag] when Offset = start - otherStart - lag/rate
+	minLag := int(math.Ceil((start - otherStart - maxOffset) * syncSampleRate))
+	maxLag := int(math.Floor((start - otherStart + maxOffset) * syncSampleRate))
+	lag, confidence, err := CrossCorrelate(a, b, minLag, maxLag)
+	if err != nil {
+		return SyncEstimate{}, err
+	}
+	return SyncEstimate{
+		Offset:     start - otherStart - lag/syncSampleRate,
+		Confidence: confidence,
+	}, nil
+}
+
+// decodeSyncPCM decodes a window of path with SyncDecodeArgs.
+func decodeSyncPCM(ctx context.Context, r Runner, ffmpeg, path string, start, duration float64) ([]float64, error) {
+	args := SyncDecodeArgs(path, start, duration)
+	var stdout, stderr bytes.Buffer
+	err := r.Run(ctx, ffmpeg, args, nil, &stdout, &stderr)
+	err = commandError(ctx, ffmpeg, args, stderr.Bytes(), err)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("sync detection interrupted: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode audio of %s: %w", path, err)
+	}
+
+	data := stdout.Bytes()
+	samples := make([]float64, len(data)/2)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(data[2*i:]))) / 32768
+	}
+	return samples, nil
+}
+
+// CrossCorrelate finds the lag, between minLag and maxLag samples, at which b best
+// matches a, meaning a[i] lines up with b[i+lag]. The lag is refined to a fraction of
+// a sample. Inverted polarity counts as a match, since a mic may be wired either way.
+// The confidence compares the peak with the best match more than syncPeakWidth away.
+func CrossCorrelate(a, b []float64, minLag, maxLag int) (lag, confidence float64, err error) {
+	a, b = withoutDC(a), withoutDC(b)
+	if isSilent(a) || isSilent(b) {
+		return 0, 0, fmt.Errorf("cannot estimate the offset: no audio to compare")
+	}
+	minLag = max(minLag, -len(a)+1)
+	maxLag = min(maxLag, len(b)-1)
+	if minLag > maxLag {
+		return 0, 0, fmt.Errorf("cannot estimate the offset: recordings don't overlap")
+	}
+
+	// corr[k mod n] = sum a[i]*b[i+k], from the spectra with zero padding so that
+	// negative and positive lags don't wrap into each other
+	n := NextPowerOfTwo(len(a) + len(b))
+	fa, fb := make([]complex128, n), make([]complex128, n)
+	for i, s := range a {
+		fa[i] = complex(s, 0)
+	}
+	for i, s := range b {
+		fb[i] = complex(s, 0)
+	}
+	FFT(fa)
+	FFT(fb)
+	for i := range fa {
+		fa[i] = complex(real(fa[i]), -imag(fa[i])) * fb[i]
+	}
+	InverseFFT(fa)
+	at := func(k int) float64 {
+		return math.Abs(real(fa[(k%n+n)%n]))
+	}
+
+	best := minLag
+	for k := minLag; k <= maxLag; k++ {
+		if at(k) > at(best) {
+			best = k
+		}
+	}
+	peak := at(best)
+
+	width := int(syncPeakWidth * syncSampleRate)
+	var runnerUp float64
+	for k := minLag; k <= maxLag; k++ {
+		if k < best-width || k > best+width {
+			runnerUp = max(runnerUp, at(k))
+		}
+	}
+
+	lag = float64(best)
+	if best > minLag && best < maxLag {
+		// Parabola through the peak and its neighbours
+		left, right := at(best-1), at(best+1)
+		if d := left - 2*peak + right; d < 0 {
+			lag += 0.5 * (left - right) / d
+		}
+	}
+	return lag, 1 - runnerUp/peak, nil
+}
+
+// withoutDC returns samples with their mean removed.
+func withoutDC(samples []float64) []float64 {
+	if len(samples) == 0 {
+		return samples
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += s
+	}
+	mean := sum / float64(len(samples))
+	out := make([]float64, len(samples))
+	for i, s := range samples {
+		out[i] = s - mean
+	}
+	return out
+}
+
+// isSilent reports whether samples are all (nearly) zero.
+func isSilent(samples []float64) bool {
+	for _, s := range samples {
+		if math.Abs(s) > 1e-4 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package ffutil
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSyncDecodeArgs(t *testing.T) {
+	got := strings.Join(SyncDecodeArgs("cam.mp4", 12.5, 60), " ")
+	want := "-ss 12.500 -t 60.000 -i cam.mp4 -map 0:a:0 -af highpass=f=100,aresample=8000 " +
+		"-ac 1 -c:a pcm_s16le -f s16le -"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if got := SyncDecodeArgs("lav.wav", 0, 10); got[0] != "-t" {
+		t.Errorf("args = %v, want no seek from the start", got)
+	}
+}
+
+// noise returns seconds of uniform noise at syncSampleRate.
+func noise(seconds float64, rng *rand.Rand) []float64 {
+	out := make([]float64, int(seconds*syncSampleRate))
+	for i := range out {
+		out[i] = 0.5 * (2*rng.Float64() - 1)
+	}
+	return out
+}
+
+func TestCrossCorrelate(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	b := noise(2, rng)
+
+	for _, lag := range []int{0, 1234, 4000} {
+		a := b[lag:]
+		got, confidence, err := CrossCorrelate(a, b, -8000, 8000)
+		if err != nil {
+			t.Fatalf("lag %d: %v", lag, err)
+		}
+		assertFloat(t, got, float64(lag), "lag")
+		if confidence < 0.5 {
+			t.Errorf("lag %d: confidence = %.2f, want at least 0.5", lag, confidence)
+		}
+	}
+
+	// Inverted polarity, and b starting after a
+	a := make([]float64, 500+len(b))
+	for i, s := range b {
+		a[500+i] = -s
+	}
+	got, _, err := CrossCorrelate(a, b, -8000, 8000)
+	if err != nil {
+		t.Fatalf("CrossCorrelate: %v", err)
+	}
+	assertFloat(t, got, -500, "lag")
+}
+
+func TestCrossCorrelate_Unrelated(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	_, confidence, err := CrossCorrelate(noise(2, rng), noise(4, rng), -8000, 8000)
+	if err != nil {
+		t.Fatalf("CrossCorrelate: %v", err)
+	}
+	if confidence > 0.25 {
+		t.Errorf("confidence = %.2f for unrelated recordings, want at most 0.25", confidence)
+	}
+
+	if _, _, err := CrossCorrelate(make([]float64, 100), noise(1, rng), -10, 10); err == nil {
+		t.Error("expected an error for a silent recording")
+	}
+}
+
+// syncRunner decodes the -ss/-t window of in-memory recordings at syncSampleRate.
+type syncRunner struct {
+	files map[string][]float64
+}
+
+func (r *syncRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var path string
+	var start, duration float64
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case "-ss":
+			start, _ = strconv.ParseFloat(args[i+1], 64)
+		case "-t":
+			duration, _ = strconv.ParseFloat(args[i+1], 64)
+		case "-i":
+			path = args[i+1]
+		}
+	}
+	samples := r.files[path]
+	from := min(int(start*syncSampleRate), len(samples))
+	to := min(from+int(duration*syncSampleRate), len(samples))
+	io.WriteString(stdout, pcm(samples[from:to]))
+	return nil
+}
+
+func TestEstimateOffset(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	source := noise(30, rng)
+	silence := func(seconds float64) []float64 { return make([]float64, int(seconds*syncSampleRate)) }
+
+	tests := []struct {
+		name        string
+		base, other []float64
+		opts        SyncOptions
+		want        float64
+	}{
+		{"other starts later", append(silence(2.5), source...), source, SyncOptions{Duration: 10, MaxOffset: 5}, 2.5},
+		{"other starts first", source, append(silence(1.25), source...), SyncOptions{Duration: 10, MaxOffset: 5}, -1.25},
+		{"window later in the base", append(silence(4), source...), source, SyncOptions{Start: 10, Duration: 5, MaxOffset: 5}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &syncRunner{files: map[string][]float64{"base.mp4": tt.base, "other.wav": tt.other}}
+			got, err := EstimateOffset(context.Background(), r, "ffmpeg", "base.mp4", "other.wav", tt.opts)
+			if err != nil {
+				t.Fatalf("EstimateOffset: %v", err)
+			}
+			if d := got.Offset - tt.want; d < -0.001 || d > 0.001 {
+				t.Errorf("Offset = %.4f, want %.4f", got.Offset, tt.want)
+			}
+			if got.Confidence < 0.5 {
+				t.Errorf("Confidence = %.2f, want at least 0.5", got.Confidence)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package ffutil
+
+import "sort"
+
+// Timeline records how an edit was made from its source: which source ranges were
+// kept, where each one lands in the output and how fast it plays. It maps times in
+// both directions and marshals to JSON, so it can be stored next to the output.
+type Timeline struct {
+	SourceDuration float64           `json:"source_duration"`
+	Segments       []TimelineSegment `json:"segments"`
+}
+
+// TimelineSegment is one kept source range and its place in the output.
+type TimelineSegment struct {
+	SourceStart float64 `json:"source_start"`
+	SourceEnd   float64 `json:"source_end"`
+	OutputStart float64 `json:"output_start"`
+	Speed       float64 `json:"speed,omitempty"` // Playback speed; 0 is treated as 1
+}
+
+func (s TimelineSegment) speed() float64 {
+	if s.Speed <= 0 {
+		return 1
+	}
+	return s.Speed
+}
+
+// OutputEnd returns where the segment ends in the output.
+func (s TimelineSegment) OutputEnd() float64 {
+	return s.OutputStart + (s.SourceEnd-s.SourceStart)/s.speed()
+}
+
+// NewTimeline builds the timeline of an edit that plays clips back to back.
+// clips must be sorted and not overlap.
+func NewTimeline(clips []Clip, sourceDuration float64) *Timeline {
+	t := &Timeline{SourceDuration: sourceDuration, Segments: []TimelineSegment{}}
+	var offset float64
+	for _, c := range clips {
+		seg := TimelineSegment{SourceStart: c.StartTime, SourceEnd: c.EndTime, OutputStart: offset}
+		if c.speed() != 1 {
+			seg.Speed = c.speed()
+		}
+		t.Segments = append(t.Segments, seg)
+		offset += c.OutputDuration()
+	}
+	return t
+}
+
+// OutputDuration returns the length of the edited output.
+func (t *Timeline) OutputDuration() float64 {
+	if len(t.Segments) == 0 {
+		return 0
+	}
+	return t.Segments[len(t.Segments)-1].OutputEnd()
+}
+
+// KeptSegments returns the source ranges that are in the output.
+func (t *Timeline) KeptSegments() []Segment {
+	segments := make([]Segment, len(t.Segments))
+	for i, s := range t.Segments {
+		segments[i] = Segment{StartTime: s.SourceStart, EndTime: s.SourceEnd, Duration: s.SourceEnd - s.SourceStart}
+	}
+	return segments
+}
+
+// RemovedSegments returns the source ranges that were cut out.
+func (t *Timeline) RemovedSegments() []Segment {
+	return InvertSegments(t.KeptSegments(), t.SourceDuration)
+}
+
+// SourceToOutput maps a time in the source to the output. If src falls inside a
+// removed range (or outside the source), kept is false and out is where the cut
+// happened in the output: the start of the next kept segment, or the end of the
+// output when nothing after src was kept.
+func (t *Timeline) SourceToOutput(src float64) (out float64, kept bool) {
+	i := sort.Search(len(t.Segments), func(i int) bool {
+		return t.Segments[i].SourceEnd >= src
+	})
+	if i == len(t.Segments) {
+		return t.OutputDuration(), false
+	}
+	seg := t.Segments[i]
+	if src < seg.SourceStart {
+		return seg.OutputStart, false
+	}
+	return seg.OutputStart + (src-seg.SourceStart)/seg.speed(), true
+}
+
+// OutputToSource maps a time in the output back to the source. A time exactly on a
+// cut maps to the start of the segment that follows it. Times outside the output
+// are clamped to its ends and ok is false.
+func (t *Timeline) OutputToSource(out float64) (src float64, ok bool) {
+	n := len(t.Segments)
+	if n == 0 {
+		return 0, false
+	}
+	if out < 0 {
+		return t.Segments[0].SourceStart, false
+	}
+	i := sort.Search(n, func(i int) bool {
+		return t.Segments[i].OutputEnd() > out
+	})
+	if i == n {
+		last := t.Segments[n-1]
+		return last.SourceEnd, out == last.OutputEnd()
+	}
+	seg := t.Segments[i]
+	return seg.SourceStart + (out-seg.OutputStart)*seg.speed(), true
+}
@@ -0,0 +1,126 @@
+package ffutil
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Keeps 1-3 and 5-8 of a 10s source
+func sampleTimeline() *Timeline {
+	return NewTimeline(SegmentClips([]Segment{
+		{StartTime: 1, EndTime: 3},
+		{StartTime: 5, EndTime: 8},
+	}), 10)
+}
+
+func TestTimeline_SourceToOutput(t *testing.T) {
+	t.Parallel()
+
+	tl := sampleTimeline()
+	tests := []struct {
+		src      float64
+		wantOut  float64
+		wantKept bool
+	}{
+		{0.5, 0, false}, // removed lead-in maps to the first cut
+		{1, 0, true},
+		{2.5, 1.5, true},
+		{3, 2, true},  // a segment end is the next segment's start in the output
+		{4, 2, false}, // removed: lands on the cut at 2s
+		{6, 3, true},
+		{8, 5, true},   // end of the last segment
+		{9, 5, false},  // removed tail maps to the end
+		{12, 5, false}, // past the source
+	}
+	for _, tt := range tests {
+		out, kept := tl.SourceToOutput(tt.src)
+		if kept != tt.wantKept {
+			t.Errorf("SourceToOutput(%.1f) kept = %v, want %v", tt.src, kept, tt.wantKept)
+		}
+		assertFloat(t, out, tt.wantOut, "SourceToOutput")
+	}
+}
+
+func TestTimeline_OutputToSource(t *testing.T) {
+	t.Parallel()
+
+	tl := sampleTimeline()
+	tests := []struct {
+		out     float64
+		wantSrc float64
+		wantOK  bool
+	}{
+		{0, 1, true},
+		{1.5, 2.5, true},
+		{2, 5, true}, // on the cut: the following segment
+		{4, 7, true},
+		{5, 8, true},
+		{6, 8, false},
+		{-1, 1, false},
+	}
+	for _, tt := range tests {
+		src, ok := tl.OutputToSource(tt.out)
+		if ok != tt.wantOK {
+			t.Errorf("OutputToSource(%.1f) ok = %v, want %v", tt.out, ok, tt.wantOK)
+		}
+		assertFloat(t, src, tt.wantSrc, "OutputToSource")
+	}
+}
+
+func TestTimeline_SpeedUp(t *testing.T) {
+	t.Parallel()
+
+	tl := NewTimeline([]Clip{
+		{StartTime: 0, EndTime: 2, Speed: 1},
+		{StartTime: 2, EndTime: 6, Speed: 4},
+		{StartTime: 6, EndTime: 8, Speed: 1},
+	}, 8)
+	assertFloat(t, tl.OutputDuration(), 5, "OutputDuration")
+
+	out, kept := tl.SourceToOutput(4)
+	if !kept {
+		t.Error("sped-up time reported as removed")
+	}
+	assertFloat(t, out, 2.5, "SourceToOutput(4)")
+
+	src, _ := tl.OutputToSource(2.5)
+	assertFloat(t, src, 4, "OutputToSource(2.5)")
+}
+
+func TestTimeline_Segments(t *testing.T) {
+	t.Parallel()
+
+	tl := sampleTimeline()
+	assertFloat(t, tl.OutputDuration(), 5, "OutputDuration")
+
+	removed := tl.RemovedSegments()
+	if len(removed) != 3 {
+		t.Fatalf("got %d removed segments, want 3: %+v", len(removed), removed)
+	}
+	assertFloat(t, removed[1].StartTime, 3, "removed[1].StartTime")
+	assertFloat(t, removed[1].EndTime, 5, "removed[1].EndTime")
+}
+
+func TestTimeline_JSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(sampleTimeline())
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	want := `{"source_duration":10,"segments":[` +
+		`{"source_start":1,"source_end":3,"output_start":0},` +
+		`{"source_start":5,"source_end":8,"output_start":2}]}`
+	if string(data) != want {
+		t.Errorf("JSON =\n%s\nwant\n%s", data, want)
+	}
+
+	var decoded Timeline
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	out, kept := decoded.SourceToOutput(6)
+	if !kept || out != 3 {
+		t.Errorf("decoded SourceToOutput(6) = %.1f, %v; want 3, true", out, kept)
+	}
+}
@@ -0,0 +1,348 @@
+package ffutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Voice activity detection settings. The audio is band-limited to the speech range,
+// resampled to vadSampleRate and cut into vadFrameSamples frames, each analysed with
+// an FFT of vadFFTSize points.
+const (
+	vadSampleRate   = 16000
+	vadFrameSamples = 320 // 20 ms
+	vadFrameRate    = vadSampleRate / vadFrameSamples
+	vadFFTSize      = 512
+	vadLowCut       = 200  // Hz; below is hum and rumble
+	vadHighCut      = 3800 // Hz; above carries little voiced energy
+
+	// vadMaxFlatness is the highest spectral flatness of a speech frame. Voiced speech
+	// concentrates its energy in harmonics (flatness around 0.1), while fans, hiss and
+	// keyboard clicks spread it evenly (0.5 and up).
+	vadMaxFlatness = 0.3
+	// vadModulationFrames is the window, 1 s, over which the energy of the frames is
+	// checked for syllables.
+	vadModulationFrames = vadFrameRate
+	// vadModulationLow and vadModulationHigh bound the syllable rate in Hz. Speech
+	// rises and falls with its syllables about four times a second.
+	vadModulationLow  = 2
+	vadModulationHigh = 8
+	// vadMinModulation is the least power of the syllable-rate modulation, relative to
+	// the mean energy, that a speech frame sits in. Speech with pauses between syllables
+	// measures 0.1 and up, while held notes and melodies keep a steady level and measure
+	// close to 0.
+	vadMinModulation = 0.05
+	// vadOnsetFrames is how many speech frames in a row start speech, so isolated
+	// tonal blips don't count.
+	vadOnsetFrames = 3
+	// vadHangoverFrames is how long speech lasts after its last speech frame, bridging
+	// unvoiced consonants and the decay of words.
+	vadHangoverFrames = 15
+)
+
+// VoiceDetectOptions controls DetectVoice.
+type VoiceDetectOptions struct {
+	// Threshold is the lowest frame energy in the speech band, in dB RMS, that can
+	// count as speech. The other fields select the audio and the shortest silence.
+	SilenceDetectOptions
+	// AutoThreshold chooses Threshold for each stream from the distribution of its
+	// frame energies, as EstimateSilenceThreshold does for peak levels. Threshold is
+	// kept for streams whose energies don't separate.
+	AutoThreshold bool
+	// OnThreshold, if set, receives each threshold chosen by AutoThreshold.
+	OnThreshold func(ThresholdEstimate)
+}
+
+// VoiceDetectArgs returns the ffmpeg arguments that decode the selected audio of path
+// to band-limited 16 kHz mono PCM on stdout, for DetectVoice.
+func VoiceDetectArgs(path string, stream int, opts SilenceDetectOptions) []string {
+	args := []string{"-i", path}
+	if stream >= 0 {
+		args = append(args, "-map", fmt.Sprintf("0:a:%d", stream))
+	}
+
+	filter := fmt.Sprintf("highpass=f=%d,lowpass=f=%d,aresample=%d", vadLowCut, vadHighCut, vadSampleRate)
+	if opts.Channel > 0 {
+		filter = fmt.Sprintf("pan=mono|c0=c%d,", opts.Channel-1) + filter
+	}
+	return append(args, "-af", filter, "-ac", "1", "-c:a", "pcm_s16le", "-f", "s16le", "-")
+}
+
+// DetectVoice is like DetectNonSilent but keeps only speech: each stream is decoded
+// to PCM and every frame is classified by its energy, its spectral flatness and the
+// syllable-rate modulation around it. Steady noise and clicks are not harmonic, and
+// music does not rise and fall with syllables, so both count as silence even when they
+// are loud. Progress is reported through stage, with one job per stream.
+func DetectVoice(ctx context.Context, r Runner, ffmpeg, path string, totalDuration float64, opts VoiceDetectOptions, stage *StageProgress) ([]Segment, error) {
+	streams := opts.Streams
+	if len(streams) == 0 {
+		streams = []int{-1}
+	}
+
+	var all []Segment
+	for job, stream := range streams {
+		analyzer := newVoiceAnalyzer(stage.Reporter(job))
+		args := VoiceDetectArgs(path, stream, opts.SilenceDetectOptions)
+		var stderr bytes.Buffer
+		err := r.Run(ctx, ffmpeg, args, nil, analyzer, &stderr)
+		err = commandError(ctx, ffmpeg, args, stderr.Bytes(), err)
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("voice detection interrupted: %w", err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode audio: %w", err)
+		}
+
+		threshold := float64(opts.Threshold)
+		if opts.AutoThreshold {
+			estimate := EstimateSilenceThreshold(analyzer.energy, opts.Threshold)
+			if opts.OnThreshold != nil {
+				opts.OnThreshold(estimate)
+			}
+			threshold = float64(estimate.Threshold)
+		}
+
+		modulation := syllableModulation(analyzer.energy, analyzer.flatness, threshold)
+		speech := classifyVoice(analyzer.energy, analyzer.flatness, modulation, threshold)
+		starts, ends := voiceSilences(speech, opts.MinDuration, totalDuration)
+		all = append(all, BuildNonSilentSegments(starts, ends, totalDuration, 0)...)
+	}
+	stage.Done()
+
+	if len(streams) == 1 {
+		return all, nil
+	}
+	return NormalizeSegments(all, totalDuration), nil
+}
+
+// voiceAnalyzer receives 16-bit little-endian mono PCM and records the energy and
+// spectral flatness of every frame.
+type voiceAnalyzer struct {
+	energy   []float64 // dB RMS, at least minLevel
+	flatness []float64 // 0 (pure tone) to 1 (white noise)
+
+	pending  []byte
+	frame    []float64
+	window   []float64
+	spectrum []complex128
+	onReport func(ProgressReport)
+}
+
+func newVoiceAnalyzer(onReport func(ProgressReport)) *voiceAnalyzer {
+	window := make([]float64, vadFrameSamples)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(vadFrameSamples-1))
+	}
+	return &voiceAnalyzer{
+		window:   window,
+		frame:    make([]float64, 0, vadFrameSamples),
+		spectrum: make([]complex128, vadFFTSize),
+		onReport: onReport,
+	}
+}
+
+// Write implements io.Writer.
+func (a *voiceAnalyzer) Write(p []byte) (int, error) {
+	data := p
+	if len(a.pending) > 0 {
+		data = append(a.pending, p...)
+		a.pending = nil
+	}
+	for ; len(data) >= 2; data = data[2:] {
+		sample := float64(int16(binary.LittleEndian.Uint16(data))) / 32768
+		a.frame = append(a.frame, sample)
+		if len(a.frame) == vadFrameSamples {
+			a.analyze()
+			a.frame = a.frame[:0]
+		}
+	}
+	if len(data) > 0 {
+		a.pending = append(a.pending, data...)
+	}
+	return len(p), nil
+}
+
+// analyze records the features of the current frame.
+func (a *voiceAnalyzer) analyze() {
+	var sum float64
+	for i, s := range a.frame {
+		sum += s * s
+		a.spectrum[i] = complex(s*a.window[i], 0)
+	}
+	for i := len(a.frame); i < vadFFTSize; i++ {
+		a.spectrum[i] = 0
+	}
+
+	energy := minLevel
+	if sum > 0 {
+		energy = max(10*math.Log10(sum/float64(len(a.frame))), minLevel)
+	}
+	a.energy = append(a.energy, energy)
+	a.flatness = append(a.flatness, spectralFlatness(a.spectrum))
+
+	if frames := len(a.energy); a.onReport != nil && frames%vadFrameRate == 0 {
+		a.onReport(ProgressReport{OutTime: float64(frames*vadFrameSamples) / vadSampleRate})
+	}
+}
+
+// spectralFlatness transforms the frame in x and returns the ratio of the geometric
+// to the arithmetic mean of its power spectrum over the speech band.
+func spectralFlatness(x []complex128) float64 {
+	FFT(x)
+	low := vadLowCut * len(x) / vadSampleRate
+	high := vadHighCut * len(x) / vadSampleRate
+
+	var logSum, sum float64
+	for _, c := range x[low : high+1] {
+		power := real(c)*real(c) + imag(c)*imag(c) + 1e-12
+		logSum += math.Log(power)
+		sum += power
+	}
+	n := float64(high - low + 1)
+	return math.Exp(logSum/n) / (sum / n)
+}
+
+// voiced reports whether a frame is loud and harmonic enough to be voiced speech.
+func voiced(energy, flatness, threshold float64) bool {
+	return energy >= threshold && flatness <= vadMaxFlatness
+}
+
+// syllableModulation returns for every frame how strongly the energy around it rises
+// and falls at syllable rate: the power of the vadModulationLow-vadModulationHigh Hz
+// components of the frame amplitudes relative to their mean, over the windows of
+// vadModulationFrames that end and start at the frame, whichever is lower. Taking the
+// lower keeps music right before or after speech from borrowing its modulation. That
+// also erodes the speech at the join, so each voiced frame then takes the highest
+// modulation of the voiced frames around it, up to half a window away.
+//
+// Frames that aren't voiced count as silent, so noise next to speech doesn't hide it.
+func syllableModulation(energy, flatness []float64, threshold float64) []float64 {
+	if len(energy) == 0 {
+		return nil
+	}
+	isVoiced := make([]bool, len(energy))
+	amplitude := make([]float64, len(energy))
+	for i := range energy {
+		if voiced(energy[i], flatness[i], threshold) {
+			isVoiced[i] = true
+			amplitude[i] = math.Pow(10, energy[i]/20)
+		}
+	}
+
+	// Files shorter than the window are measured as a whole
+	n := min(vadModulationFrames, len(amplitude))
+	var cos, sin [][]float64
+	for k := (vadModulationLow*n + vadFrameRate - 1) / vadFrameRate; k <= vadModulationHigh*n/vadFrameRate; k++ {
+		c, s := make([]float64, n), make([]float64, n)
+		for t := range c {
+			c[t] = math.Cos(2 * math.Pi * float64(k*t) / float64(n))
+			s[t] = math.Sin(2 * math.Pi * float64(k*t) / float64(n))
+		}
+		cos, sin = append(cos, c), append(sin, s)
+	}
+
+	windows := make([]float64, len(amplitude)-n+1)
+	for j := range windows {
+		window := amplitude[j : j+n]
+		var sum float64
+		for _, a := range window {
+			sum += a
+		}
+		if sum == 0 {
+			continue
+		}
+		var power float64
+		for k := range cos {
+			var re, im float64
+			for t, a := range window {
+				re += a * cos[k][t]
+				im += a * sin[k][t]
+			}
+			power += re*re + im*im
+		}
+		windows[j] = power / (sum * sum)
+	}
+
+	lowest := make([]float64, len(amplitude))
+	for i := range lowest {
+		lowest[i] = min(windows[max(i-n+1, 0)], windows[min(i, len(windows)-1)])
+	}
+
+	modulation := make([]float64, len(amplitude))
+	for i := range modulation {
+		if !isVoiced[i] {
+			continue
+		}
+		modulation[i] = lowest[i]
+		for j := i - 1; j >= max(i-n/2, 0) && isVoiced[j]; j-- {
+			modulation[i] = max(modulation[i], lowest[j])
+		}
+		for j := i + 1; j <= min(i+n/2, len(lowest)-1) && isVoiced[j]; j++ {
+			modulation[i] = max(modulation[i], lowest[j])
+		}
+	}
+	return modulation
+}
+
+// classifyVoice marks the frames that are speech: at least vadOnsetFrames in a row that
+// are voiced at threshold and modulated by at least vadMinModulation, extended by
+// vadHangoverFrames.
+func classifyVoice(energy, flatness, modulation []float64, threshold float64) []bool {
+	speech := make([]bool, len(energy))
+	run, hangover := 0, 0
+	for i := range energy {
+		if voiced(energy[i], flatness[i], threshold) && modulation[i] >= vadMinModulation {
+			run++
+		} else {
+			run = 0
+		}
+		switch {
+		case run >= vadOnsetFrames:
+			if run == vadOnsetFrames {
+				for j := i - vadOnsetFrames + 1; j < i; j++ {
+					speech[j] = true
+				}
+			}
+			speech[i] = true
+			hangover = vadHangoverFrames
+		case hangover > 0:
+			speech[i] = true
+			hangover--
+		}
+	}
+	return speech
+}
+
+// voiceSilences returns the start and end times of the runs of non-speech frames
+// lasting at least minDuration, in the form ParseSilenceOutput returns.
+func voiceSilences(speech []bool, minDuration, totalDuration float64) (starts, ends []float64) {
+	const frame = float64(vadFrameSamples) / vadSampleRate
+	add := func(from, to int) {
+		start, end := float64(from)*frame, min(float64(to)*frame, totalDuration)
+		if to == len(speech) {
+			end = totalDuration
+		}
+		if end-start >= minDuration && end > start {
+			starts = append(starts, start)
+			ends = append(ends, end)
+		}
+	}
+
+	silentFrom := -1
+	for i, s := range speech {
+		switch {
+		case !s && silentFrom < 0:
+			silentFrom = i
+		case s && silentFrom >= 0:
+			add(silentFrom, i)
+			silentFrom = -1
+		}
+	}
+	if silentFrom >= 0 {
+		add(silentFrom, len(speech))
+	}
+	return starts, ends
+}
@@ -0,0 +1,243 @@
+package ffutil
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// pcm encodes samples as 16-bit little-endian PCM.
+func pcm(samples []float64) string {
+	b := make([]byte, 2*len(samples))
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(b[2*i:], uint16(int16(s*32767)))
+	}
+	return string(b)
+}
+
+// voicedTone returns a harmonic-rich 150 Hz tone, a held note.
+func voicedTone(seconds, amplitude float64) []float64 {
+	out := make([]float64, int(seconds*vadSampleRate))
+	for i := range out {
+		t := float64(i) / vadSampleRate
+		for h := 2.0; h <= 8; h++ {
+			out[i] += amplitude / h * math.Sin(2*math.Pi*150*h*t)
+		}
+	}
+	return out
+}
+
+// syllables returns voicedTone rising and falling four times a second, a stand-in
+// for speech.
+func syllables(seconds, amplitude float64) []float64 {
+	out := voicedTone(seconds, amplitude)
+	for i := range out {
+		t := float64(i) / vadSampleRate
+		out[i] *= 0.575 - 0.425*math.Cos(2*math.Pi*4*t)
+	}
+	return out
+}
+
+// melody returns harmonic-rich notes of a quarter second at a steady level, a
+// stand-in for music.
+func melody(seconds, amplitude float64) []float64 {
+	notes := []float64{196, 262, 330, 392, 330, 262}
+	out := make([]float64, int(seconds*vadSampleRate))
+	for i := range out {
+		t := float64(i) / vadSampleRate
+		note := notes[int(t*4)%len(notes)]
+		for h := 2.0; h <= 8; h++ {
+			out[i] += amplitude / h * math.Sin(2*math.Pi*note*h*t)
+		}
+	}
+	return out
+}
+
+func whiteNoise(seconds, amplitude float64, rng *rand.Rand) []float64 {
+	out := make([]float64, int(seconds*vadSampleRate))
+	for i := range out {
+		out[i] = amplitude * (2*rng.Float64() - 1)
+	}
+	return out
+}
+
+func TestVoiceDetectArgs(t *testing.T) {
+	got := strings.Join(VoiceDetectArgs("in.mp4", 1, SilenceDetectOptions{Channel: 1}), " ")
+	want := "-i in.mp4 -map 0:a:1 -af pan=mono|c0=c0,highpass=f=200,lowpass=f=3800,aresample=16000 " +
+		"-ac 1 -c:a pcm_s16le -f s16le -"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestSpectralFlatness(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	measure := func(samples []float64) float64 {
+		a := newVoiceAnalyzer(nil)
+		a.Write([]byte(pcm(samples[:vadFrameSamples])))
+		return a.flatness[0]
+	}
+	if f := measure(voicedTone(0.1, 0.5)); f > vadMaxFlatness {
+		t.Errorf("voiced flatness = %.2f, want at most %.2f", f, vadMaxFlatness)
+	}
+	if f := measure(whiteNoise(0.1, 0.5, rng)); f < vadMaxFlatness {
+		t.Errorf("noise flatness = %.2f, want above %.2f", f, vadMaxFlatness)
+	}
+}
+
+func TestClassifyVoice_OnsetAndHangover(t *testing.T) {
+	energy := make([]float64, 60)
+	flatness := make([]float64, 60)
+	for i := range energy {
+		energy[i] = -60
+	}
+	energy[2] = -10 // a single loud frame is not speech
+	for i := 10; i < 14; i++ {
+		energy[i] = -10
+	}
+
+	modulation := make([]float64, 60)
+	for i := range modulation {
+		modulation[i] = 1
+	}
+	for i := 40; i < 50; i++ {
+		energy[i] = -10
+		modulation[i] = 0 // loud but too steady to be speech
+	}
+
+	speech := classifyVoice(energy, flatness, modulation, -40)
+	if speech[2] {
+		t.Error("isolated frame classified as speech")
+	}
+	for i := 10; i < 14+vadHangoverFrames; i++ {
+		if !speech[i] {
+			t.Errorf("frame %d should be speech or hangover", i)
+		}
+	}
+	if speech[14+vadHangoverFrames] || speech[9] {
+		t.Error("speech extends past its onset or hangover")
+	}
+	for i := 40; i < 50; i++ {
+		if speech[i] {
+			t.Errorf("unmodulated frame %d classified as speech", i)
+		}
+	}
+}
+
+func TestSyllableModulation(t *testing.T) {
+	measure := func(samples []float64) float64 {
+		a := newVoiceAnalyzer(nil)
+		a.Write([]byte(pcm(samples)))
+		modulation := syllableModulation(a.energy, a.flatness, -40)
+		return modulation[len(modulation)/2]
+	}
+	if m := measure(syllables(2, 0.3)); m < vadMinModulation {
+		t.Errorf("speech modulation = %.3f, want at least %.3f", m, vadMinModulation)
+	}
+	if m := measure(voicedTone(2, 0.3)); m >= vadMinModulation {
+		t.Errorf("held note modulation = %.3f, want below %.3f", m, vadMinModulation)
+	}
+	if m := measure(melody(2, 0.3)); m >= vadMinModulation {
+		t.Errorf("melody modulation = %.3f, want below %.3f", m, vadMinModulation)
+	}
+}
+
+func TestDetectVoice_IgnoresNoise(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	// 1s voice, 1s loud noise, 1s voice
+	var samples []float64
+	samples = append(samples, syllables(1, 0.3)...)
+	samples = append(samples, whiteNoise(1, 0.3, rng)...)
+	samples = append(samples, syllables(1, 0.3)...)
+	r := &fakeRunner{stdout: pcm(samples)}
+
+	opts := VoiceDetectOptions{SilenceDetectOptions: SilenceDetectOptions{Threshold: -40, MinDuration: 0.5}}
+	got, err := DetectVoice(context.Background(), r, "ffmpeg", "in.wav", 3, opts, nil)
+	if err != nil {
+		t.Fatalf("DetectVoice: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d segments, want 2: %+v", len(got), got)
+	}
+	assertFloat(t, got[0].StartTime, 0, "first start")
+	// The hangover keeps 0.3s of the noise after the voice
+	assertFloat(t, got[0].EndTime, 1.3, "first end")
+	assertFloat(t, got[1].StartTime, 2, "second start")
+	assertFloat(t, got[1].EndTime, 3, "second end")
+}
+
+func TestDetectVoice_IgnoresMusic(t *testing.T) {
+	// 1s voice, 3s music at the same level, 1s voice
+	var samples []float64
+	samples = append(samples, syllables(1, 0.3)...)
+	samples = append(samples, melody(3, 0.2)...)
+	samples = append(samples, syllables(1, 0.3)...)
+	r := &fakeRunner{stdout: pcm(samples)}
+
+	opts := VoiceDetectOptions{SilenceDetectOptions: SilenceDetectOptions{Threshold: -40, MinDuration: 0.5}}
+	got, err := DetectVoice(context.Background(), r, "ffmpeg", "in.wav", 5, opts, nil)
+	if err != nil {
+		t.Fatalf("DetectVoice: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d segments, want 2: %+v", len(got), got)
+	}
+	// The window blurs the joins by up to half a second
+	if got[0].StartTime != 0 || got[0].EndTime < 0.5 || got[0].EndTime > 1.5 {
+		t.Errorf("first segment %+v, want the first second", got[0])
+	}
+	if got[1].StartTime < 3.5 || got[1].StartTime > 4.5 || got[1].EndTime != 5 {
+		t.Errorf("second segment %+v, want the last second", got[1])
+	}
+}
+
+func TestDetectVoice_AutoThreshold(t *testing.T) {
+	// Quiet voice over silence: -40 dB would miss it, the automatic threshold does not
+	var samples []float64
+	samples = append(samples, syllables(1, 0.005)...)
+	samples = append(samples, make([]float64, vadSampleRate)...)
+	samples = append(samples, syllables(1, 0.005)...)
+	r := &fakeRunner{stdout: pcm(samples)}
+
+	var chosen []ThresholdEstimate
+	opts := VoiceDetectOptions{
+		SilenceDetectOptions: SilenceDetectOptions{Threshold: -40, MinDuration: 0.5},
+		AutoThreshold:        true,
+		OnThreshold:          func(e ThresholdEstimate) { chosen = append(chosen, e) },
+	}
+	got, err := DetectVoice(context.Background(), r, "ffmpeg", "in.wav", 3, opts, nil)
+	if err != nil {
+		t.Fatalf("DetectVoice: %v", err)
+	}
+	if len(chosen) != 1 || chosen[0].Threshold >= -40 {
+		t.Errorf("chosen thresholds = %+v, want one below -40 dB", chosen)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %d segments, want 2: %+v", len(got), got)
+	}
+}
+
+func TestDetectVoice_AutoThresholdWithoutPauses(t *testing.T) {
+	// A note held from start to end gives the energies nothing to separate
+	r := &fakeRunner{stdout: pcm(voicedTone(3, 0.1))}
+
+	var chosen []ThresholdEstimate
+	opts := VoiceDetectOptions{
+		SilenceDetectOptions: SilenceDetectOptions{Threshold: -45, MinDuration: 0.5},
+		AutoThreshold:        true,
+		OnThreshold:          func(e ThresholdEstimate) { chosen = append(chosen, e) },
+	}
+	got, err := DetectVoice(context.Background(), r, "ffmpeg", "in.wav", 3, opts, nil)
+	if err != nil {
+		t.Fatalf("DetectVoice: %v", err)
+	}
+	if len(chosen) != 1 || !chosen[0].Fallback || chosen[0].Threshold != -45 {
+		t.Errorf("chosen thresholds = %+v, want the -45 dB fallback", chosen)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %+v, want no speech", got)
+	}
+}
@@ -0,0 +1,129 @@
+// Package media opens a file without knowing in advance whether it is a video or
+// an audio file, and hands out the matching video.Video or audio.Audio.
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/meunomeebero/ffmpego/audio"
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+	"github.com/meunomeebero/ffmpego/video"
+)
+
+// Type is the kind of media a file holds.
+type Type string
+
+const (
+	// TypeVideo is a file with at least one video stream. Cover art and other
+	// attached pictures do not count.
+	TypeVideo Type = "video"
+	// TypeAudio is a file with audio streams and no video stream.
+	TypeAudio Type = "audio"
+)
+
+// Errors returned by Open and the AsVideo/AsAudio conversions
+var (
+	ErrNoMediaStreams = errors.New("no video or audio stream")
+	ErrNoVideoStream  = errors.New("no video stream")
+	ErrNoAudioStream  = errors.New("no audio stream")
+)
+
+// ProbeResult is the full ffprobe description of a file.
+type ProbeResult = ffutil.ProbeResult
+
+// Media is a probed media file.
+type Media interface {
+	// Path returns the file path.
+	Path() string
+	// Type reports whether the file is a video or an audio file.
+	Type() Type
+	// HasVideo reports whether the file has a video stream other than an attached picture.
+	HasVideo() bool
+	// HasAudio reports whether the file has at least one audio stream.
+	HasAudio() bool
+	// Probe returns the ffprobe description Open classified the file from.
+	Probe() *ProbeResult
+	// AsVideo returns the file as a video.Video, or ErrNoVideoStream.
+	AsVideo() (*video.Video, error)
+	// AsAudio returns the file as an audio.Audio, or ErrNoAudioStream. Video files
+	// with an audio track can be opened as audio too.
+	AsAudio() (*audio.Audio, error)
+}
+
+type file struct {
+	path   string
+	probe  *ProbeResult
+	config *config
+}
+
+// Open probes path and classifies it as video or audio.
+// Returns an error if ffmpeg/ffprobe are not installed, the file does not exist or
+// it has neither a video nor an audio stream.
+func Open(path string, opts ...Option) (Media, error) {
+	return OpenContext(context.Background(), path, opts...)
+}
+
+// OpenContext is like Open but stops ffprobe when ctx is cancelled or its deadline
+// passes.
+func OpenContext(ctx context.Context, path string, opts ...Option) (Media, error) {
+	c := newConfig(opts)
+	if _, ok := c.runner.(ffutil.ExecRunner); ok {
+		if err := ffutil.CheckBinaries(c.ffmpeg, c.ffprobe); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("media file not accessible: %s: %w", path, err)
+	}
+
+	probe, err := ffutil.Probe(ctx, c.runner, c.ffprobe, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe file: %w", err)
+	}
+
+	f := &file{path: path, probe: probe, config: c}
+	if !f.HasVideo() && !f.HasAudio() {
+		return nil, fmt.Errorf("%s: %w", path, ErrNoMediaStreams)
+	}
+	return f, nil
+}
+
+func (f *file) Path() string {
+	return f.path
+}
+
+func (f *file) Type() Type {
+	if f.HasVideo() {
+		return TypeVideo
+	}
+	return TypeAudio
+}
+
+func (f *file) HasVideo() bool {
+	return len(f.probe.VideoStreams()) > 0
+}
+
+func (f *file) HasAudio() bool {
+	return len(f.probe.AudioStreams()) > 0
+}
+
+func (f *file) Probe() *ProbeResult {
+	return f.probe.Clone()
+}
+
+func (f *file) AsVideo() (*video.Video, error) {
+	if !f.HasVideo() {
+		return nil, fmt.Errorf("%s: %w", f.path, ErrNoVideoStream)
+	}
+	return video.New(f.path, f.config.videoOptions(f.probe)...)
+}
+
+func (f *file) AsAudio() (*audio.Audio, error) {
+	if !f.HasAudio() {
+		return nil, fmt.Errorf("%s: %w", f.path, ErrNoAudioStream)
+	}
+	return audio.New(f.path, f.config.audioOptions(f.probe)...)
+}
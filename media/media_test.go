@@ -0,0 +1,174 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// probeRunner answers every ffprobe call with canned JSON and counts them.
+type probeRunner struct {
+	probe string
+	calls int
+}
+
+func (r *probeRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	r.calls++
+	io.WriteString(stdout, r.probe)
+	return nil
+}
+
+// tempFile creates an empty file so that the existence check in Open passes.
+func tempFile(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	return path
+}
+
+func TestOpen_Classification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		probe     string
+		wantType  Type
+		wantVideo bool
+		wantAudio bool
+	}{
+		{
+			name: "video with audio",
+			probe: `{"streams": [
+				{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080},
+				{"index": 1, "codec_type": "audio", "codec_name": "aac"}
+			], "format": {}}`,
+			wantType:  TypeVideo,
+			wantVideo: true,
+			wantAudio: true,
+		},
+		{
+			name: "mp3 with cover art",
+			probe: `{"streams": [
+				{"index": 0, "codec_type": "audio", "codec_name": "mp3"},
+				{"index": 1, "codec_type": "video", "codec_name": "mjpeg", "width": 500, "height": 500, "disposition": {"attached_pic": 1}}
+			], "format": {}}`,
+			wantType:  TypeAudio,
+			wantAudio: true,
+		},
+		{
+			name: "silent screen recording",
+			probe: `{"streams": [
+				{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1280, "height": 720}
+			], "format": {}}`,
+			wantType:  TypeVideo,
+			wantVideo: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m, err := Open(tempFile(t, "input"), WithRunner(&probeRunner{probe: tt.probe}))
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			if m.Type() != tt.wantType {
+				t.Errorf("Type() = %q, want %q", m.Type(), tt.wantType)
+			}
+			if m.HasVideo() != tt.wantVideo {
+				t.Errorf("HasVideo() = %v, want %v", m.HasVideo(), tt.wantVideo)
+			}
+			if m.HasAudio() != tt.wantAudio {
+				t.Errorf("HasAudio() = %v, want %v", m.HasAudio(), tt.wantAudio)
+			}
+		})
+	}
+}
+
+func TestOpen_AsVideoAsAudio(t *testing.T) {
+	t.Parallel()
+
+	r := &probeRunner{probe: `{"streams": [{"index": 0, "codec_type": "audio", "codec_name": "flac", "sample_rate": "44100"}], "format": {}}`}
+	path := tempFile(t, "song.flac")
+	m, err := Open(path, WithRunner(r))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	if _, err := m.AsVideo(); !errors.Is(err, ErrNoVideoStream) {
+		t.Errorf("AsVideo() error = %v, want ErrNoVideoStream", err)
+	}
+
+	a, err := m.AsAudio()
+	if err != nil {
+		t.Fatalf("AsAudio: %v", err)
+	}
+	if a.Path() != path {
+		t.Errorf("Path() = %q, want %q", a.Path(), path)
+	}
+	info, err := a.GetInfo()
+	if err != nil {
+		t.Fatalf("GetInfo: %v", err)
+	}
+	if info.SampleRate != 44100 {
+		t.Errorf("SampleRate = %d, want 44100 (runner should be passed on)", info.SampleRate)
+	}
+}
+
+func TestOpen_AsVideoAsAudioReuseProbe(t *testing.T) {
+	t.Parallel()
+
+	path := tempFile(t, "clip.mp4")
+	r := &probeRunner{probe: fmt.Sprintf(`{"streams": [
+		{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1280, "height": 720, "r_frame_rate": "30/1"},
+		{"index": 1, "codec_type": "audio", "codec_name": "aac", "sample_rate": "48000", "channels": 2}
+	], "format": {"filename": %q, "duration": "4.0"}}`, path)}
+	m, err := Open(path, WithRunner(r))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	v, err := m.AsVideo()
+	if err != nil {
+		t.Fatalf("AsVideo: %v", err)
+	}
+	if _, err := v.GetInfo(); err != nil {
+		t.Fatalf("Video.GetInfo: %v", err)
+	}
+	a, err := m.AsAudio()
+	if err != nil {
+		t.Fatalf("AsAudio: %v", err)
+	}
+	if _, err := a.GetInfo(); err != nil {
+		t.Fatalf("Audio.GetInfo: %v", err)
+	}
+	if r.calls != 1 {
+		t.Errorf("ran ffprobe %d times, want once for Open", r.calls)
+	}
+}
+
+func TestOpen_NoMediaStreams(t *testing.T) {
+	t.Parallel()
+
+	r := &probeRunner{probe: `{"streams": [{"index": 0, "codec_type": "subtitle", "codec_name": "subrip"}], "format": {}}`}
+	_, err := Open(tempFile(t, "captions.srt"), WithRunner(r))
+	if !errors.Is(err, ErrNoMediaStreams) {
+		t.Fatalf("expected ErrNoMediaStreams, got %v", err)
+	}
+}
+
+func TestOpen_FileNotFound(t *testing.T) {
+	t.Parallel()
+
+	_, err := Open("/nonexistent/file.mp4", WithRunner(&probeRunner{}))
+	if err == nil {
+		t.Fatal("expected an error for a missing file, got nil")
+	}
+}
@@ -0,0 +1,77 @@
+package media
+
+import (
+	"github.com/meunomeebero/ffmpego/audio"
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+	"github.com/meunomeebero/ffmpego/video"
+)
+
+// Runner executes the ffmpeg and ffprobe commands issued for a file.
+type Runner = ffutil.Runner
+
+// Option configures how Open and the Video/Audio it hands out run ffmpeg.
+type Option func(*config)
+
+type config struct {
+	runner  ffutil.Runner
+	ffmpeg  string
+	ffprobe string
+}
+
+// WithRunner runs ffmpeg and ffprobe through r instead of starting local
+// processes. See video.WithRunner.
+func WithRunner(r Runner) Option {
+	return func(c *config) {
+		if r != nil {
+			c.runner = r
+		}
+	}
+}
+
+// WithFFmpegPath sets the ffmpeg binary to use. Defaults to "ffmpeg".
+func WithFFmpegPath(path string) Option {
+	return func(c *config) {
+		if path != "" {
+			c.ffmpeg = path
+		}
+	}
+}
+
+// WithFFprobePath sets the ffprobe binary to use. Defaults to "ffprobe".
+func WithFFprobePath(path string) Option {
+	return func(c *config) {
+		if path != "" {
+			c.ffprobe = path
+		}
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		runner:  ffutil.ExecRunner{},
+		ffmpeg:  ffutil.DefaultFFmpeg,
+		ffprobe: ffutil.DefaultFFprobe,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *config) videoOptions(probe *ProbeResult) []video.Option {
+	return []video.Option{
+		video.WithRunner(c.runner),
+		video.WithFFmpegPath(c.ffmpeg),
+		video.WithFFprobePath(c.ffprobe),
+		video.WithProbe(probe),
+	}
+}
+
+func (c *config) audioOptions(probe *ProbeResult) []audio.Option {
+	return []audio.Option{
+		audio.WithRunner(c.runner),
+		audio.WithFFmpegPath(c.ffmpeg),
+		audio.WithFFprobePath(c.ffprobe),
+		audio.WithProbe(probe),
+	}
+}
@@ -0,0 +1,267 @@
+// Package multicam edits a multi-camera recording automatically: it cuts to the
+// camera of whoever is talking, as a director would for a podcast or interview with
+// one camera and one mic per person.
+//
+// Who is talking comes from silence detection on each mic. The switching follows two
+// rules: a shot lasts at least Config.MinShotDuration, and while several people talk
+// at once the edit cuts to a wide shot, if there is one. The recordings must already
+// be in sync; see video.Video.FindAudioOffset and WithAudioOffset.
+package multicam
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/meunomeebero/ffmpego/audio"
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+	"github.com/meunomeebero/ffmpego/video"
+)
+
+// Segment is a time range in seconds.
+type Segment = ffutil.Segment
+
+// Progress describes how far an edit has come.
+type Progress = ffutil.Progress
+
+// ProgressFunc receives progress updates.
+type ProgressFunc = ffutil.ProgressFunc
+
+// Stage identifies which part of an edit a Progress value refers to.
+type Stage = ffutil.Stage
+
+// Stages reported through Progress.Stage
+const (
+	StageDetect = ffutil.StageDetect // Silence detection on the mics
+	StageRender = ffutil.StageRender // Rendering the edit
+)
+
+// Wide is the Shot.Angle of the wide shot, Config.Wide.
+const Wide = -1
+
+// noSpeaker marks the parts of the recording where nobody talks.
+const noSpeaker = -2
+
+// Defaults for the switching rules, in milliseconds
+const (
+	defaultMinShotDuration   = 2000
+	defaultCrosstalkDuration = 1000
+)
+
+// Share of the Edit percentage given to detecting activity on the mics
+const detectProgressEnd = 15.0
+
+// Angle is one camera and the mic of the person it frames.
+type Angle struct {
+	Video *video.Video
+	Mic   *audio.Audio
+}
+
+// Shot is a part of the edit showing one angle, in seconds of the recording.
+type Shot struct {
+	StartTime float64
+	EndTime   float64
+	Angle     int // Index into the angles, or Wide
+}
+
+// Config contains configuration for Plan, Render and Edit.
+type Config struct {
+	// Wide is an optional camera that frames everyone. The edit cuts to it while
+	// several people talk at once; without it the current shot holds.
+	Wide *video.Video
+
+	// Activity configures the silence detection run on each mic; zero values select
+	// the audio package defaults. Set the threshold above the level at which a mic
+	// picks up the other people, or set AutoThreshold. OnProgress is ignored.
+	Activity audio.SilenceConfig
+
+	// MinShotDuration is the shortest a shot lasts before the edit cuts away, in
+	// milliseconds (default 2000). The last shot may be shorter.
+	MinShotDuration int
+	// CrosstalkDuration is how long several people must talk at once before the edit
+	// cuts to the wide shot, in milliseconds (default 1000). Shorter overlaps, such as
+	// one person finishing as the next begins, don't cut.
+	CrosstalkDuration int
+
+	// OnProgress, if set, receives progress updates: StageDetect while the mics are
+	// analysed, then StageRender.
+	OnProgress ProgressFunc
+}
+
+func (c Config) minShotDuration() float64 {
+	if c.MinShotDuration <= 0 {
+		return defaultMinShotDuration / 1000.0
+	}
+	return float64(c.MinShotDuration) / 1000.0
+}
+
+func (c Config) crosstalkDuration() float64 {
+	if c.CrosstalkDuration <= 0 {
+		return defaultCrosstalkDuration / 1000.0
+	}
+	return float64(c.CrosstalkDuration) / 1000.0
+}
+
+// Plan detects who talks when on each mic and returns the shots of the edit, covering
+// the recording from the start to the end of the shortest camera.
+func Plan(angles []Angle, config Config) ([]Shot, error) {
+	return PlanContext(context.Background(), angles, config)
+}
+
+// PlanContext is like Plan but stops ffmpeg when ctx is cancelled or its deadline
+// passes.
+func PlanContext(ctx context.Context, angles []Angle, config Config) ([]Shot, error) {
+	return plan(ctx, angles, config, 100)
+}
+
+// plan runs Plan, reporting progress as the [0, progressEnd] percent range of the
+// overall operation.
+func plan(ctx context.Context, angles []Angle, config Config, progressEnd float64) ([]Shot, error) {
+	duration, err := programDuration(ctx, angles, config.Wide)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([][]Segment, len(angles))
+	for i, angle := range angles {
+		detect := config.Activity
+		detect.OnProgress = nil
+		if onProgress := config.OnProgress; onProgress != nil {
+			from := progressEnd * float64(i) / float64(len(angles))
+			detect.OnProgress = func(p Progress) {
+				p.Percent = from + p.Percent*progressEnd/100/float64(len(angles))
+				onProgress(p)
+			}
+		}
+		segments, err := angle.Mic.GetNonSilentSegmentsContext(ctx, detect)
+		if err != nil {
+			return nil, fmt.Errorf("failed to detect activity on mic %d: %w", i+1, err)
+		}
+		activity[i] = segments
+	}
+	return PlanShots(activity, duration, config.Wide != nil, config), nil
+}
+
+// PlanShots returns the shots of an edit of duration seconds from when each person
+// talks: activity[i] holds the segments of angle i's mic. Each part where one person
+// talks alone shows their angle, and crosstalk shows Wide when withWide is set.
+// Silence keeps the current shot.
+//
+// A cut is placed where the new speaker starts, or later if the current shot would
+// otherwise be shorter than config.MinShotDuration. Speakers who stop before that
+// never get a shot.
+func PlanShots(activity [][]Segment, duration float64, withWide bool, config Config) []Shot {
+	if duration <= 0 {
+		return nil
+	}
+	minShot, crosstalk := config.minShotDuration(), config.crosstalkDuration()
+
+	var shots []Shot
+	current, shotStart := noSpeaker, 0.0
+	for _, run := range speakerRuns(activity, duration, withWide) {
+		if run.Angle == noSpeaker || run.Angle == current {
+			continue
+		}
+		if run.Angle == Wide && run.EndTime-run.StartTime < crosstalk {
+			continue
+		}
+		if current == noSpeaker {
+			// The first speaker is on screen from the start
+			current = run.Angle
+			continue
+		}
+		cut := max(run.StartTime, shotStart+minShot)
+		if cut >= run.EndTime {
+			continue
+		}
+		shots = append(shots, Shot{StartTime: shotStart, EndTime: cut, Angle: current})
+		current, shotStart = run.Angle, cut
+	}
+
+	if current == noSpeaker {
+		current = 0
+		if withWide {
+			current = Wide
+		}
+	}
+	return append(shots, Shot{StartTime: shotStart, EndTime: duration, Angle: current})
+}
+
+// speakerRuns splits [0, duration] into the parts where the same person talks alone,
+// several people talk (Wide, or noSpeaker without a wide shot) or nobody does
+// (noSpeaker). Neighbouring parts have different speakers.
+func speakerRuns(activity [][]Segment, duration float64, withWide bool) []Shot {
+	times := []float64{0, duration}
+	normalized := make([][]Segment, len(activity))
+	for i, segments := range activity {
+		normalized[i] = ffutil.NormalizeSegments(segments, duration)
+		for _, seg := range normalized[i] {
+			times = append(times, seg.StartTime, seg.EndTime)
+		}
+	}
+	sort.Float64s(times)
+
+	var runs []Shot
+	for i := 1; i < len(times); i++ {
+		start, end := times[i-1], times[i]
+		if end <= start {
+			continue
+		}
+		mid := (start + end) / 2
+		speaker, talking := noSpeaker, 0
+		for angle, segments := range normalized {
+			if active(segments, mid) {
+				speaker = angle
+				talking++
+			}
+		}
+		if talking > 1 {
+			speaker = noSpeaker
+			if withWide {
+				speaker = Wide
+			}
+		}
+
+		if n := len(runs); n > 0 && runs[n-1].Angle == speaker {
+			runs[n-1].EndTime = end
+			continue
+		}
+		runs = append(runs, Shot{StartTime: start, EndTime: end, Angle: speaker})
+	}
+	return runs
+}
+
+// active reports whether t falls inside one of segments, which are sorted.
+func active(segments []Segment, t float64) bool {
+	i := sort.Search(len(segments), func(i int) bool { return segments[i].EndTime > t })
+	return i < len(segments) && segments[i].StartTime <= t
+}
+
+// programDuration returns the duration of the shortest camera.
+func programDuration(ctx context.Context, angles []Angle, wide *video.Video) (float64, error) {
+	if len(angles) == 0 {
+		return 0, fmt.Errorf("no angles given")
+	}
+	cameras := make([]*video.Video, 0, len(angles)+1)
+	for i, angle := range angles {
+		if angle.Video == nil || angle.Mic == nil {
+			return 0, fmt.Errorf("angle %d needs both a video and a mic", i+1)
+		}
+		cameras = append(cameras, angle.Video)
+	}
+	if wide != nil {
+		cameras = append(cameras, wide)
+	}
+
+	duration := 0.0
+	for i, camera := range cameras {
+		info, err := camera.GetInfoContext(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get video info: %w", err)
+		}
+		if i == 0 || info.Duration < duration {
+			duration = info.Duration
+		}
+	}
+	return duration, nil
+}
@@ -0,0 +1,242 @@
+package multicam
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meunomeebero/ffmpego/audio"
+	"github.com/meunomeebero/ffmpego/video"
+)
+
+func assertShots(t *testing.T, got, want []Shot) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d shots %+v, want %+v", len(got), got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("shot %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPlanShots(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		activity [][]Segment
+		withWide bool
+		config   Config
+		want     []Shot
+	}{
+		{
+			name: "alternating speakers",
+			activity: [][]Segment{
+				{{StartTime: 1, EndTime: 5}, {StartTime: 11, EndTime: 15}},
+				{{StartTime: 6, EndTime: 10}},
+			},
+			want: []Shot{{0, 6, 0}, {6, 11, 1}, {11, 20, 0}},
+		},
+		{
+			name: "short interjection is held off by the minimum shot length",
+			activity: [][]Segment{
+				{{StartTime: 0, EndTime: 10}},
+				{{StartTime: 1, EndTime: 1.5}, {StartTime: 11, EndTime: 20}},
+			},
+			config: Config{MinShotDuration: 3000},
+			want:   []Shot{{0, 11, 0}, {11, 20, 1}},
+		},
+		{
+			name: "cut delayed until the shot is long enough",
+			activity: [][]Segment{
+				{{StartTime: 0, EndTime: 1}},
+				{{StartTime: 1, EndTime: 8}},
+			},
+			config: Config{MinShotDuration: 3000},
+			want:   []Shot{{0, 3, 0}, {3, 20, 1}},
+		},
+		{
+			name: "crosstalk cuts to the wide shot",
+			activity: [][]Segment{
+				{{StartTime: 0, EndTime: 9}},
+				{{StartTime: 5, EndTime: 14}},
+			},
+			withWide: true,
+			want:     []Shot{{0, 5, 0}, {5, 9, Wide}, {9, 20, 1}},
+		},
+		{
+			name: "short overlap at a handover stays on the speakers",
+			activity: [][]Segment{
+				{{StartTime: 0, EndTime: 6.5}},
+				{{StartTime: 6, EndTime: 14}},
+			},
+			withWide: true,
+			want:     []Shot{{0, 6.5, 0}, {6.5, 20, 1}},
+		},
+		{
+			name: "crosstalk without a wide shot holds",
+			activity: [][]Segment{
+				{{StartTime: 0, EndTime: 9}},
+				{{StartTime: 5, EndTime: 14}},
+			},
+			want: []Shot{{0, 9, 0}, {9, 20, 1}},
+		},
+		{
+			name:     "nobody talks",
+			activity: [][]Segment{nil, nil},
+			withWide: true,
+			want:     []Shot{{0, 20, Wide}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertShots(t, PlanShots(tt.activity, 20, tt.withWide, tt.config), tt.want)
+		})
+	}
+}
+
+func TestValidateShots(t *testing.T) {
+	t.Parallel()
+
+	valid := []Shot{{0, 2, 0}, {2, 5, Wide}, {5, 6, 1}}
+	if err := validateShots(valid, 2, true); err != nil {
+		t.Errorf("valid shots: %v", err)
+	}
+	for name, shots := range map[string][]Shot{
+		"empty":         nil,
+		"unknown angle": {{0, 2, 2}},
+		"gap":           {{0, 2, 0}, {3, 5, 1}},
+		"reversed":      {{2, 1, 0}},
+	} {
+		if err := validateShots(shots, 2, true); err == nil {
+			t.Errorf("%s: expected an error, got nil", name)
+		}
+	}
+	if err := validateShots(valid, 2, false); err == nil {
+		t.Error("expected an error for a wide shot without Config.Wide, got nil")
+	}
+}
+
+func TestProgramFilter(t *testing.T) {
+	t.Parallel()
+
+	got := programFilter([]Shot{{0, 2, 1}, {2, 3.5, Wide}}, 2, true, frame{Width: 1280, Height: 720, FrameRate: "30000/1001"})
+	conform := "scale=1280:720:force_original_aspect_ratio=decrease,pad=1280:720:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=30000/1001"
+	want := "[1:v]trim=start=0.000000:end=2.000000,setpts=PTS-STARTPTS," + conform + "[v0];" +
+		"[2:v]trim=start=2.000000:end=3.500000,setpts=PTS-STARTPTS," + conform + "[v1];" +
+		"[v0][v1]concat=n=2:v=1:a=0[outv];" +
+		"[3:a:0][4:a:0]amix=inputs=2:duration=longest:normalize=0," +
+		"atrim=start=0.000000:end=3.500000,asetpts=PTS-STARTPTS[outa]"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+// studioRunner fakes a two-person recording: ffprobe describes every file as 20s long,
+// silencedetect reports each mic's pauses, and the render's arguments are recorded.
+// Like a killed process, it fails once ctx is done.
+type studioRunner struct {
+	silences map[string]string // mic file name -> silencedetect output
+	render   []string
+}
+
+func (r *studioRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if name == "ffprobe" {
+		io.WriteString(stdout, `{"streams": [
+			{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "r_frame_rate": "25/1", "duration": "20.0"},
+			{"index": 1, "codec_type": "audio", "codec_name": "aac", "duration": "20.0"}
+		], "format": {"duration": "20.0"}}`)
+		return nil
+	}
+	if strings.Contains(strings.Join(args, " "), "silencedetect") {
+		for i, arg := range args[:len(args)-1] {
+			if arg == "-i" {
+				io.WriteString(stderr, r.silences[filepath.Base(args[i+1])])
+			}
+		}
+		return nil
+	}
+	r.render = args
+	return nil
+}
+
+// studio returns the angles of a two-person recording faked by r.
+func studio(t *testing.T, r *studioRunner) []Angle {
+	t.Helper()
+	dir := t.TempDir()
+	open := func(name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+		return path
+	}
+	var angles []Angle
+	for _, host := range []string{"a", "b"} {
+		cam, err := video.New(open(host+".mp4"), video.WithRunner(r))
+		if err != nil {
+			t.Fatalf("video.New: %v", err)
+		}
+		mic, err := audio.New(open(host+".wav"), audio.WithRunner(r))
+		if err != nil {
+			t.Fatalf("audio.New: %v", err)
+		}
+		angles = append(angles, Angle{Video: cam, Mic: mic})
+	}
+	return angles
+}
+
+func TestEdit(t *testing.T) {
+	t.Parallel()
+
+	r := &studioRunner{silences: map[string]string{
+		// Host A talks for the first 10 seconds, host B for the rest
+		"a.wav": "silence_start: 10\nsilence_end: 20 | silence_duration: 10\n",
+		"b.wav": "silence_start: 0\nsilence_end: 10 | silence_duration: 10\n",
+	}}
+	angles := studio(t, r)
+
+	var last Progress
+	out := filepath.Join(t.TempDir(), "out", "program.mp4")
+	shots, err := Edit(out, angles, Config{OnProgress: func(p Progress) { last = p }}, WithRunner(r))
+	if err != nil {
+		t.Fatalf("Edit: %v", err)
+	}
+	assertShots(t, shots, []Shot{{0, 10, 0}, {10, 20, 1}})
+
+	args := strings.Join(r.render, " ")
+	for _, want := range []string{"-i " + angles[1].Video.Path(), "-i " + angles[0].Mic.Path(), "[1:v]trim=start=10.000000:end=20.000000", "-map [outv] -map [outa]"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("render args missing %q:\n%s", want, args)
+		}
+	}
+	if last.Stage != StageRender || last.Percent != 100 {
+		t.Errorf("last progress = %+v, want render at 100%%", last)
+	}
+}
+
+func TestPlanContext_Canceled(t *testing.T) {
+	t.Parallel()
+
+	r := &studioRunner{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	angles := studio(t, r)
+	// The cameras are probed with ctx too, before any mic is analysed
+	_, err := PlanContext(ctx, angles, Config{})
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "video info") {
+		t.Fatalf("expected the video info error wrapping context.Canceled, got %v", err)
+	}
+	if err := RenderContext(ctx, filepath.Join(t.TempDir(), "out.mp4"), angles, []Shot{{0, 20, 0}}, Config{}, WithRunner(r)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error wrapping context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package multicam
+
+import "github.com/meunomeebero/ffmpego/internal/ffutil"
+
+// Runner executes the ffmpeg command that renders an edit.
+type Runner = ffutil.Runner
+
+// Option configures how Render and Edit run ffmpeg. Detection on the mics and probing
+// the cameras use the options of each audio.Audio and video.Video.
+type Option func(*options)
+
+type options struct {
+	runner ffutil.Runner
+	ffmpeg string
+}
+
+// WithRunner runs ffmpeg through r instead of starting a local process. See
+// video.WithRunner.
+func WithRunner(r Runner) Option {
+	return func(o *options) {
+		if r != nil {
+			o.runner = r
+		}
+	}
+}
+
+// WithFFmpegPath sets the ffmpeg binary to use. Defaults to "ffmpeg".
+func WithFFmpegPath(path string) Option {
+	return func(o *options) {
+		if path != "" {
+			o.ffmpeg = path
+		}
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		runner: ffutil.ExecRunner{},
+		ffmpeg: ffutil.DefaultFFmpeg,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,174 @@
+package multicam
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// frame is the output picture every camera is scaled and padded to.
+type frame struct {
+	Width, Height int
+	FrameRate     string // fraction such as "30000/1001"; "" keeps each camera's own rate
+}
+
+// Edit plans the shots with Plan and renders them with Render. It returns the shots
+// it rendered.
+func Edit(outputPath string, angles []Angle, config Config, opts ...Option) ([]Shot, error) {
+	return EditContext(context.Background(), outputPath, angles, config, opts...)
+}
+
+// EditContext is like Edit but stops ffmpeg when ctx is cancelled or its deadline
+// passes.
+func EditContext(ctx context.Context, outputPath string, angles []Angle, config Config, opts ...Option) ([]Shot, error) {
+	shots, err := plan(ctx, angles, config, detectProgressEnd)
+	if err != nil {
+		return nil, err
+	}
+	tracker := ffutil.NewProgressTracker(config.OnProgress)
+	if err := render(ctx, outputPath, angles, shots, config, newOptions(opts), tracker, detectProgressEnd); err != nil {
+		return nil, err
+	}
+	return shots, nil
+}
+
+// Render writes the edit described by shots into outputPath in a single ffmpeg pass.
+// Each shot shows its camera, scaled and padded to the size and frame rate of the
+// first angle, and the sound is the mix of every mic. Shots must follow each other
+// without gaps, as Plan returns them. The output is encoded with the output format's
+// default encoders.
+func Render(outputPath string, angles []Angle, shots []Shot, config Config, opts ...Option) error {
+	return RenderContext(context.Background(), outputPath, angles, shots, config, opts...)
+}
+
+// RenderContext is like Render but stops ffmpeg when ctx is cancelled or its deadline
+// passes.
+func RenderContext(ctx context.Context, outputPath string, angles []Angle, shots []Shot, config Config, opts ...Option) error {
+	tracker := ffutil.NewProgressTracker(config.OnProgress)
+	return render(ctx, outputPath, angles, shots, config, newOptions(opts), tracker, 0)
+}
+
+// render runs Render, reporting progress through tracker from progressStart to 100.
+func render(ctx context.Context, outputPath string, angles []Angle, shots []Shot, config Config, o *options, tracker *ffutil.ProgressTracker, progressStart float64) error {
+	if _, err := programDuration(ctx, angles, config.Wide); err != nil {
+		return err
+	}
+	if err := validateShots(shots, len(angles), config.Wide != nil); err != nil {
+		return err
+	}
+
+	info, err := angles[0].Video.GetInfoContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get video info: %w", err)
+	}
+	out := frame{Width: info.Width, Height: info.Height}
+	if info.FrameRate > 0 {
+		// The float rate would round NTSC rates, so conform to the exact fraction.
+		// GetInfoContext has cached the probe.
+		probe, err := angles[0].Video.Probe()
+		if err != nil {
+			return err
+		}
+		out.FrameRate = probe.VideoStreams()[0].FrameRateFraction
+	}
+
+	var args []string
+	for _, angle := range angles {
+		args = append(args, "-i", angle.Video.Path())
+	}
+	if config.Wide != nil {
+		args = append(args, "-i", config.Wide.Path())
+	}
+	for _, angle := range angles {
+		args = append(args, "-i", angle.Mic.Path())
+	}
+
+	tempDir, err := os.MkdirTemp("", "ffmpego_multicam_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	graph := programFilter(shots, len(angles), config.Wide != nil, out)
+	filterArgs, err := ffutil.FilterComplexArgs(graph, tempDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	args = append(args, filterArgs...)
+	args = append(args, "-map", "[outv]", "-map", "[outa]", "-y", outputPath)
+
+	duration := shots[len(shots)-1].EndTime - shots[0].StartTime
+	stage := tracker.Stage(StageRender, progressStart, 100, duration, 1)
+	if _, err := ffutil.RunWithProgress(ctx, o.runner, o.ffmpeg, args, stage.Reporter(0)); err != nil {
+		return fmt.Errorf("failed to render: %w", err)
+	}
+	stage.Done()
+	return nil
+}
+
+// validateShots checks that shots show known angles and follow each other.
+func validateShots(shots []Shot, angles int, withWide bool) error {
+	if len(shots) == 0 {
+		return fmt.Errorf("no shots to render")
+	}
+	for i, shot := range shots {
+		switch {
+		case shot.Angle == Wide && !withWide:
+			return fmt.Errorf("shot %d shows the wide shot, but Config.Wide is not set", i+1)
+		case shot.Angle != Wide && (shot.Angle < 0 || shot.Angle >= angles):
+			return fmt.Errorf("shot %d shows angle %d, but there are %d angles", i+1, shot.Angle, angles)
+		case shot.EndTime <= shot.StartTime:
+			return fmt.Errorf("shot %d ends at %.3fs, before it starts at %.3fs", i+1, shot.EndTime, shot.StartTime)
+		case i > 0 && math.Abs(shot.StartTime-shots[i-1].EndTime) > 1e-6:
+			return fmt.Errorf("shot %d starts at %.3fs, not where shot %d ends", i+1, shot.StartTime, i)
+		}
+	}
+	return nil
+}
+
+// programFilter builds the filter_complex graph of an edit. Inputs are the angles'
+// cameras, then the wide camera if withWide, then the angles' mics. Each shot is
+// trimmed out of its camera and conformed to out, and the shots are concatenated into
+// [outv]; the first audio stream of every mic is mixed at full level into [outa].
+func programFilter(shots []Shot, angles int, withWide bool, out frame) string {
+	var b strings.Builder
+	var pads strings.Builder
+
+	conform := fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1",
+		out.Width, out.Height, out.Width, out.Height)
+	if out.FrameRate != "" {
+		conform += ",fps=" + out.FrameRate
+	}
+	for i, shot := range shots {
+		input := shot.Angle
+		if shot.Angle == Wide {
+			input = angles
+		}
+		fmt.Fprintf(&b, "[%d:v]trim=start=%.6f:end=%.6f,setpts=PTS-STARTPTS,%s[v%d];",
+			input, shot.StartTime, shot.EndTime, conform, i)
+		fmt.Fprintf(&pads, "[v%d]", i)
+	}
+	fmt.Fprintf(&b, "%sconcat=n=%d:v=1:a=0[outv];", pads.String(), len(shots))
+
+	firstMic := angles
+	if withWide {
+		firstMic++
+	}
+	for i := 0; i < angles; i++ {
+		fmt.Fprintf(&b, "[%d:a:0]", firstMic+i)
+	}
+	if angles > 1 {
+		fmt.Fprintf(&b, "amix=inputs=%d:duration=longest:normalize=0,", angles)
+	}
+	fmt.Fprintf(&b, "atrim=start=%.6f:end=%.6f,asetpts=PTS-STARTPTS[outa]",
+		shots[0].StartTime, shots[len(shots)-1].EndTime)
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package subtitles
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// parseSRT reads SubRip cues. Each block is an optional sequence number, a timing
+// line and the text.
+func parseSRT(lines []string) (*File, error) {
+	f := &File{Format: FormatSRT}
+	for _, block := range blocks(lines) {
+		var id string
+		if !strings.Contains(block[0], "-->") {
+			id = strings.TrimSpace(block[0])
+			block = block[1:]
+		}
+		if len(block) == 0 {
+			return nil, fmt.Errorf("cue %q has no timing line", id)
+		}
+		start, end, _, err := parseTiming(block[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cue %q: %w", id, err)
+		}
+		f.Cues = append(f.Cues, Cue{
+			ID:    id,
+			Start: start,
+			End:   end,
+			Text:  strings.Join(block[1:], "\n"),
+		})
+	}
+	return f, nil
+}
+
+// writeSRT writes cues as SubRip, numbering them from 1.
+func writeSRT(w io.Writer, cues []Cue) error {
+	bw := bufio.NewWriter(w)
+	for i, cue := range cues {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		fmt.Fprintf(bw, "%d\n%s --> %s\n", i+1, formatTimestamp(cue.Start, ","), formatTimestamp(cue.End, ","))
+		if cue.Text != "" {
+			bw.WriteString(cue.Text + "\n")
+		}
+	}
+	return bw.Flush()
+}
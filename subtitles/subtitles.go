@@ -0,0 +1,274 @@
+// Package subtitles reads and writes SRT and WebVTT caption files and retimes them
+// to match an edit, such as the output of RemoveSilence. It is pure Go and never
+// runs ffmpeg.
+package subtitles
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// Segment is a kept time range of the source, in seconds.
+type Segment = ffutil.Segment
+
+// Timeline describes an edit, as returned by RemoveSilenceWithTimeline.
+type Timeline = ffutil.Timeline
+
+// TimelineSegment is one kept source range of a Timeline.
+type TimelineSegment = ffutil.TimelineSegment
+
+// Format is a subtitle file format.
+type Format string
+
+const (
+	FormatSRT Format = "srt" // SubRip
+	FormatVTT Format = "vtt" // WebVTT
+)
+
+// Cue is one caption: text shown from Start to End, in seconds.
+type Cue struct {
+	ID       string // SRT sequence number or WebVTT identifier; may be empty
+	Start    float64
+	End      float64
+	Text     string // Payload, lines separated by "\n"
+	Settings string // WebVTT cue settings, e.g. "align:start line:0"; empty for SRT
+}
+
+// File is a parsed subtitle file.
+type File struct {
+	Format Format
+	// Header is the WebVTT header: the "WEBVTT" line and any lines up to the first
+	// blank line. Empty for SRT; written as plain "WEBVTT" when empty.
+	Header string
+	// Blocks holds WebVTT STYLE and REGION blocks verbatim. NOTE blocks are dropped.
+	Blocks []string
+	Cues   []Cue
+}
+
+// FormatFromPath picks the format from a file extension: .srt or .vtt.
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".srt":
+		return FormatSRT, nil
+	case ".vtt":
+		return FormatVTT, nil
+	default:
+		return "", fmt.Errorf("unsupported subtitle format: %s (use .srt or .vtt)", path)
+	}
+}
+
+// Parse reads an SRT or WebVTT file from r. Files starting with "WEBVTT" are read
+// as WebVTT, everything else as SRT.
+func Parse(r io.Reader) (*File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subtitles: %w", err)
+	}
+	lines := splitLines(data)
+	if len(lines) > 0 && isVTTSignature(lines[0]) {
+		return parseVTT(lines)
+	}
+	return parseSRT(lines)
+}
+
+// ReadFile parses the subtitle file at path.
+func ReadFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open subtitles: %w", err)
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Write writes the file to w in f.Format.
+func (f *File) Write(w io.Writer) error {
+	switch f.Format {
+	case FormatSRT:
+		return writeSRT(w, f.Cues)
+	case FormatVTT:
+		return writeVTT(w, f)
+	default:
+		return fmt.Errorf("unsupported subtitle format: %q", f.Format)
+	}
+}
+
+// WriteFile writes the file to path, in the format given by its extension.
+func (f *File) WriteFile(path string) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	out := *f
+	out.Format = format
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create subtitle file: %w", err)
+	}
+	if err := out.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write subtitle file: %w", err)
+	}
+	return nil
+}
+
+// RetimeOptions controls how cues are retimed.
+type RetimeOptions struct {
+	// MergeSplit joins the parts of a cue that spans a cut back into one cue. The
+	// parts are adjacent in the output, so the merged cue covers them exactly.
+	// Without it each part becomes its own cue with the same text.
+	MergeSplit bool
+	// MinDuration drops cue parts shorter than this many seconds, such as the last
+	// few frames of a cue that ends just after a cut.
+	MinDuration float64
+}
+
+// Retime returns a copy of f with its cues moved onto the output of an edit that kept
+// segments of the source, in source order. See RetimeCues.
+func (f *File) Retime(kept []Segment, opts RetimeOptions) *File {
+	out := *f
+	out.Blocks = append([]string(nil), f.Blocks...)
+	out.Cues = RetimeCues(f.Cues, kept, opts)
+	return &out
+}
+
+// RetimeCues moves cues onto the output of an edit that kept segments of the source,
+// in source order. Cues inside kept segments are shifted, cues that span a cut are
+// split (or merged, with opts.MergeSplit) and cues entirely inside removed ranges are
+// dropped.
+func RetimeCues(cues []Cue, kept []Segment, opts RetimeOptions) []Cue {
+	kept = ffutil.NormalizeSegments(kept, 0)
+	return RetimeCuesTimeline(cues, ffutil.NewTimeline(ffutil.SegmentClips(kept), 0), opts)
+}
+
+// RetimeCuesTimeline is like RetimeCues but follows a Timeline, which also covers
+// sped-up edits: cue parts inside a sped-up range are shortened by its speed.
+func RetimeCuesTimeline(cues []Cue, timeline *Timeline, opts RetimeOptions) []Cue {
+	var out []Cue
+	for _, cue := range cues {
+		var parts []Cue
+		for _, seg := range timeline.Segments {
+			from, to := max(cue.Start, seg.SourceStart), min(cue.End, seg.SourceEnd)
+			if to <= from {
+				continue
+			}
+			speed := seg.Speed
+			if speed <= 0 {
+				speed = 1
+			}
+			part := cue
+			part.Start = seg.OutputStart + (from-seg.SourceStart)/speed
+			part.End = seg.OutputStart + (to-seg.SourceStart)/speed
+			if part.End-part.Start >= opts.MinDuration {
+				parts = append(parts, part)
+			}
+		}
+
+		if len(parts) > 1 && opts.MergeSplit {
+			parts[0].End = parts[len(parts)-1].End
+			parts = parts[:1]
+		}
+		for i := 1; i < len(parts); i++ {
+			if parts[i].ID != "" {
+				parts[i].ID = fmt.Sprintf("%s-%d", parts[i].ID, i+1)
+			}
+		}
+		out = append(out, parts...)
+	}
+	return out
+}
+
+// splitLines splits data into lines, dropping a UTF-8 byte order mark and the
+// carriage returns of CRLF line endings.
+func splitLines(data []byte) []string {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	return lines
+}
+
+// blocks groups lines into runs separated by blank lines.
+func blocks(lines []string) [][]string {
+	var out [][]string
+	var current []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				out = append(out, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		out = append(out, current)
+	}
+	return out
+}
+
+// parseTiming parses a "start --> end [settings]" line.
+func parseTiming(line string) (start, end float64, settings string, err error) {
+	left, right, ok := strings.Cut(line, "-->")
+	if !ok {
+		return 0, 0, "", fmt.Errorf("missing --> in timing line %q", line)
+	}
+	start, err = parseTimestamp(strings.TrimSpace(left))
+	if err != nil {
+		return 0, 0, "", err
+	}
+	fields := strings.Fields(right)
+	if len(fields) == 0 {
+		return 0, 0, "", fmt.Errorf("missing end time in timing line %q", line)
+	}
+	end, err = parseTimestamp(fields[0])
+	if err != nil {
+		return 0, 0, "", err
+	}
+	return start, end, strings.Join(fields[1:], " "), nil
+}
+
+// parseTimestamp parses [hh:]mm:ss.ttt, accepting "," as the decimal separator.
+func parseTimestamp(s string) (float64, error) {
+	parts := strings.Split(strings.Replace(s, ",", ".", 1), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	var total float64
+	for i, p := range parts {
+		var v float64
+		if _, err := fmt.Sscanf(p, "%g", &v); err != nil || v < 0 || strings.ContainsAny(p, "+-eE") {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		if i < len(parts)-1 && v != math.Trunc(v) {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		total = total*60 + v
+	}
+	return total, nil
+}
+
+// formatTimestamp formats seconds as hh:mm:ss followed by sep and milliseconds.
+func formatTimestamp(t float64, sep string) string {
+	ms := int64(math.Round(max(t, 0) * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
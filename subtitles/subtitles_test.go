@@ -0,0 +1,224 @@
+package subtitles
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSRT = "\xef\xbb\xbf1\r\n00:00:01,000 --> 00:00:02,500\r\nHello\r\nworld\r\n\r\n" +
+	"2\r\n00:00:03.000 --> 00:00:06,000\r\nAcross the cut\r\n\r\n" +
+	"3\r\n00:00:04,200 --> 00:00:04,800\r\nGone\r\n"
+
+const testVTT = `WEBVTT - captions
+
+STYLE
+::cue { color: yellow }
+
+NOTE this is dropped
+
+intro
+00:01.000 --> 00:02.500 align:start line:0
+Hello
+world
+
+00:00:03.000 --> 00:00:06.000
+Across the cut
+`
+
+func assertTime(t *testing.T, got, want float64, name string) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestParseSRT(t *testing.T) {
+	f, err := Parse(strings.NewReader(testSRT))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if f.Format != FormatSRT {
+		t.Errorf("Format = %q, want srt", f.Format)
+	}
+	if len(f.Cues) != 3 {
+		t.Fatalf("got %d cues, want 3", len(f.Cues))
+	}
+	c := f.Cues[0]
+	if c.ID != "1" || c.Text != "Hello\nworld" {
+		t.Errorf("cue 0 = %+v", c)
+	}
+	assertTime(t, c.Start, 1, "cue 0 start")
+	assertTime(t, c.End, 2.5, "cue 0 end")
+	assertTime(t, f.Cues[1].Start, 3, "cue 1 start (dot separator)")
+}
+
+func TestParseVTT(t *testing.T) {
+	f, err := Parse(strings.NewReader(testVTT))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if f.Format != FormatVTT || f.Header != "WEBVTT - captions" {
+		t.Errorf("Format = %q, Header = %q", f.Format, f.Header)
+	}
+	if len(f.Blocks) != 1 || !strings.HasPrefix(f.Blocks[0], "STYLE\n") {
+		t.Errorf("Blocks = %q, want the STYLE block", f.Blocks)
+	}
+	if len(f.Cues) != 2 {
+		t.Fatalf("got %d cues, want 2", len(f.Cues))
+	}
+	c := f.Cues[0]
+	if c.ID != "intro" || c.Settings != "align:start line:0" || c.Text != "Hello\nworld" {
+		t.Errorf("cue 0 = %+v", c)
+	}
+	assertTime(t, c.Start, 1, "cue 0 start (mm:ss)")
+	assertTime(t, f.Cues[1].End, 6, "cue 1 end")
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"1\n00:00:01,000 00:00:02,000\nno arrow\n",
+		"1\n00:00:xx,000 --> 00:00:02,000\nbad time\n",
+		"WEBVTT\n\n00:01.000 -->\ntext\n",
+	} {
+		if _, err := Parse(strings.NewReader(in)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", in)
+		}
+	}
+}
+
+func TestWrite_RoundTrip(t *testing.T) {
+	for _, in := range []string{testSRT, testVTT} {
+		f, err := Parse(strings.NewReader(in))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := f.Write(&buf); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		again, err := Parse(&buf)
+		if err != nil {
+			t.Fatalf("Parse written %s: %v", f.Format, err)
+		}
+		if len(again.Cues) != len(f.Cues) || again.Header != f.Header || len(again.Blocks) != len(f.Blocks) {
+			t.Fatalf("round trip changed the file:\n%+v\n%+v", f, again)
+		}
+		for i := range f.Cues {
+			if again.Cues[i] != f.Cues[i] {
+				t.Errorf("cue %d = %+v, want %+v", i, again.Cues[i], f.Cues[i])
+			}
+		}
+	}
+}
+
+func TestWriteSRT(t *testing.T) {
+	f := &File{Format: FormatSRT, Cues: []Cue{
+		{ID: "a", Start: 0.5, End: 3661.25, Text: "One"},
+		{Start: 4000, End: 4001.0004, Text: "Two"},
+	}}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	want := "1\n00:00:00,500 --> 01:01:01,250\nOne\n\n2\n01:06:40,000 --> 01:06:41,000\nTwo\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRetimeCues(t *testing.T) {
+	f, err := Parse(strings.NewReader(testSRT))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	// Remove 2-4 and 5-5.5: cue 1 spans both cuts, cue 3 sits between them
+	kept := []Segment{{StartTime: 0, EndTime: 2}, {StartTime: 4, EndTime: 5}, {StartTime: 5.5, EndTime: 10}}
+
+	got := RetimeCues(f.Cues, kept, RetimeOptions{})
+	if len(got) != 4 {
+		t.Fatalf("got %d cues, want 4: %+v", len(got), got)
+	}
+	// Cue 0 spans the first cut too: 1-2 stays, 2-2.5 is removed
+	assertTime(t, got[0].Start, 1, "cue 0 start")
+	assertTime(t, got[0].End, 2, "cue 0 end")
+	// Cue 1 (3-6) keeps 4-5 and 5.5-6
+	if got[1].ID != "2" || got[2].ID != "2-2" || got[2].Text != "Across the cut" {
+		t.Errorf("split parts = %+v, %+v", got[1], got[2])
+	}
+	assertTime(t, got[1].Start, 2, "part 1 start")
+	assertTime(t, got[1].End, 3, "part 1 end")
+	assertTime(t, got[2].Start, 3, "part 2 start")
+	assertTime(t, got[2].End, 3.5, "part 2 end")
+	// Cue 2 (4.2-4.8) is inside a kept segment and only shifted
+	assertTime(t, got[3].Start, 2.2, "shifted start")
+	assertTime(t, got[3].End, 2.8, "shifted end")
+}
+
+func TestRetimeCues_MergeAndDrop(t *testing.T) {
+	cues := []Cue{
+		{ID: "1", Start: 3, End: 6, Text: "Across"},
+		{ID: "2", Start: 2.2, End: 3.8, Text: "Removed"},
+		{ID: "3", Start: 1.5, End: 4.05, Text: "Short tail"},
+	}
+	kept := []Segment{{StartTime: 0, EndTime: 2}, {StartTime: 4, EndTime: 5}, {StartTime: 5.5, EndTime: 10}}
+
+	got := RetimeCues(cues, kept, RetimeOptions{MergeSplit: true, MinDuration: 0.1})
+	if len(got) != 2 {
+		t.Fatalf("got %d cues, want 2: %+v", len(got), got)
+	}
+	if got[0].ID != "1" {
+		t.Errorf("merged ID = %q, want 1", got[0].ID)
+	}
+	assertTime(t, got[0].Start, 2, "merged start")
+	assertTime(t, got[0].End, 3.5, "merged end")
+	// The 0.05s part after the cut is below MinDuration
+	assertTime(t, got[1].Start, 1.5, "trimmed start")
+	assertTime(t, got[1].End, 2, "trimmed end")
+}
+
+func TestRetimeCuesTimeline_SpeedUp(t *testing.T) {
+	timeline := &Timeline{Segments: []TimelineSegment{
+		{SourceStart: 0, SourceEnd: 2, OutputStart: 0},
+		{SourceStart: 2, SourceEnd: 6, OutputStart: 2, Speed: 4},
+	}}
+	got := RetimeCuesTimeline([]Cue{{Start: 1, End: 5}}, timeline, RetimeOptions{MergeSplit: true})
+	if len(got) != 1 {
+		t.Fatalf("got %d cues, want 1", len(got))
+	}
+	assertTime(t, got[0].Start, 1, "start")
+	assertTime(t, got[0].End, 2.75, "end")
+}
+
+func TestWriteFile(t *testing.T) {
+	f, err := Parse(strings.NewReader(testSRT))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "sub", "out.vtt")
+	if err := f.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "WEBVTT\n\n1\n00:00:01.000 --> 00:00:02.500\n") {
+		t.Errorf("unexpected output:\n%s", data)
+	}
+
+	got, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if got.Format != FormatVTT || len(got.Cues) != 3 {
+		t.Errorf("ReadFile = %s with %d cues", got.Format, len(got.Cues))
+	}
+
+	if err := f.WriteFile(filepath.Join(t.TempDir(), "out.ass")); err == nil {
+		t.Error("WriteFile(.ass) succeeded, want error")
+	}
+}
@@ -0,0 +1,82 @@
+package subtitles
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// isVTTSignature reports whether line is a WebVTT file signature: "WEBVTT" alone or
+// followed by a space or tab and free text.
+func isVTTSignature(line string) bool {
+	rest, ok := strings.CutPrefix(line, "WEBVTT")
+	return ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t')
+}
+
+// parseVTT reads WebVTT cues. lines[0] must be the signature.
+func parseVTT(lines []string) (*File, error) {
+	f := &File{Format: FormatVTT}
+	all := blocks(lines)
+	f.Header = strings.Join(all[0], "\n")
+
+	for _, block := range all[1:] {
+		first := block[0]
+		switch {
+		case first == "NOTE" || strings.HasPrefix(first, "NOTE ") || strings.HasPrefix(first, "NOTE\t"):
+			continue
+		case (first == "STYLE" || first == "REGION") && len(f.Cues) == 0:
+			f.Blocks = append(f.Blocks, strings.Join(block, "\n"))
+			continue
+		}
+
+		var id string
+		if !strings.Contains(first, "-->") {
+			id = first
+			block = block[1:]
+		}
+		if len(block) == 0 {
+			return nil, fmt.Errorf("cue %q has no timing line", id)
+		}
+		start, end, settings, err := parseTiming(block[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cue %q: %w", id, err)
+		}
+		f.Cues = append(f.Cues, Cue{
+			ID:       id,
+			Start:    start,
+			End:      end,
+			Text:     strings.Join(block[1:], "\n"),
+			Settings: settings,
+		})
+	}
+	return f, nil
+}
+
+// writeVTT writes f as WebVTT.
+func writeVTT(w io.Writer, f *File) error {
+	bw := bufio.NewWriter(w)
+	header := f.Header
+	if !isVTTSignature(strings.SplitN(header, "\n", 2)[0]) {
+		header = "WEBVTT"
+	}
+	bw.WriteString(header + "\n")
+	for _, block := range f.Blocks {
+		bw.WriteString("\n" + block + "\n")
+	}
+	for _, cue := range f.Cues {
+		bw.WriteString("\n")
+		if cue.ID != "" {
+			bw.WriteString(cue.ID + "\n")
+		}
+		fmt.Fprintf(bw, "%s --> %s", formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."))
+		if cue.Settings != "" {
+			bw.WriteString(" " + cue.Settings)
+		}
+		bw.WriteString("\n")
+		if cue.Text != "" {
+			bw.WriteString(cue.Text + "\n")
+		}
+	}
+	return bw.Flush()
+}
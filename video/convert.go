@@ -1,11 +1,13 @@
 package video
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
 )
 
 // ConvertConfig contains configuration for video conversion
@@ -38,6 +40,9 @@ type ConvertConfig struct {
 
 	// Bitrate in kbps (e.g., 5000 for 5 Mbps)
 	Bitrate int
+
+	// OnProgress, if set, receives progress updates while ffmpeg runs
+	OnProgress ProgressFunc
 }
 
 // AspectRatio represents common aspect ratios
@@ -83,13 +88,25 @@ const (
 	PresetVeryslow  = "veryslow"
 )
 
-// Convert converts the video according to the configuration
+// Convert converts the video according to the configuration.
+// Returns an error wrapping ErrEncoderNotAvailable, before encoding starts, if the
+// requested codecs are not compiled into ffmpeg.
 func (v *Video) Convert(outputPath string, config ConvertConfig) error {
-	info, err := v.GetInfo()
+	return v.ConvertContext(context.Background(), outputPath, config)
+}
+
+// ConvertContext is like Convert but stops ffmpeg when ctx is cancelled or its
+// deadline passes. The returned error then wraps ctx.Err().
+func (v *Video) ConvertContext(ctx context.Context, outputPath string, config ConvertConfig) error {
+	info, err := v.getInfo(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get video info: %w", err)
 	}
 
+	if err := v.requireEncoders(ctx, config.videoCodec(), config.audioCodec()); err != nil {
+		return err
+	}
+
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
@@ -98,11 +115,12 @@ func (v *Video) Convert(outputPath string, config ConvertConfig) error {
 	args = append(args, buildConvertArgs(info, &config)...)
 	args = append(args, "-y", outputPath)
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
+	stage := ffutil.NewProgressTracker(config.OnProgress).Stage(StageConvert, 0, 100, info.Duration, 1)
+	_, err = ffutil.RunWithProgress(ctx, v.runner, v.ffmpeg, args, stage.Reporter(0))
 	if err != nil {
-		return fmt.Errorf("FFmpeg error: %w - %s", err, string(output))
+		return fmt.Errorf("failed to convert: %w", err)
 	}
+	stage.Done()
 	return nil
 }
 
@@ -122,17 +140,8 @@ func buildConvertArgs(info *Info, config *ConvertConfig) []string {
 		args = append(args, "-r", fmt.Sprintf("%.3f", config.FrameRate))
 	}
 
-	videoCodec := config.VideoCodec
-	if videoCodec == "" {
-		videoCodec = CodecH264
-	}
-	args = append(args, "-c:v", videoCodec)
-
-	audioCodec := config.AudioCodec
-	if audioCodec == "" {
-		audioCodec = CodecAAC
-	}
-	args = append(args, "-c:a", audioCodec)
+	args = append(args, "-c:v", config.videoCodec())
+	args = append(args, "-c:a", config.audioCodec())
 
 	quality := config.Quality
 	if quality == 0 {
@@ -157,6 +166,22 @@ func buildConvertArgs(info *Info, config *ConvertConfig) []string {
 	return args
 }
 
+// videoCodec returns the configured video encoder, defaulting to H.264.
+func (c *ConvertConfig) videoCodec() string {
+	if c.VideoCodec == "" {
+		return CodecH264
+	}
+	return c.VideoCodec
+}
+
+// audioCodec returns the configured audio encoder, defaulting to AAC.
+func (c *ConvertConfig) audioCodec() string {
+	if c.AudioCodec == "" {
+		return CodecAAC
+	}
+	return c.AudioCodec
+}
+
 // roundEven rounds n to the nearest even number (required by most video codecs).
 func roundEven(n int) int {
 	return (n + 1) &^ 1
@@ -7,6 +7,7 @@ import (
 )
 
 func TestConvert_BasicCodec(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -30,6 +31,7 @@ func TestConvert_BasicCodec(t *testing.T) {
 }
 
 func TestConvert_AspectRatio(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -78,6 +80,7 @@ func TestConvert_AspectRatio(t *testing.T) {
 }
 
 func TestConvert_OutputDirCreated(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -98,3 +101,39 @@ func TestConvert_OutputDirCreated(t *testing.T) {
 
 	assertValidMedia(t, out)
 }
+
+func TestConvert_ReportsProgress(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var last Progress
+	calls := 0
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	config := ConvertConfig{
+		VideoCodec: CodecH264,
+		Quality:    28,
+		Preset:     PresetUltrafast,
+		OnProgress: func(p Progress) {
+			calls++
+			last = p
+		},
+	}
+	if err := v.Convert(out, config); err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+
+	if calls == 0 {
+		t.Fatal("expected progress updates, got none")
+	}
+	if last.Stage != StageConvert {
+		t.Errorf("Stage = %q, want %q", last.Stage, StageConvert)
+	}
+	if last.Percent != 100 {
+		t.Errorf("final Percent = %.1f, want 100", last.Percent)
+	}
+}
@@ -1,10 +1,9 @@
 package video
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"strconv"
-	"strings"
 )
 
 // Info contains information about a video file
@@ -15,57 +14,61 @@ type Info struct {
 	FrameRate     float64
 	VideoCodec    string
 	AudioCodec    string
+	SampleRate    int // Audio sample rate in Hz
+	AudioChannels int
 	PixelFormat   string
+	BitRate       int64 // Overall bit rate of the file in bits per second
+	Rotation      int   // Display rotation in degrees
 	FileSizeBytes int64
 }
 
 // GetInfo retrieves information about the video file.
 // Results are cached — subsequent calls return the cached value without invoking ffprobe.
+// Video fields describe the first video stream that is not an attached picture, and
+// the audio fields the first audio stream; use Probe to inspect every stream.
 func (v *Video) GetInfo() (*Info, error) {
+	return v.GetInfoContext(context.Background())
+}
+
+// GetInfoContext is like GetInfo but stops ffprobe when ctx is cancelled or its
+// deadline passes.
+func (v *Video) GetInfoContext(ctx context.Context) (*Info, error) {
+	return v.getInfo(ctx)
+}
+
+func (v *Video) getInfo(ctx context.Context) (*Info, error) {
 	if v.info != nil {
 		copy := *v.info
 		return &copy, nil
 	}
 
-	output, err := ffprobeVideo(v.path)
+	probe, err := v.probe(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get video info: %w", err)
 	}
 
-	info := &Info{}
-	lines := strings.Split(string(output), "\n")
+	info := &Info{
+		Duration: probe.Format.Duration,
+		BitRate:  probe.Format.BitRate,
+	}
 
-	for _, line := range lines {
-		if strings.HasPrefix(line, "width=") {
-			info.Width, _ = strconv.Atoi(strings.TrimPrefix(line, "width="))
-		} else if strings.HasPrefix(line, "height=") {
-			info.Height, _ = strconv.Atoi(strings.TrimPrefix(line, "height="))
-		} else if strings.HasPrefix(line, "r_frame_rate=") {
-			frParts := strings.Split(strings.TrimPrefix(line, "r_frame_rate="), "/")
-			if len(frParts) == 2 {
-				num, _ := strconv.ParseFloat(frParts[0], 64)
-				den, _ := strconv.ParseFloat(frParts[1], 64)
-				if den > 0 {
-					info.FrameRate = num / den
-				}
-			}
-		} else if strings.HasPrefix(line, "codec_name=") {
-			info.VideoCodec = strings.TrimPrefix(line, "codec_name=")
-		} else if strings.HasPrefix(line, "duration=") {
-			info.Duration, _ = strconv.ParseFloat(strings.TrimPrefix(line, "duration="), 64)
-		} else if strings.HasPrefix(line, "pix_fmt=") {
-			info.PixelFormat = strings.TrimPrefix(line, "pix_fmt=")
+	if streams := probe.VideoStreams(); len(streams) > 0 {
+		s := streams[0]
+		info.Width = s.Width
+		info.Height = s.Height
+		info.FrameRate = s.FrameRate
+		info.VideoCodec = s.CodecName
+		info.PixelFormat = s.PixelFormat
+		info.Rotation = s.Rotation
+		if info.Duration == 0 {
+			info.Duration = s.Duration
 		}
 	}
 
-	audioOutput, err := ffprobeAudioStream(v.path)
-	if err == nil {
-		for _, line := range strings.Split(string(audioOutput), "\n") {
-			if strings.HasPrefix(line, "codec_name=") {
-				info.AudioCodec = strings.TrimPrefix(line, "codec_name=")
-				break
-			}
-		}
+	if streams := probe.AudioStreams(); len(streams) > 0 {
+		info.AudioCodec = streams[0].CodecName
+		info.SampleRate = streams[0].SampleRate
+		info.AudioChannels = streams[0].Channels
 	}
 
 	fileInfo, err := os.Stat(v.path)
@@ -1,11 +1,14 @@
 package video
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestNew_ValidFile(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	path := fixture("no-silence.mp4")
@@ -28,6 +31,7 @@ func TestNew_FileNotFound(t *testing.T) {
 }
 
 func TestNew_PermissionDenied(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	if os.Getuid() == 0 {
@@ -65,6 +69,7 @@ func TestNew_PermissionDenied(t *testing.T) {
 }
 
 func TestGetInfo_ReturnsCorrectMetadata(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -114,6 +119,7 @@ func TestGetInfo_ReturnsCorrectMetadata(t *testing.T) {
 }
 
 func TestGetInfo_Cached(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -137,6 +143,7 @@ func TestGetInfo_Cached(t *testing.T) {
 }
 
 func TestGetInfo_CopyNotShared(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -168,3 +175,63 @@ func TestGetInfo_CopyNotShared(t *testing.T) {
 			info2.Width, originalWidth)
 	}
 }
+
+func TestGetInfo_CorruptFile_FFmpegError(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "corrupt.mp4")
+	if err := os.WriteFile(path, []byte("definitely not media"), 0644); err != nil {
+		t.Fatalf("failed to write corrupt file: %v", err)
+	}
+
+	v, err := New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	_, err = v.GetInfo()
+	var ffErr *FFmpegError
+	if !errors.As(err, &ffErr) {
+		t.Fatalf("expected *FFmpegError, got %T: %v", err, err)
+	}
+	if ffErr.Binary != "ffprobe" {
+		t.Errorf("Binary = %q, want ffprobe", ffErr.Binary)
+	}
+	if ffErr.Kind != ErrorKindInvalidData {
+		t.Errorf("Kind = %q, want %q (stderr: %s)", ffErr.Kind, ErrorKindInvalidData, ffErr.Stderr)
+	}
+	if ffErr.ExitCode == 0 {
+		t.Error("ExitCode should be non-zero")
+	}
+}
+
+func TestProbe_DescribesStreamsAndFormat(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	probe, err := v.Probe()
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+
+	videoStreams := probe.VideoStreams()
+	if len(videoStreams) != 1 {
+		t.Fatalf("got %d video streams, want 1", len(videoStreams))
+	}
+	if videoStreams[0].CodecName != "h264" || videoStreams[0].Width != 320 {
+		t.Errorf("video stream = %s %dx%d, want h264 320x240",
+			videoStreams[0].CodecName, videoStreams[0].Width, videoStreams[0].Height)
+	}
+	if len(probe.AudioStreams()) != 1 {
+		t.Errorf("got %d audio streams, want 1", len(probe.AudioStreams()))
+	}
+	if probe.Format.Duration <= 0 || probe.Format.Size <= 0 {
+		t.Errorf("Format = %+v, want positive duration and size", probe.Format)
+	}
+}
@@ -0,0 +1,252 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// CutMethod selects how kept segments are cut out of the source and joined.
+type CutMethod string
+
+const (
+	// CutMethodCopy extracts segments in parallel and joins them without
+	// re-encoding video (default). Fast, but video cuts snap to the previous keyframe.
+	CutMethodCopy CutMethod = "copy"
+	// CutMethodAccurate renders the whole edit in one ffmpeg pass with trim/concat
+	// filters. Cuts are frame-exact and audio stays in sync across many segments, but
+	// everything is re-encoded with the output format's default encoders. Text
+	// subtitles are converted to the output's text format and retimed; sources with
+	// bitmap subtitles are rejected.
+	CutMethodAccurate CutMethod = "accurate"
+	// CutMethodSmart is frame-exact like CutMethodAccurate but re-encodes only the
+	// frames between each cut point and the next keyframe, matching the source's
+	// codec, profile, level, pixel format and color tags, and stream-copies the rest.
+	// Close to copy speed for long recordings. Supports H.264 and HEVC video.
+	//
+	// Other encoder settings, such as the number of reference frames, are not matched.
+	// Strict decoders may reject the join when the source used settings the
+	// re-encoded frames' SPS doesn't share; use CutMethodAccurate for those.
+	//
+	// Keyframes that start an open GOP (HEVC CRA pictures, H.264 recovery points)
+	// can't start a copied part, so with open-GOP sources more is re-encoded, up to
+	// whole segments when every keyframe is open.
+	CutMethodSmart CutMethod = "smart"
+)
+
+// validate reports an unknown cut method.
+func (m CutMethod) validate() error {
+	switch m {
+	case "", CutMethodCopy, CutMethodAccurate, CutMethodSmart:
+		return nil
+	default:
+		return fmt.Errorf("unknown cut method %q", m)
+	}
+}
+
+// CutConfig contains configuration for KeepSegments and RemoveSegments
+type CutConfig struct {
+	// Method selects how segments are cut and joined (default: CutMethodCopy).
+	Method CutMethod
+
+	// OnProgress, if set, receives progress updates while extracting and joining segments.
+	OnProgress ProgressFunc
+}
+
+// KeepSegments writes a new video made of the given segments of this one, in source
+// order. Segments are clamped to the file, and overlapping ones are merged.
+// Works like RemoveSilence: segments are extracted in parallel, video streams are
+// copied without re-encoding, and audio gets a short fade at each cut point.
+func (v *Video) KeepSegments(outputPath string, segments []Segment, config CutConfig) error {
+	return v.KeepSegmentsContext(context.Background(), outputPath, segments, config)
+}
+
+// KeepSegmentsContext is like KeepSegments but can be cancelled through ctx, with the
+// same cleanup as RemoveSilenceContext.
+func (v *Video) KeepSegmentsContext(ctx context.Context, outputPath string, segments []Segment, config CutConfig) error {
+	if err := config.Method.validate(); err != nil {
+		return err
+	}
+	info, err := v.getInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get video info: %w", err)
+	}
+	segments = ffutil.NormalizeSegments(segments, info.Duration)
+	if len(segments) == 0 {
+		return fmt.Errorf("no segments to keep")
+	}
+	return v.keepSegments(ctx, outputPath, segments, config.Method, ffutil.NewProgressTracker(config.OnProgress), 0)
+}
+
+// RemoveSegments writes a new video without the given segments, keeping everything
+// else. It is the inverse of KeepSegments.
+func (v *Video) RemoveSegments(outputPath string, segments []Segment, config CutConfig) error {
+	return v.RemoveSegmentsContext(context.Background(), outputPath, segments, config)
+}
+
+// RemoveSegmentsContext is like RemoveSegments but can be cancelled through ctx.
+func (v *Video) RemoveSegmentsContext(ctx context.Context, outputPath string, segments []Segment, config CutConfig) error {
+	if err := config.Method.validate(); err != nil {
+		return err
+	}
+	info, err := v.getInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get video info: %w", err)
+	}
+	kept := ffutil.InvertSegments(segments, info.Duration)
+	if len(kept) == 0 {
+		return fmt.Errorf("nothing left after removing segments")
+	}
+	return v.keepSegments(ctx, outputPath, kept, config.Method, ffutil.NewProgressTracker(config.OnProgress), 0)
+}
+
+// keepSegments joins segments of the source into outputPath using method.
+// segments must be sorted and not overlap. Progress is reported through tracker
+// from progressStart to 100 percent.
+func (v *Video) keepSegments(ctx context.Context, outputPath string, segments []Segment, method CutMethod, tracker *ffutil.ProgressTracker, progressStart float64) error {
+	switch method {
+	case CutMethodAccurate:
+		clips := ffutil.SegmentClips(segments)
+		stage := tracker.Stage(StageRender, progressStart, 100, ffutil.ClipsDuration(clips), 1)
+		return v.renderClips(ctx, outputPath, clips, nil, stage)
+	case CutMethodSmart:
+		cutter, err := v.newSmartCutter(ctx)
+		if err != nil {
+			return err
+		}
+		return v.extractAndConcat(ctx, outputPath, segments, tracker, progressStart,
+			func(ctx context.Context, path string, seg Segment, onReport func(ffutil.ProgressReport)) error {
+				fade := ffutil.AudioFadeFilter(seg.Duration, ffutil.DefaultFadeDurationSec)
+				return cutter.extract(ctx, path, seg.StartTime, seg.EndTime, fade, onReport)
+			})
+	default:
+		return v.extractAndConcat(ctx, outputPath, segments, tracker, progressStart,
+			func(ctx context.Context, path string, seg Segment, onReport func(ffutil.ProgressReport)) error {
+				return v.extractSegmentWithAudioFade(ctx, path, seg.StartTime, seg.EndTime, onReport)
+			})
+	}
+}
+
+// segmentExtractor writes one kept segment of the source to path.
+type segmentExtractor func(ctx context.Context, path string, seg Segment, onReport func(ffutil.ProgressReport)) error
+
+// extractAndConcat extracts segments in parallel with extract and joins them with
+// the concat demuxer.
+func (v *Video) extractAndConcat(ctx context.Context, outputPath string, segments []Segment, tracker *ffutil.ProgressTracker, progressStart float64, extract segmentExtractor) error {
+	tempDir, err := os.MkdirTemp("", "ffmpego_cut_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	ext := filepath.Ext(v.path)
+	if ext == "" {
+		ext = ".mp4"
+	}
+
+	var keptDuration float64
+	for _, seg := range segments {
+		keptDuration += seg.Duration
+	}
+
+	// Extract segments in parallel
+	extractStage := tracker.Stage(StageExtract, progressStart, extractProgressEnd, keptDuration, len(segments))
+	segmentPaths := make([]string, len(segments))
+	errs := make([]error, len(segments))
+
+	maxWorkers := 4
+	if len(segments) < maxWorkers {
+		maxWorkers = len(segments)
+	}
+
+	var wg sync.WaitGroup
+	jobs := make(chan int, len(segments))
+
+	for w := 0; w < maxWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// Drain remaining jobs without starting ffmpeg once ctx is done
+				if ctx.Err() != nil {
+					continue
+				}
+				seg := segments[i]
+				path := filepath.Join(tempDir, fmt.Sprintf("seg_%03d%s", i, ext))
+				segmentPaths[i] = path
+				errs[i] = extract(ctx, path, seg, extractStage.Reporter(i))
+			}
+		}()
+	}
+
+	for i := range segments {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("cutting interrupted: %w", err)
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			return fmt.Errorf("failed to extract segment %d: %w", i+1, err)
+		}
+	}
+
+	extractStage.Done()
+
+	metadataPath, err := v.writeMetadata(ctx, tempDir, ffutil.SegmentClips(segments))
+	if err != nil {
+		return err
+	}
+
+	concatStage := tracker.Stage(StageConcat, extractProgressEnd, 100, keptDuration, 1)
+	return v.concatenate(ctx, segmentPaths, outputPath, nil, metadataPath, concatStage)
+}
+
+// extractSegmentWithAudioFade extracts a video segment keeping the video stream as-is
+// (stream copy) while re-encoding audio with a short fade-in/fade-out at the boundaries.
+// Every stream except data streams is kept: all audio tracks get the fade, and
+// subtitles are copied, shifted to the segment start.
+//
+// This is used by RemoveSilence and KeepSegments. When segments are cut and later concatenated,
+// the audio waveform at each cut point is unlikely to be at a zero-crossing, which produces
+// audible clicks. The fade eliminates these artifacts without affecting video quality or
+// significantly increasing processing time (only the audio track is re-encoded).
+func (v *Video) extractSegmentWithAudioFade(ctx context.Context, outputPath string, startTime, endTime float64, onReport func(ffutil.ProgressReport)) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	probe, err := v.probe(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to probe file: %w", err)
+	}
+
+	duration := endTime - startTime
+	fadeFilter := ffutil.AudioFadeFilter(duration, ffutil.DefaultFadeDurationSec)
+
+	args := []string{
+		"-ss", fmt.Sprintf("%.3f", startTime),
+		"-i", v.path,
+		"-t", fmt.Sprintf("%.3f", duration),
+	}
+	args = append(args, copyAllStreamsArgs...)
+	args = append(args, audioEncoderArgs(probe.AudioStreams())...)
+	args = append(args,
+		"-af", fadeFilter,
+		"-y", outputPath,
+	)
+
+	_, err = ffutil.RunWithProgress(ctx, v.runner, v.ffmpeg, args, onReport)
+	if err != nil {
+		return fmt.Errorf("failed to extract segment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package video
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKeepSegments(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	segments := []Segment{
+		{StartTime: 3.0, EndTime: 4.0},
+		{StartTime: 0.5, EndTime: 1.5},
+		{StartTime: 1.0, EndTime: 2.0}, // overlaps the previous one
+	}
+	if err := v.KeepSegments(out, segments, CutConfig{}); err != nil {
+		t.Fatalf("KeepSegments: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 2.5, 0.5)
+}
+
+func TestRemoveSegments(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	if err := v.RemoveSegments(out, []Segment{{StartTime: 1.0, EndTime: 3.0}}, CutConfig{}); err != nil {
+		t.Fatalf("RemoveSegments: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 3.0, 0.5)
+}
+
+func TestKeepSegments_NothingToKeep(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	if err := v.KeepSegments(out, []Segment{{StartTime: 20, EndTime: 30}}, CutConfig{}); err == nil {
+		t.Error("expected an error for segments outside the file, got nil")
+	}
+	if err := v.RemoveSegments(out, []Segment{{StartTime: 0, EndTime: 30}}, CutConfig{}); err == nil {
+		t.Error("expected an error when removing everything, got nil")
+	}
+}
+
+func TestKeepSegments_Accurate(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	segments := []Segment{
+		{StartTime: 0.5, EndTime: 1.5},
+		{StartTime: 3.0, EndTime: 4.2},
+	}
+	if err := v.KeepSegments(out, segments, CutConfig{Method: CutMethodAccurate}); err != nil {
+		t.Fatalf("KeepSegments: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 2.2, 0.2)
+}
+
+func TestKeepSegments_UnknownMethod(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	err = v.KeepSegments(out, []Segment{{StartTime: 0, EndTime: 1}}, CutConfig{Method: "bogus"})
+	if err == nil {
+		t.Error("expected an error for an unknown cut method, got nil")
+	}
+}
+
+// subtitleRunner fakes a 10s video with one subtitle stream of codec: ffprobe
+// describes it, extracting the subtitles writes srt, and the render's arguments and
+// its retimed subtitle input are recorded.
+type subtitleRunner struct {
+	codec   string
+	srt     string
+	render  []string
+	retimed string
+}
+
+func (r *subtitleRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	switch {
+	case name == "ffprobe":
+		io.WriteString(stdout, `{
+			"streams": [
+				{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1280, "height": 720, "r_frame_rate": "25/1"},
+				{"index": 1, "codec_type": "audio", "codec_name": "aac"},
+				{"index": 2, "codec_type": "subtitle", "codec_name": "`+r.codec+`", "tags": {"language": "por"}}
+			],
+			"format": {"duration": "10.000000"}
+		}`)
+	case slices.Contains(args, "srt") && slices.Contains(args, "-f"):
+		return os.WriteFile(args[len(args)-1], []byte(r.srt), 0644)
+	default:
+		r.render = args
+		for i, arg := range args[:len(args)-1] {
+			if arg == "-i" && strings.HasSuffix(args[i+1], ".srt") {
+				data, err := os.ReadFile(args[i+1])
+				if err != nil {
+					return err
+				}
+				r.retimed = string(data)
+			}
+		}
+	}
+	return nil
+}
+
+func TestKeepSegments_AccurateRetimesSubtitles(t *testing.T) {
+	t.Parallel()
+
+	r := &subtitleRunner{codec: "subrip", srt: "1\n00:00:01,000 --> 00:00:02,000\nOlá\n\n2\n00:00:05,000 --> 00:00:06,000\nTchau\n"}
+	v, err := New(placeholderFile(t, "in.mkv"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	segments := []Segment{{StartTime: 0.5, EndTime: 2.5}, {StartTime: 4.5, EndTime: 6.5}}
+	if err := v.KeepSegments(out, segments, CutConfig{Method: CutMethodAccurate}); err != nil {
+		t.Fatalf("KeepSegments: %v", err)
+	}
+
+	args := strings.Join(r.render, " ")
+	for _, want := range []string{"-map 2:0", "-metadata:s:s:0 language=por", "-c:s mov_text"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("render args missing %q:\n%s", want, args)
+		}
+	}
+	want := "1\n00:00:00,500 --> 00:00:01,500\nOlá\n\n2\n00:00:02,500 --> 00:00:03,500\nTchau\n"
+	if r.retimed != want {
+		t.Errorf("retimed subtitles = %q, want %q", r.retimed, want)
+	}
+}
+
+func TestKeepSegments_AccurateBitmapSubtitles(t *testing.T) {
+	t.Parallel()
+
+	r := &subtitleRunner{codec: "hdmv_pgs_subtitle"}
+	v, err := New(placeholderFile(t, "in.mkv"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mkv")
+	err = v.KeepSegments(out, []Segment{{StartTime: 0.5, EndTime: 2.5}}, CutConfig{Method: CutMethodAccurate})
+	if err == nil || !strings.Contains(err.Error(), "bitmap") {
+		t.Fatalf("expected a bitmap subtitle error, got %v", err)
+	}
+	if r.render != nil {
+		t.Errorf("rendered %v despite the bitmap subtitles", r.render)
+	}
+}
@@ -0,0 +1,75 @@
+package video
+
+import "github.com/meunomeebero/ffmpego/internal/ffutil"
+
+// Runner executes the ffmpeg and ffprobe commands issued by a Video.
+//
+// Run must block until the command exits and should stop the command when ctx is
+// done. stdin may be nil; stdout and stderr may be the same writer.
+type Runner = ffutil.Runner
+
+// Option configures a Video created with New.
+type Option func(*Video)
+
+// WithRunner makes the Video run ffmpeg and ffprobe through r instead of starting
+// local processes. Use it to run commands in a container or on a remote host, to
+// record invocations, or to fake ffmpeg in unit tests.
+//
+// The PATH check for ffmpeg/ffprobe is skipped when a custom runner is used, since
+// the binaries may not exist on the local machine.
+func WithRunner(r Runner) Option {
+	return func(v *Video) {
+		if r != nil {
+			v.runner = r
+		}
+	}
+}
+
+// WithFFmpegPath sets the ffmpeg binary to use, either a name looked up in PATH or a
+// path to an executable. Defaults to "ffmpeg". Useful when several ffmpeg builds are
+// installed side by side.
+func WithFFmpegPath(path string) Option {
+	return func(v *Video) {
+		if path != "" {
+			v.ffmpeg = path
+		}
+	}
+}
+
+// WithFFprobePath sets the ffprobe binary to use, either a name looked up in PATH or
+// a path to an executable. Defaults to "ffprobe".
+func WithFFprobePath(path string) Option {
+	return func(v *Video) {
+		if path != "" {
+			v.ffprobe = path
+		}
+	}
+}
+
+// WithProbe gives the Video a probe already taken of its file, such as the one from
+// media.Open, so the file isn't probed again. It only applies to the file named by
+// probe.Format.Filename, which is the path ffprobe was given; instances derived for
+// other files, such as segments, probe their own.
+func WithProbe(probe *ProbeResult) Option {
+	return func(v *Video) {
+		if probe != nil && probe.Format.Filename == v.path {
+			v.probed = probe.Clone()
+		}
+	}
+}
+
+// newWithOptions returns a Video for path with defaults applied and opts on top.
+// It does not validate anything.
+func newWithOptions(path string, opts []Option) *Video {
+	v := &Video{
+		path:    path,
+		runner:  ffutil.ExecRunner{},
+		ffmpeg:  ffutil.DefaultFFmpeg,
+		ffprobe: ffutil.DefaultFFprobe,
+		opts:    opts,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
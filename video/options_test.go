@@ -0,0 +1,145 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingRunner answers ffprobe with canned output and records every invocation
+// instead of running a process.
+type recordingRunner struct {
+	mu    sync.Mutex
+	probe string
+	calls [][]string
+}
+
+func (r *recordingRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	r.mu.Lock()
+	r.calls = append(r.calls, append([]string{name}, args...))
+	r.mu.Unlock()
+	if name == "ffprobe" {
+		io.WriteString(stdout, r.probe)
+	}
+	return nil
+}
+
+func TestWithRunner_GetInfoUsesRunner(t *testing.T) {
+	t.Parallel()
+
+	r := &recordingRunner{probe: `{
+		"streams": [
+			{"index": 0, "codec_type": "video", "codec_name": "mjpeg", "width": 600, "height": 600, "disposition": {"attached_pic": 1}},
+			{"index": 1, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "r_frame_rate": "30/1"},
+			{"index": 2, "codec_type": "audio", "codec_name": "aac", "sample_rate": "48000", "channels": 2},
+			{"index": 3, "codec_type": "audio", "codec_name": "ac3", "sample_rate": "44100", "channels": 6}
+		],
+		"format": {"duration": "12.500000"}
+	}`}
+	v, err := New(placeholderFile(t, "no-silence.mp4"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	info, err := v.GetInfo()
+	if err != nil {
+		t.Fatalf("GetInfo: %v", err)
+	}
+	if info.Width != 1920 || info.Height != 1080 {
+		t.Errorf("resolution = %dx%d, want 1920x1080 from fake runner", info.Width, info.Height)
+	}
+	if info.Duration != 12.5 {
+		t.Errorf("Duration = %.3f, want 12.5 from fake runner", info.Duration)
+	}
+	if info.VideoCodec != "h264" || info.AudioCodec != "aac" {
+		t.Errorf("codecs = %q/%q, want h264/aac (cover art and later tracks ignored)", info.VideoCodec, info.AudioCodec)
+	}
+	if info.SampleRate != 48000 || info.AudioChannels != 2 {
+		t.Errorf("audio = %d Hz, %d channels, want 48000 Hz stereo from the first track", info.SampleRate, info.AudioChannels)
+	}
+	if len(r.calls) == 0 || r.calls[0][0] != "ffprobe" {
+		t.Errorf("expected ffprobe to go through the runner, got %v", r.calls)
+	}
+}
+
+func TestWithRunner_ExtractSegmentArgs(t *testing.T) {
+	t.Parallel()
+
+	r := &recordingRunner{}
+	v, err := New(placeholderFile(t, "no-silence.mp4"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "segment.mp4")
+	if err := v.ExtractSegment(out, 1.0, 3.0, nil); err != nil {
+		t.Fatalf("ExtractSegment: %v", err)
+	}
+
+	if len(r.calls) != 1 {
+		t.Fatalf("expected 1 runner call, got %d", len(r.calls))
+	}
+	got := strings.Join(r.calls[0], " ")
+	want := "ffmpeg -ss 1.000 -i " + v.Path() + " -t 2.000 -c copy -y " + out
+	if got != want {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+}
+
+func TestWithFFmpegPath_MissingBinary(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	_, err := New(fixture("no-silence.mp4"), WithFFmpegPath("/nonexistent/ffmpeg"))
+	if err == nil {
+		t.Fatal("expected error for missing ffmpeg binary, got nil")
+	}
+}
+
+func TestConvert_UnavailableEncoder(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	err = v.Convert(out, ConvertConfig{VideoCodec: "libdoesnotexist"})
+	if !errors.Is(err, ErrEncoderNotAvailable) {
+		t.Fatalf("expected ErrEncoderNotAvailable, got %v", err)
+	}
+	if _, statErr := os.Stat(out); statErr == nil {
+		t.Error("no output should be written when the encoder is missing")
+	}
+}
+
+func TestCapabilities_ListsCommonEncoders(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	caps, err := v.Capabilities()
+	if err != nil {
+		t.Fatalf("Capabilities: %v", err)
+	}
+	if caps.Version == "" {
+		t.Error("Version should not be empty")
+	}
+	if !caps.HasEncoder(CodecAAC) {
+		t.Errorf("expected the %q encoder to be available", CodecAAC)
+	}
+	if !caps.HasFilter("silencedetect") {
+		t.Error("expected the silencedetect filter to be available")
+	}
+}
@@ -0,0 +1,130 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// Packet is one demuxed packet of the video stream: presentation and decode times,
+// duration, size in bytes, byte offset and ffprobe's flags ("K" marks a keyframe).
+// Times are the container's timestamps, which start at Probe().Format.StartTime
+// rather than zero in MPEG-TS files and MP4s with an edit list; subtract it to get
+// times as GetInfo, the segments and ExtractSegment count them.
+type Packet = ffutil.Packet
+
+// Packets lists every packet of the video stream in decode order. Only the container
+// is read, nothing is decoded, so this is fast even for long recordings and is the
+// basis for GOP statistics or bitrate-over-time graphs. The stream is the first
+// video stream that is not an attached picture, as in GetInfo.
+// Results are cached — subsequent calls return the cached value without invoking ffprobe.
+func (v *Video) Packets() ([]Packet, error) {
+	return v.PacketsContext(context.Background())
+}
+
+// PacketsContext is like Packets but stops ffprobe when ctx is cancelled or its
+// deadline passes. The returned error then wraps ctx.Err().
+func (v *Video) PacketsContext(ctx context.Context) ([]Packet, error) {
+	packets, err := v.packets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]Packet(nil), packets...), nil
+}
+
+// Keyframes lists the keyframe packets of the video stream sorted by presentation
+// time. These are the only points where stream copy can start a segment.
+// Results come from the same cache as Packets.
+func (v *Video) Keyframes() ([]Packet, error) {
+	return v.KeyframesContext(context.Background())
+}
+
+// KeyframesContext is like Keyframes but can be cancelled through ctx.
+func (v *Video) KeyframesContext(ctx context.Context) ([]Packet, error) {
+	packets, err := v.packets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var keyframes []Packet
+	for _, p := range packets {
+		if p.Keyframe() {
+			keyframes = append(keyframes, p)
+		}
+	}
+	sort.SliceStable(keyframes, func(i, j int) bool {
+		return keyframes[i].PTS < keyframes[j].PTS
+	})
+	return keyframes, nil
+}
+
+// SnapToKeyframe returns the time of the last keyframe at or before t, which is
+// where ExtractSegment without a config actually starts a clip requested at t.
+// Times before the first keyframe snap to it. t and the result count from the start
+// of the file, like ExtractSegment's times, not from the container's first timestamp.
+func (v *Video) SnapToKeyframe(t float64) (float64, error) {
+	return v.SnapToKeyframeContext(context.Background(), t)
+}
+
+// SnapToKeyframeContext is like SnapToKeyframe but can be cancelled through ctx.
+func (v *Video) SnapToKeyframeContext(ctx context.Context, t float64) (float64, error) {
+	packets, err := v.packets(ctx)
+	if err != nil {
+		return 0, err
+	}
+	probe, err := v.probe(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe file: %w", err)
+	}
+	keyframes := ffutil.KeyframeTimes(packets)
+	if len(keyframes) == 0 {
+		return 0, fmt.Errorf("no keyframes found")
+	}
+	for i := range keyframes {
+		keyframes[i] -= probe.Format.StartTime
+	}
+	// Keyframes within a microsecond count as at t, absorbing ffprobe's rounding
+	i := sort.SearchFloat64s(keyframes, t+1e-6)
+	if i == 0 {
+		return keyframes[0], nil
+	}
+	return keyframes[i-1], nil
+}
+
+// packets returns the cached packet list of the video stream, reading it on first use.
+// Callers must not modify the result.
+func (v *Video) packets(ctx context.Context) ([]Packet, error) {
+	if v.packetIndex != nil {
+		return v.packetIndex, nil
+	}
+
+	stream, err := v.videoStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	packets, err := ffutil.ProbePackets(ctx, v.runner, v.ffprobe, v.path, strconv.Itoa(stream.Index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packets: %w", err)
+	}
+	if packets == nil {
+		packets = []Packet{}
+	}
+	v.packetIndex = packets
+	return packets, nil
+}
+
+// videoStream returns the stream described by GetInfo: the first video stream that
+// is not an attached picture.
+func (v *Video) videoStream(ctx context.Context) (*Stream, error) {
+	probe, err := v.probe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe file: %w", err)
+	}
+	streams := probe.VideoStreams()
+	if len(streams) == 0 {
+		return nil, fmt.Errorf("no video stream found")
+	}
+	return &streams[0], nil
+}
@@ -0,0 +1,186 @@
+package video
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// packetRunner answers ffprobe with a canned stream description for -show_streams
+// (probe, or a default one) and canned packets for -show_entries, counting packet
+// reads. ffmpeg runs are recorded in calls. Like a killed process, it fails once ctx
+// is done.
+type packetRunner struct {
+	mu      sync.Mutex
+	reads   int
+	probe   string
+	packets string
+	calls   [][]string
+}
+
+func (r *packetRunner) Run(ctx context.Context, name string, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case name != "ffprobe":
+		r.calls = append(r.calls, args)
+	case slices.Contains(args, "-show_streams") && r.probe != "":
+		io.WriteString(stdout, r.probe)
+	case slices.Contains(args, "-show_streams"):
+		io.WriteString(stdout, `{
+			"streams": [
+				{"index": 0, "codec_type": "video", "codec_name": "mjpeg", "disposition": {"attached_pic": 1}},
+				{"index": 1, "codec_type": "video", "codec_name": "h264", "r_frame_rate": "25/1"}
+			],
+			"format": {"duration": "6.000000"}
+		}`)
+	default:
+		r.reads++
+		io.WriteString(stdout, r.packets)
+	}
+	return nil
+}
+
+const fakePackets = `pts_time=0.000000|dts_time=-0.080000|duration_time=0.040000|size=9000|pos=48|flags=K__
+pts_time=0.080000|dts_time=-0.040000|duration_time=0.040000|size=400|pos=9048|flags=___
+pts_time=2.000000|dts_time=1.920000|duration_time=0.040000|size=8800|pos=80000|flags=K__
+pts_time=4.000000|dts_time=3.920000|duration_time=0.040000|size=8700|pos=160000|flags=K__
+`
+
+func TestKeyframes_FakeRunner(t *testing.T) {
+	t.Parallel()
+
+	r := &packetRunner{packets: fakePackets}
+	v, err := New(placeholderFile(t, "no-silence.mp4"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	packets, err := v.Packets()
+	if err != nil {
+		t.Fatalf("Packets: %v", err)
+	}
+	if len(packets) != 4 {
+		t.Errorf("got %d packets, want 4", len(packets))
+	}
+
+	keyframes, err := v.Keyframes()
+	if err != nil {
+		t.Fatalf("Keyframes: %v", err)
+	}
+	var times []float64
+	for _, k := range keyframes {
+		times = append(times, k.PTS)
+	}
+	if !slices.Equal(times, []float64{0, 2, 4}) {
+		t.Errorf("keyframe times = %v, want [0 2 4]", times)
+	}
+
+	for _, tt := range []struct{ t, want float64 }{{0, 0}, {1.5, 0}, {2, 2}, {3.9, 2}, {5, 4}} {
+		got, err := v.SnapToKeyframe(tt.t)
+		if err != nil {
+			t.Fatalf("SnapToKeyframe: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("SnapToKeyframe(%.1f) = %.1f, want %.1f", tt.t, got, tt.want)
+		}
+	}
+
+	if r.reads != 1 {
+		t.Errorf("packets read %d times, want 1 (cached)", r.reads)
+	}
+
+	// Callers get their own copy of the cache
+	packets[0].Size = 0
+	again, _ := v.Packets()
+	if again[0].Size != 9000 {
+		t.Error("modifying the result changed the cache")
+	}
+}
+
+func TestSnapToKeyframeContext_Canceled(t *testing.T) {
+	t.Parallel()
+
+	r := &packetRunner{packets: fakePackets}
+	v, err := New(placeholderFile(t, "no-silence.mp4"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := v.SnapToKeyframeContext(ctx, 3); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error wrapping context.Canceled, got %v", err)
+	}
+
+	got, err := v.SnapToKeyframeContext(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("SnapToKeyframeContext: %v", err)
+	}
+	if got != 2 {
+		t.Errorf("SnapToKeyframeContext(3) = %.1f, want 2", got)
+	}
+}
+
+func TestSnapToKeyframe_StartTime(t *testing.T) {
+	t.Parallel()
+
+	// The same keyframes in a file whose timestamps start at 10s
+	r := &packetRunner{
+		probe: `{
+			"streams": [{"index": 0, "codec_type": "video", "codec_name": "h264", "r_frame_rate": "25/1"}],
+			"format": {"start_time": "10.000000", "duration": "6.000000"}
+		}`,
+		packets: `pts_time=10.000000|dts_time=9.920000|flags=K__
+pts_time=12.000000|dts_time=11.920000|flags=K__
+pts_time=14.000000|dts_time=13.920000|flags=K__
+`,
+	}
+	v, err := New(placeholderFile(t, "in.ts"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for _, tt := range []struct{ t, want float64 }{{0, 0}, {3.9, 2}, {5, 4}} {
+		got, err := v.SnapToKeyframe(tt.t)
+		if err != nil {
+			t.Fatalf("SnapToKeyframe: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("SnapToKeyframe(%.1f) = %.1f, want %.1f", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestKeyframes(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	keyframes, err := v.Keyframes()
+	if err != nil {
+		t.Fatalf("Keyframes: %v", err)
+	}
+	if len(keyframes) == 0 || keyframes[0].PTS != 0 {
+		t.Fatalf("expected a keyframe at 0, got %+v", keyframes)
+	}
+
+	packets, err := v.Packets()
+	if err != nil {
+		t.Fatalf("Packets: %v", err)
+	}
+	// 5 seconds at 15 fps
+	if len(packets) < 70 || len(packets) > 80 {
+		t.Errorf("got %d packets, want about 75", len(packets))
+	}
+}
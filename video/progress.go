@@ -0,0 +1,30 @@
+package video
+
+import "github.com/meunomeebero/ffmpego/internal/ffutil"
+
+// Progress describes how far a long-running operation has come.
+// Percent is normalized against the input duration and covers the whole operation.
+type Progress = ffutil.Progress
+
+// ProgressFunc receives progress updates. Calls are serialized, even when
+// RemoveSilence extracts segments in parallel.
+type ProgressFunc = ffutil.ProgressFunc
+
+// Stage identifies which part of an operation a Progress value refers to.
+type Stage = ffutil.Stage
+
+// Stages reported through Progress.Stage
+const (
+	StageConvert = ffutil.StageConvert // Convert or ExtractSegment re-encoding
+	StageDetect  = ffutil.StageDetect  // Silence detection
+	StageExtract = ffutil.StageExtract // Extracting kept segments
+	StageConcat  = ffutil.StageConcat  // Joining segments
+	StageRender  = ffutil.StageRender  // Rendering the whole edit in one pass (SilenceModeSpeedUp, SilenceModeCrossfade, CutMethodAccurate)
+)
+
+// Share of the overall RemoveSilence percentage given to each stage. Extraction does
+// most of the I/O, so it gets the largest slice.
+const (
+	detectProgressEnd  = 15.0
+	extractProgressEnd = 90.0
+)
@@ -0,0 +1,176 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/meunomeebero/ffmpego/audio"
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// ReferenceConfig contains configuration for RemoveSilenceUsing.
+type ReferenceConfig struct {
+	// SilenceConfig sets how silence is detected on the reference and how the video
+	// is cut, as for RemoveSilence. AudioStream and Channel select tracks of the
+	// reference.
+	SilenceConfig
+
+	// ReplaceAudio swaps the video's audio for the reference's in the output. The
+	// reference is encoded with the codec of the video's first audio track (AAC when
+	// the video has none); video and subtitles are copied.
+	ReplaceAudio bool
+
+	// DetectOffset ignores the offset passed to RemoveSilenceUsing and finds it with
+	// FindAudioOffset, configured by Sync. RemoveSilenceUsing fails when the match has
+	// a confidence below minSyncConfidence.
+	DetectOffset bool
+	Sync         SyncConfig
+	// OnOffset, if set, receives the offset found by DetectOffset.
+	OnOffset func(SyncEstimate)
+}
+
+// minSyncConfidence is the lowest confidence of a detected offset RemoveSilenceUsing
+// accepts. Below it the recordings most likely don't share the same sound.
+const minSyncConfidence = 0.25
+
+// RemoveSilenceUsing detects silence on a separate reference recording, such as the
+// lav mic of a dual-system shoot, and removes the same moments from the video.
+//
+// offset is where the reference starts on the video's timeline, in seconds: sound at
+// t in the reference is at t+offset in the video. It is negative when the reference
+// started recording first. Parts of the reference outside the video are ignored. Set
+// config.DetectOffset to find it from the audio instead.
+func (v *Video) RemoveSilenceUsing(outputPath string, reference *audio.Audio, offset float64, config ReferenceConfig) error {
+	return v.RemoveSilenceUsingContext(context.Background(), outputPath, reference, offset, config)
+}
+
+// RemoveSilenceUsingContext is like RemoveSilenceUsing but can be cancelled through
+// ctx, as RemoveSilenceContext.
+func (v *Video) RemoveSilenceUsingContext(ctx context.Context, outputPath string, reference *audio.Audio, offset float64, config ReferenceConfig) error {
+	if reference == nil {
+		return fmt.Errorf("no reference recording given")
+	}
+	if err := config.validateMode(); err != nil {
+		return err
+	}
+
+	info, err := v.getInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get video info: %w", err)
+	}
+
+	if config.DetectOffset {
+		estimate, err := v.FindAudioOffsetContext(ctx, reference, config.Sync)
+		if err != nil {
+			return fmt.Errorf("failed to detect the offset of the reference: %w", err)
+		}
+		if config.OnOffset != nil {
+			config.OnOffset(estimate)
+		}
+		if estimate.Confidence < minSyncConfidence {
+			return fmt.Errorf("reference does not clearly match the video (confidence %.2f); pass its offset instead", estimate.Confidence)
+		}
+		offset = estimate.Offset
+	}
+
+	tracker := ffutil.NewProgressTracker(config.OnProgress)
+	detected, err := reference.GetNonSilentSegmentsContext(ctx, referenceSilenceConfig(config.SilenceConfig))
+	if err != nil {
+		return fmt.Errorf("failed to detect segments on the reference: %w", err)
+	}
+	segments := ffutil.NormalizeSegments(shiftSegments(detected, offset), info.Duration)
+	if len(segments) == 0 {
+		return fmt.Errorf("no audible content of the reference overlaps the video")
+	}
+
+	source := v
+	if config.ReplaceAudio {
+		tempDir, err := os.MkdirTemp("", "ffmpego_reference_*")
+		if err != nil {
+			return fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer os.RemoveAll(tempDir)
+
+		if source, err = v.withReferenceAudio(ctx, tempDir, reference, offset); err != nil {
+			return err
+		}
+	}
+
+	_, err = source.applySegments(ctx, outputPath, segments, config.SilenceConfig, tracker)
+	return err
+}
+
+// shiftSegments returns segments moved later by offset seconds, or earlier when offset
+// is negative. The result may start before zero; clamp it with the target's duration.
+func shiftSegments(segments []Segment, offset float64) []Segment {
+	shifted := make([]Segment, len(segments))
+	for i, seg := range segments {
+		shifted[i] = Segment{StartTime: seg.StartTime + offset, EndTime: seg.EndTime + offset, Duration: seg.Duration}
+	}
+	return shifted
+}
+
+// referenceSilenceConfig converts the detection settings of config for the reference.
+// Progress is scaled to the detection part of the overall operation.
+func referenceSilenceConfig(config SilenceConfig) audio.SilenceConfig {
+	c := audio.SilenceConfig{
+		MinSilenceDuration: config.MinSilenceDuration,
+		SilenceThreshold:   config.SilenceThreshold,
+		AutoThreshold:      config.AutoThreshold,
+		Detector:           audio.SilenceDetector(config.Detector),
+		AudioStream:        config.AudioStream,
+		Channel:            config.Channel,
+		PaddingBefore:      config.PaddingBefore,
+		PaddingAfter:       config.PaddingAfter,
+		MergeGap:           config.MergeGap,
+		MinSegmentDuration: config.MinSegmentDuration,
+		MaxPauseDuration:   config.MaxPauseDuration,
+		OnThreshold:        config.OnThreshold,
+	}
+	if onProgress := config.OnProgress; onProgress != nil {
+		c.OnProgress = func(p Progress) {
+			p.Percent *= detectProgressEnd / 100
+			onProgress(p)
+		}
+	}
+	return c
+}
+
+// withReferenceAudio writes a copy of the video into dir with its audio replaced by
+// the reference, placed at offset, and returns it as a Video with the same options.
+func (v *Video) withReferenceAudio(ctx context.Context, dir string, reference *audio.Audio, offset float64) (*Video, error) {
+	info, err := v.getInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video info: %w", err)
+	}
+	probe, err := v.probe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe file: %w", err)
+	}
+	encoder := "aac"
+	if tracks := probe.AudioStreams(); len(tracks) > 0 {
+		encoder = encoderForDecoder(tracks[0].CodecName)
+	}
+
+	ext := filepath.Ext(v.path)
+	if ext == "" {
+		ext = ".mp4"
+	}
+	path := filepath.Join(dir, "synced"+ext)
+
+	args := []string{"-i", v.path}
+	args = append(args, offsetInputArgs(offset)...)
+	args = append(args,
+		"-i", reference.Path(),
+		"-map", "0", "-map", "-0:a", "-map", "-0:d?", "-map", "1:a",
+		"-c", "copy", "-c:a", encoder,
+		"-t", fmt.Sprintf("%.6f", info.Duration),
+		"-y", path,
+	)
+	if _, err := ffutil.CombinedOutput(ctx, v.runner, v.ffmpeg, args...); err != nil {
+		return nil, fmt.Errorf("failed to replace audio: %w", err)
+	}
+	return newWithOptions(path, v.opts), nil
+}
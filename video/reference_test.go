@@ -0,0 +1,82 @@
+package video
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/meunomeebero/ffmpego/audio"
+)
+
+func TestShiftSegments(t *testing.T) {
+	got := shiftSegments([]Segment{{StartTime: 1, EndTime: 3, Duration: 2}}, -1.5)
+	want := Segment{StartTime: -0.5, EndTime: 1.5, Duration: 2}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestRemoveSilenceUsing(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	// The camera recorded a steady tone; the reference has the pause
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	reference, err := audio.New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("audio.New: %v", err)
+	}
+	config := ReferenceConfig{SilenceConfig: SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+	}}
+
+	// Reference tone at 0-2 and 4-6 lands on 1-3 and 5-6 of the 5s video, which
+	// clamps the second one away
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	if err := v.RemoveSilenceUsing(out, reference, 1, config); err != nil {
+		t.Fatalf("RemoveSilenceUsing: %v", err)
+	}
+	assertValidMedia(t, out)
+	assertDuration(t, out, 2.0, 0.4)
+
+	config.ReplaceAudio = true
+	out = filepath.Join(t.TempDir(), "replaced.mp4")
+	if err := v.RemoveSilenceUsing(out, reference, 0, config); err != nil {
+		t.Fatalf("RemoveSilenceUsing with ReplaceAudio: %v", err)
+	}
+	assertValidMedia(t, out)
+	assertDuration(t, out, 4.0, 0.4)
+
+	if err := v.RemoveSilenceUsing(out, reference, 10, config); err == nil {
+		t.Error("expected an error for a reference entirely after the video, got nil")
+	}
+}
+
+func TestRemoveSilenceUsing_DetectOffset(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	reference, err := audio.New(fixture("noise.mp4"))
+	if err != nil {
+		t.Fatalf("audio.New: %v", err)
+	}
+
+	// A steady tone and noise have nothing in common to line up
+	config := ReferenceConfig{DetectOffset: true}
+	var found bool
+	config.OnOffset = func(SyncEstimate) { found = true }
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	if err := v.RemoveSilenceUsing(out, reference, 0, config); err == nil {
+		t.Error("expected an error for an unrelated reference, got nil")
+	}
+	if !found {
+		t.Error("OnOffset was not called")
+	}
+}
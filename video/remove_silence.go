@@ -1,11 +1,8 @@
 package video
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
-	"sync"
 
 	"github.com/meunomeebero/ffmpego/internal/ffutil"
 )
@@ -14,95 +11,74 @@ import (
 // video with only the non-silent segments concatenated together.
 // Uses parallel extraction for speed. Video streams are copied without re-encoding;
 // audio gets a short fade at each cut point to prevent clicks and pops.
+// Set config.CutMethod to CutMethodAccurate or CutMethodSmart for frame-exact cuts instead.
 func (v *Video) RemoveSilence(outputPath string, config SilenceConfig) error {
-	segments, err := v.GetNonSilentSegments(config)
-	if err != nil {
-		return fmt.Errorf("failed to detect segments: %w", err)
-	}
+	return v.RemoveSilenceContext(context.Background(), outputPath, config)
+}
 
-	if len(segments) == 0 {
-		return fmt.Errorf("no audible content found above the configured threshold")
-	}
+// RemoveSilenceContext is like RemoveSilence but can be cancelled through ctx.
+// On cancellation or deadline the running ffmpeg processes are killed, no further
+// segments are extracted, temporary files are removed and the returned error wraps
+// ctx.Err().
+func (v *Video) RemoveSilenceContext(ctx context.Context, outputPath string, config SilenceConfig) error {
+	_, err := v.RemoveSilenceWithTimelineContext(ctx, outputPath, config)
+	return err
+}
 
-	tempDir, err := os.MkdirTemp("", "ffmpego_silence_*")
-	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
-	}
-	defer os.RemoveAll(tempDir)
+// RemoveSilenceWithTimeline is like RemoveSilence but also returns the Timeline of the
+// edit, for translating times between the source and the output.
+func (v *Video) RemoveSilenceWithTimeline(outputPath string, config SilenceConfig) (*Timeline, error) {
+	return v.RemoveSilenceWithTimelineContext(context.Background(), outputPath, config)
+}
 
-	ext := filepath.Ext(v.path)
-	if ext == "" {
-		ext = ".mp4"
+// RemoveSilenceWithTimelineContext is like RemoveSilenceWithTimeline but can be
+// cancelled through ctx, as RemoveSilenceContext.
+func (v *Video) RemoveSilenceWithTimelineContext(ctx context.Context, outputPath string, config SilenceConfig) (*Timeline, error) {
+	if err := config.validateMode(); err != nil {
+		return nil, err
 	}
 
-	// Extract segments in parallel
-	segmentPaths := make([]string, len(segments))
-	errs := make([]error, len(segments))
+	tracker := ffutil.NewProgressTracker(config.OnProgress)
 
-	maxWorkers := 4
-	if len(segments) < maxWorkers {
-		maxWorkers = len(segments)
+	segments, err := v.nonSilentSegments(ctx, config, tracker, detectProgressEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect segments: %w", err)
 	}
 
-	var wg sync.WaitGroup
-	jobs := make(chan int, len(segments))
-
-	for w := 0; w < maxWorkers; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for i := range jobs {
-				seg := segments[i]
-				path := filepath.Join(tempDir, fmt.Sprintf("seg_%03d%s", i, ext))
-				segmentPaths[i] = path
-				errs[i] = v.extractSegmentWithAudioFade(path, seg.StartTime, seg.EndTime)
-			}
-		}()
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("no audible content found above the configured threshold")
 	}
 
-	for i := range segments {
-		jobs <- i
+	return v.applySegments(ctx, outputPath, segments, config, tracker)
+}
+
+// applySegments writes the edit that keeps segments, as config.Mode and
+// config.CutMethod ask, and returns its timeline. Progress continues from
+// detectProgressEnd.
+func (v *Video) applySegments(ctx context.Context, outputPath string, segments []Segment, config SilenceConfig, tracker *ffutil.ProgressTracker) (*Timeline, error) {
+	info, err := v.getInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video info: %w", err)
 	}
-	close(jobs)
-	wg.Wait()
 
-	for i, err := range errs {
+	if config.Mode == SilenceModeCrossfade {
+		clips, err := v.crossfadeSilence(ctx, outputPath, segments, config, tracker)
 		if err != nil {
-			return fmt.Errorf("failed to extract segment %d: %w", i+1, err)
+			return nil, err
 		}
+		return ffutil.NewTimeline(clips, info.Duration), nil
 	}
 
-	return ConcatenateSegments(segmentPaths, outputPath, nil)
-}
-
-// extractSegmentWithAudioFade extracts a video segment keeping the video stream as-is
-// (stream copy) while re-encoding audio with a short fade-in/fade-out at the boundaries.
-//
-// This is used exclusively by RemoveSilence. When segments are cut and later concatenated,
-// the audio waveform at each cut point is unlikely to be at a zero-crossing, which produces
-// audible clicks. The fade eliminates these artifacts without affecting video quality or
-// significantly increasing processing time (only the audio track is re-encoded).
-func (v *Video) extractSegmentWithAudioFade(outputPath string, startTime, endTime float64) error {
-	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
-	}
-
-	duration := endTime - startTime
-	fadeFilter := ffutil.AudioFadeFilter(duration, ffutil.DefaultFadeDurationSec)
-
-	args := []string{
-		"-ss", fmt.Sprintf("%.3f", startTime),
-		"-i", v.path,
-		"-t", fmt.Sprintf("%.3f", duration),
-		"-c:v", "copy",
-		"-af", fadeFilter,
-		"-y", outputPath,
+	if config.Mode == SilenceModeSpeedUp {
+		clips, err := v.speedUpSilence(ctx, outputPath, segments, config, tracker)
+		if err != nil {
+			return nil, err
+		}
+		return ffutil.NewTimeline(clips, info.Duration), nil
 	}
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("FFmpeg error: %w - %s", err, string(output))
+	if err := v.keepSegments(ctx, outputPath, segments, config.CutMethod, tracker, detectProgressEnd); err != nil {
+		return nil, err
 	}
-	return nil
+	return ffutil.NewTimeline(ffutil.SegmentClips(segments), info.Duration), nil
 }
@@ -1,15 +1,20 @@
 package video
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 )
 
 // RemoveSilence tests
 
 func TestRemoveSilence_ProducesShorterOutput(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("silence-middle.mp4"))
@@ -49,6 +54,7 @@ func TestRemoveSilence_ProducesShorterOutput(t *testing.T) {
 }
 
 func TestRemoveSilence_NoSilence_CopiesFile(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -70,6 +76,7 @@ func TestRemoveSilence_NoSilence_CopiesFile(t *testing.T) {
 }
 
 func TestRemoveSilence_AllSilence(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("all-silence.mp4"))
@@ -89,6 +96,7 @@ func TestRemoveSilence_AllSilence(t *testing.T) {
 }
 
 func TestRemoveSilence_ShortFile(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("short.mp4"))
@@ -111,6 +119,7 @@ func TestRemoveSilence_ShortFile(t *testing.T) {
 }
 
 func TestRemoveSilence_TempFilesCleanedUp(t *testing.T) {
+	requireFFmpeg(t)
 	// NOT parallel — runs after all parallel tests complete, so no
 	// other test is creating ffmpego_silence_* dirs concurrently.
 	const prefix = "ffmpego_silence_"
@@ -141,6 +150,7 @@ func TestRemoveSilence_TempFilesCleanedUp(t *testing.T) {
 // Parallel tests
 
 func TestParallel_SameInput_DifferentOutputs(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	tmpDir := t.TempDir()
@@ -184,6 +194,7 @@ func TestParallel_SameInput_DifferentOutputs(t *testing.T) {
 }
 
 func TestParallel_DifferentInputs(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	tmpDir := t.TempDir()
@@ -227,6 +238,7 @@ func TestParallel_DifferentInputs(t *testing.T) {
 }
 
 func TestParallel_SameOutput_NoPanic(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	out := filepath.Join(t.TempDir(), "out.mp4")
@@ -258,6 +270,7 @@ func TestParallel_SameOutput_NoPanic(t *testing.T) {
 }
 
 func TestParallel_RemoveSilence_Concurrent(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	tmpDir := t.TempDir()
@@ -296,6 +309,7 @@ func TestParallel_RemoveSilence_Concurrent(t *testing.T) {
 }
 
 func TestParallel_TempFilesIsolated(t *testing.T) {
+	requireFFmpeg(t)
 	// NOT parallel — runs after all parallel tests complete, so the
 	// snapshot/diff approach works without false positives from sibling tests.
 	const prefix = "ffmpego_silence_"
@@ -340,3 +354,305 @@ func TestParallel_TempFilesIsolated(t *testing.T) {
 		}
 	}
 }
+
+// Context tests
+
+func TestRemoveSilenceContext_Canceled(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	err = v.RemoveSilenceContext(ctx, out, SilenceConfig{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error wrapping context.Canceled, got %v", err)
+	}
+	if _, statErr := os.Stat(out); statErr == nil {
+		t.Errorf("output %q should not exist after cancellation", out)
+	}
+}
+
+func TestRemoveSilenceContext_DeadlineExceeded(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := v.GetInfo(); err != nil {
+		t.Fatalf("GetInfo: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	err = v.RemoveSilenceContext(ctx, out, SilenceConfig{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected error wrapping context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// Progress tests
+
+func TestRemoveSilence_ReportsProgress(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var updates []Progress
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		OnProgress:         func(p Progress) { updates = append(updates, p) },
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	if err := v.RemoveSilence(out, config); err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	if len(updates) == 0 {
+		t.Fatal("expected progress updates, got none")
+	}
+
+	stages := make(map[Stage]bool)
+	last := 0.0
+	for _, p := range updates {
+		stages[p.Stage] = true
+		if p.Percent < last {
+			t.Errorf("percent moved backwards: %.1f -> %.1f", last, p.Percent)
+		}
+		last = p.Percent
+	}
+	for _, s := range []Stage{StageDetect, StageExtract, StageConcat} {
+		if !stages[s] {
+			t.Errorf("no progress reported for stage %q", s)
+		}
+	}
+	if last != 100 {
+		t.Errorf("final percent = %.1f, want 100", last)
+	}
+}
+
+func TestRemoveSilence_SpeedUp(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		Mode:               SilenceModeSpeedUp,
+		SpeedUpFactor:      4,
+	}
+	if err := v.RemoveSilence(out, config); err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	// 2s tone + 2s silence at 4x + 2s tone
+	assertValidMedia(t, out)
+	assertDuration(t, out, 4.5, 0.4)
+}
+
+func TestRemoveSilence_Crossfade(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		Mode:               SilenceModeCrossfade,
+		CrossfadeDuration:  300,
+		CrossfadeCurve:     CrossfadeLinear,
+		CrossfadeVideo:     true,
+	}
+	timeline, err := v.RemoveSilenceWithTimeline(out, config)
+	if err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	// 2s tone + 2s tone, overlapping by 0.3s
+	assertValidMedia(t, out)
+	assertDuration(t, out, 3.7, 0.4)
+	if got := timeline.OutputDuration(); got < 3.3 || got > 4.1 {
+		t.Errorf("timeline output duration = %.2f, want about 3.7", got)
+	}
+}
+
+func TestRemoveSilence_InvalidMode(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	if err := v.RemoveSilence(out, SilenceConfig{Mode: "bogus"}); err == nil {
+		t.Error("expected an error for an unknown mode, got nil")
+	}
+	if err := v.RemoveSilence(out, SilenceConfig{Mode: SilenceModeSpeedUp, SpeedUpFactor: 0.5}); err == nil {
+		t.Error("expected an error for SpeedUpFactor below 1, got nil")
+	}
+	if err := v.RemoveSilence(out, SilenceConfig{Mode: SilenceModeCrossfade, CrossfadeCurve: "wobbly"}); err == nil {
+		t.Error("expected an error for an unknown crossfade curve, got nil")
+	}
+}
+
+func TestRemoveSilence_MaxPauseDuration(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		MaxPauseDuration:   500,
+	}
+	if err := v.RemoveSilence(out, config); err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	// 2s tone + 2s silence shortened to 0.5s + 2s tone
+	assertValidMedia(t, out)
+	assertDuration(t, out, 4.5, 0.4)
+}
+
+func TestRemoveSilence_AccurateCut(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		CutMethod:          CutMethodAccurate,
+	}
+	if err := v.RemoveSilence(out, config); err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 4.0, 0.4)
+}
+
+func TestRemoveSilence_PreservesStreamsAndChapters(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	dir := t.TempDir()
+	metadata := filepath.Join(dir, "metadata.txt")
+	if err := os.WriteFile(metadata, []byte(";FFMETADATA1\ntitle=Episode 1\n"+
+		"[CHAPTER]\nTIMEBASE=1/1000\nSTART=0\nEND=2000\ntitle=Intro\n"+
+		"[CHAPTER]\nTIMEBASE=1/1000\nSTART=2300\nEND=3700\ntitle=Pause\n"+
+		"[CHAPTER]\nTIMEBASE=1/1000\nSTART=3700\nEND=6000\ntitle=Outro\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// silence-middle.mp4 with a second audio track, a title and chapters
+	if err := runFFmpeg(dir, "in.mp4",
+		"-i", fixture("silence-middle.mp4"),
+		"-f", "ffmetadata", "-i", metadata,
+		"-map", "0:v", "-map", "0:a", "-map", "0:a",
+		"-map_metadata", "1", "-map_chapters", "1",
+		"-c", "copy",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := New(filepath.Join(dir, "in.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	out := filepath.Join(dir, "out.mp4")
+	if err := v.RemoveSilence(out, SilenceConfig{MinSilenceDuration: SilenceDurationShort}); err != nil {
+		t.Fatalf("RemoveSilence: %v", err)
+	}
+
+	outV, err := New(out)
+	if err != nil {
+		t.Fatalf("New (output): %v", err)
+	}
+	probe, err := outV.Probe()
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if n := len(probe.AudioStreams()); n != 2 {
+		t.Errorf("output has %d audio streams, want 2", n)
+	}
+	if title := probe.Format.Tags["title"]; title != "Episode 1" {
+		t.Errorf("title = %q, want %q", title, "Episode 1")
+	}
+	var titles []string
+	for _, c := range probe.Chapters {
+		titles = append(titles, c.Title)
+	}
+	if fmt.Sprint(titles) != "[Intro Outro]" {
+		t.Errorf("chapters = %v, want [Intro Outro] (the pause chapter was removed)", titles)
+	}
+}
+
+func TestRemoveSilenceWithTimeline(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	timeline, err := v.RemoveSilenceWithTimeline(out, SilenceConfig{MinSilenceDuration: SilenceDurationShort})
+	if err != nil {
+		t.Fatalf("RemoveSilenceWithTimeline: %v", err)
+	}
+
+	if len(timeline.Segments) != 2 {
+		t.Fatalf("expected 2 kept segments (tone-silence-tone), got %+v", timeline.Segments)
+	}
+	assertDuration(t, out, timeline.OutputDuration(), 0.3)
+
+	// The middle of the pause was removed and lands on the cut
+	if _, kept := timeline.SourceToOutput(3.0); kept {
+		t.Error("SourceToOutput(3.0) reported a removed time as kept")
+	}
+	src, ok := timeline.OutputToSource(timeline.Segments[1].OutputStart + 0.5)
+	if !ok || src < 4.0 {
+		t.Errorf("OutputToSource after the cut = %.3f, %v; want a time in the second tone", src, ok)
+	}
+}
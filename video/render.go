@@ -0,0 +1,175 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+	"github.com/meunomeebero/ffmpego/subtitles"
+)
+
+// textSubtitleCodecs are the subtitle codecs ffmpeg converts to SubRip, so renderClips
+// can retime them. Bitmap subtitles such as PGS or DVD subpictures are not among them.
+var textSubtitleCodecs = map[string]bool{
+	"subrip":   true,
+	"srt":      true,
+	"webvtt":   true,
+	"mov_text": true,
+	"ass":      true,
+	"ssa":      true,
+	"text":     true,
+}
+
+// speedUpSilence renders the video with segments at normal speed and the silent
+// parts between them sped up by config.SpeedUpFactor, and returns the clips it played.
+func (v *Video) speedUpSilence(ctx context.Context, outputPath string, segments []Segment, config SilenceConfig, tracker *ffutil.ProgressTracker) ([]ffutil.Clip, error) {
+	info, err := v.getInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video info: %w", err)
+	}
+	clips := ffutil.SpeedUpClips(segments, info.Duration, config.speedUpFactor())
+	stage := tracker.Stage(StageRender, detectProgressEnd, 100, ffutil.ClipsDuration(clips), 1)
+	if err := v.renderClips(ctx, outputPath, clips, nil, stage); err != nil {
+		return nil, err
+	}
+	return clips, nil
+}
+
+// crossfadeSilence renders segments into outputPath with each cut overlapped by a
+// crossfade, and returns the clips of the source visible in the output.
+func (v *Video) crossfadeSilence(ctx context.Context, outputPath string, segments []Segment, config SilenceConfig, tracker *ffutil.ProgressTracker) ([]ffutil.Clip, error) {
+	xfade := config.crossfade()
+	clips := ffutil.SegmentClips(segments)
+	visible := ffutil.CrossfadeClips(clips, xfade.Duration)
+	stage := tracker.Stage(StageRender, detectProgressEnd, 100, ffutil.ClipsDuration(visible), 1)
+	if err := v.renderClips(ctx, outputPath, clips, &xfade, stage); err != nil {
+		return nil, err
+	}
+	return visible, nil
+}
+
+// renderClips renders clips of the source into outputPath in a single ffmpeg pass,
+// re-encoding with the output format's default encoders. Every audio track is cut
+// alongside the video and container metadata and chapters are carried over.
+// Subtitle streams can't pass through the filter graph, so they are retimed with
+// retimeSubtitles and muxed back in; sources with bitmap subtitles are rejected.
+// With xfade set, neighbouring clips are crossfaded instead of faded at the cuts.
+func (v *Video) renderClips(ctx context.Context, outputPath string, clips []ffutil.Clip, xfade *ffutil.Crossfade, stage *ffutil.StageProgress) error {
+	if len(clips) == 0 {
+		return fmt.Errorf("no segments to render")
+	}
+
+	probe, err := v.probe(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get video info: %w", err)
+	}
+	audioTracks := len(probe.AudioStreams())
+
+	tempDir, err := os.MkdirTemp("", "ffmpego_render_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	visible := clips
+	if xfade != nil {
+		visible = ffutil.CrossfadeClips(clips, xfade.Duration)
+	}
+	metadataPath, err := v.writeMetadata(ctx, tempDir, visible)
+	if err != nil {
+		return err
+	}
+	subtitleInputs, subtitleOutputs, err := v.retimeSubtitles(ctx, tempDir, probe.SubtitleStreams(), visible, 2, outputPath)
+	if err != nil {
+		return err
+	}
+
+	graph := ffutil.ConcatFilterTracks(clips, true, audioTracks, ffutil.DefaultFadeDurationSec)
+	if xfade != nil {
+		graph = ffutil.CrossfadeFilterTracks(clips, true, audioTracks, *xfade)
+	}
+	filterArgs, err := ffutil.FilterComplexArgs(graph, tempDir)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	args := append([]string{"-i", v.path}, metadataInputArgs(metadataPath)...)
+	args = append(args, subtitleInputs...)
+	args = append(args, filterArgs...)
+	args = append(args, "-map", "[outv]")
+	for track := 0; track < audioTracks; track++ {
+		args = append(args, "-map", ffutil.AudioOutputLabel(track))
+	}
+	args = append(args, subtitleOutputs...)
+	args = append(args, mapMetadataArgs(1)...)
+	args = append(args, "-y", outputPath)
+
+	_, err = ffutil.RunWithProgress(ctx, v.runner, v.ffmpeg, args, stage.Reporter(0))
+	if err != nil {
+		return fmt.Errorf("failed to render: %w", err)
+	}
+	stage.Done()
+	return nil
+}
+
+// retimeSubtitles extracts each of streams to SubRip, moves its cues onto the output
+// of clips and writes the result into dir. It returns the ffmpeg arguments that add
+// the files as inputs, numbered from firstInput, and the output arguments that map
+// them into outputPath with their language and title. Styling of ASS subtitles is
+// lost on the way.
+func (v *Video) retimeSubtitles(ctx context.Context, dir string, streams []Stream, clips []ffutil.Clip, firstInput int, outputPath string) (inputs, outputs []string, err error) {
+	if len(streams) == 0 {
+		return nil, nil, nil
+	}
+	for _, s := range streams {
+		if !textSubtitleCodecs[s.CodecName] {
+			return nil, nil, fmt.Errorf("subtitle stream %d is %s, a bitmap format that can't be retimed; cut with CutMethodCopy to keep it", s.Index, s.CodecName)
+		}
+	}
+
+	timeline := ffutil.NewTimeline(clips, 0)
+	for i, s := range streams {
+		path := filepath.Join(dir, fmt.Sprintf("subtitles_%d.srt", i))
+		if _, err := ffutil.CombinedOutput(ctx, v.runner, v.ffmpeg,
+			"-i", v.path, "-map", fmt.Sprintf("0:%d", s.Index), "-c:s", "srt", "-f", "srt", "-y", path); err != nil {
+			return nil, nil, fmt.Errorf("failed to extract subtitle stream %d: %w", s.Index, err)
+		}
+		file, err := subtitles.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read subtitle stream %d: %w", s.Index, err)
+		}
+		file.Cues = subtitles.RetimeCuesTimeline(file.Cues, timeline, subtitles.RetimeOptions{})
+		if err := file.WriteFile(path); err != nil {
+			return nil, nil, err
+		}
+
+		inputs = append(inputs, "-i", path)
+		outputs = append(outputs, "-map", fmt.Sprintf("%d:0", firstInput+i))
+		if s.Language != "" {
+			outputs = append(outputs, fmt.Sprintf("-metadata:s:s:%d", i), "language="+s.Language)
+		}
+		if s.Title != "" {
+			outputs = append(outputs, fmt.Sprintf("-metadata:s:s:%d", i), "title="+s.Title)
+		}
+	}
+	return inputs, append(outputs, "-c:s", subtitleEncoder(outputPath)), nil
+}
+
+// subtitleEncoder returns the text subtitle encoder the container of path accepts.
+func subtitleEncoder(path string) string {
+	switch {
+	case isMP4Family(path):
+		return "mov_text"
+	case strings.EqualFold(filepath.Ext(path), ".webm"):
+		return "webvtt"
+	default:
+		return "srt"
+	}
+}
@@ -1,9 +1,10 @@
 package video
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -16,6 +17,12 @@ type Segment = ffutil.Segment
 // ExtractSegment extracts a segment from the video file.
 // Pass nil for config to use stream copy (fastest, no quality loss).
 func (v *Video) ExtractSegment(outputPath string, startTime, endTime float64, config *ConvertConfig) error {
+	return v.ExtractSegmentContext(context.Background(), outputPath, startTime, endTime, config)
+}
+
+// ExtractSegmentContext is like ExtractSegment but stops ffmpeg when ctx is cancelled
+// or its deadline passes. The returned error then wraps ctx.Err().
+func (v *Video) ExtractSegmentContext(ctx context.Context, outputPath string, startTime, endTime float64, config *ConvertConfig) error {
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
@@ -27,29 +34,77 @@ func (v *Video) ExtractSegment(outputPath string, startTime, endTime float64, co
 		"-t", fmt.Sprintf("%.3f", endTime-startTime),
 	}
 
+	var stage *ffutil.StageProgress
 	if config != nil {
-		info, err := v.GetInfo()
+		info, err := v.getInfo(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get video info: %w", err)
 		}
 		args = append(args, buildConvertArgs(info, config)...)
+		stage = ffutil.NewProgressTracker(config.OnProgress).Stage(StageConvert, 0, 100, endTime-startTime, 1)
 	} else {
 		args = append(args, "-c", "copy")
 	}
 
 	args = append(args, "-y", outputPath)
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
+	_, err := ffutil.RunWithProgress(ctx, v.runner, v.ffmpeg, args, stage.Reporter(0))
 	if err != nil {
-		return fmt.Errorf("FFmpeg error: %w - %s", err, string(output))
+		return fmt.Errorf("failed to extract segment: %w", err)
 	}
+	stage.Done()
 	return nil
 }
 
 // ConcatenateSegments concatenates multiple video segment files into a single video.
-// Pass nil for config to use stream copy (fastest, no quality loss).
-func ConcatenateSegments(segmentPaths []string, outputPath string, config *ConvertConfig) error {
+// Pass nil for config to use stream copy (fastest, no quality loss). Stream copy keeps
+// every stream of the segments except data streams: all audio tracks, subtitles and
+// attachments, not just ffmpeg's default pick of one video and one audio stream. The
+// segments must therefore have the same streams.
+// opts configure how ffmpeg is run, as for New.
+func ConcatenateSegments(segmentPaths []string, outputPath string, config *ConvertConfig, opts ...Option) error {
+	return ConcatenateSegmentsContext(context.Background(), segmentPaths, outputPath, config, opts...)
+}
+
+// ConcatenateSegmentsContext is like ConcatenateSegments but stops ffmpeg when ctx is
+// cancelled or its deadline passes. The returned error then wraps ctx.Err().
+func ConcatenateSegmentsContext(ctx context.Context, segmentPaths []string, outputPath string, config *ConvertConfig, opts ...Option) error {
+	base := newWithOptions("", opts)
+
+	var stage *ffutil.StageProgress
+	if config != nil && config.OnProgress != nil {
+		total, err := base.totalDuration(ctx, segmentPaths)
+		if err != nil {
+			return err
+		}
+		stage = ffutil.NewProgressTracker(config.OnProgress).Stage(StageConcat, 0, 100, total, 1)
+	}
+	return base.concatenate(ctx, segmentPaths, outputPath, config, "", stage)
+}
+
+// totalDuration returns the summed duration of the given media files, probing them
+// with the same options as v.
+func (v *Video) totalDuration(ctx context.Context, paths []string) (float64, error) {
+	var total float64
+	for _, path := range paths {
+		seg, err := New(path, v.opts...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open segment: %w", err)
+		}
+		info, err := seg.getInfo(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get video info: %w", err)
+		}
+		total += info.Duration
+	}
+	return total, nil
+}
+
+// concatenate joins segmentPaths into outputPath, reporting progress through stage.
+// When stream copying, every stream of the segments is kept. If metadataPath is set,
+// the output takes its global metadata and chapters from that ffmetadata file.
+// v only supplies the runner and options; its path is not used.
+func (v *Video) concatenate(ctx context.Context, segmentPaths []string, outputPath string, config *ConvertConfig, metadataPath string, stage *ffutil.StageProgress) error {
 	if len(segmentPaths) == 0 {
 		return fmt.Errorf("no segments to concatenate")
 	}
@@ -73,8 +128,7 @@ func ConcatenateSegments(segmentPaths []string, outputPath string, config *Conve
 		if strings.ContainsAny(absPath, "\n\r") {
 			return fmt.Errorf("segment path contains invalid characters: %s", segmentPath)
 		}
-		escaped := strings.ReplaceAll(absPath, "'", "'\\''")
-		if _, err := fmt.Fprintf(fileList, "file '%s'\n", escaped); err != nil {
+		if _, err := io.WriteString(fileList, ffutil.ConcatListEntry(absPath)); err != nil {
 			return fmt.Errorf("failed to write file list: %w", err)
 		}
 	}
@@ -92,31 +146,37 @@ func ConcatenateSegments(segmentPaths []string, outputPath string, config *Conve
 		"-safe", "0",
 		"-i", fileListPath,
 	}
+	if metadataPath != "" {
+		args = append(args, metadataInputArgs(metadataPath)...)
+	}
 
 	if config != nil {
-		firstVideo, err := New(segmentPaths[0])
+		firstVideo, err := New(segmentPaths[0], v.opts...)
 		if err != nil {
 			return fmt.Errorf("failed to open first segment: %w", err)
 		}
-		info, err := firstVideo.GetInfo()
+		info, err := firstVideo.getInfo(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get video info: %w", err)
 		}
 		if config.needsReencoding(info) {
 			args = append(args, buildConvertArgs(info, config)...)
 		} else {
-			args = append(args, "-c", "copy")
+			args = append(args, copyAllStreamsArgs...)
 		}
 	} else {
-		args = append(args, "-c", "copy")
+		args = append(args, copyAllStreamsArgs...)
+	}
+	if metadataPath != "" {
+		args = append(args, mapMetadataArgs(1)...)
 	}
 
 	args = append(args, "-y", outputPath)
 
-	cmd := exec.Command("ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
+	_, err = ffutil.RunWithProgress(ctx, v.runner, v.ffmpeg, args, stage.Reporter(0))
 	if err != nil {
-		return fmt.Errorf("failed to concatenate segments: %w - %s", err, string(output))
+		return fmt.Errorf("failed to concatenate segments: %w", err)
 	}
+	stage.Done()
 	return nil
 }
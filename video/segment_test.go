@@ -6,6 +6,7 @@ import (
 )
 
 func TestExtractSegment_StreamCopy(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -23,6 +24,7 @@ func TestExtractSegment_StreamCopy(t *testing.T) {
 }
 
 func TestExtractSegment_WithConfig(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -46,6 +48,7 @@ func TestExtractSegment_WithConfig(t *testing.T) {
 }
 
 func TestExtractSegment_OutputDirCreated(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -62,6 +65,7 @@ func TestExtractSegment_OutputDirCreated(t *testing.T) {
 }
 
 func TestConcatenateSegments_Basic(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -94,6 +98,7 @@ func TestConcatenateSegments_Basic(t *testing.T) {
 }
 
 func TestConcatenateSegments_SingleSegment(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
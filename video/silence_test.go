@@ -1,10 +1,12 @@
 package video
 
 import (
+	"strings"
 	"testing"
 )
 
 func TestGetNonSilentSegments_SilenceAtStart(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("silence-start.mp4"))
@@ -36,6 +38,7 @@ func TestGetNonSilentSegments_SilenceAtStart(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_SilenceMiddle(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("silence-middle.mp4"))
@@ -59,6 +62,7 @@ func TestGetNonSilentSegments_SilenceMiddle(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_SilenceAtEnd(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("silence-end.mp4"))
@@ -96,6 +100,7 @@ func TestGetNonSilentSegments_SilenceAtEnd(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_NoSilence(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-silence.mp4"))
@@ -130,6 +135,7 @@ func TestGetNonSilentSegments_NoSilence(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_AllSilence(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("all-silence.mp4"))
@@ -153,6 +159,7 @@ func TestGetNonSilentSegments_AllSilence(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_ZeroValueConfig(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("silence-middle.mp4"))
@@ -172,6 +179,7 @@ func TestGetNonSilentSegments_ZeroValueConfig(t *testing.T) {
 }
 
 func TestGetNonSilentSegments_NoAudioStream(t *testing.T) {
+	requireFFmpeg(t)
 	t.Parallel()
 
 	v, err := New(fixture("no-audio.mp4"))
@@ -192,3 +200,247 @@ func TestGetNonSilentSegments_NoAudioStream(t *testing.T) {
 	}
 	t.Logf("GetNonSilentSegments on no-audio.mp4 returned %d segments (no error)", len(segments))
 }
+
+func TestGetNonSilentSegments_Padding(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	config := SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+	}
+	plain, err := v.GetNonSilentSegments(config)
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+
+	config.PaddingBefore = 300
+	config.PaddingAfter = 300
+	padded, err := v.GetNonSilentSegments(config)
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments with padding: %v", err)
+	}
+
+	if len(plain) != 2 || len(padded) != 2 {
+		t.Fatalf("expected 2 segments with and without padding, got %d and %d", len(plain), len(padded))
+	}
+	const tolerance = 0.01
+	if diff := padded[0].EndTime - plain[0].EndTime; diff < 0.3-tolerance || diff > 0.3+tolerance {
+		t.Errorf("first segment end moved by %.3fs, want 0.3s", diff)
+	}
+	if diff := plain[1].StartTime - padded[1].StartTime; diff < 0.3-tolerance || diff > 0.3+tolerance {
+		t.Errorf("second segment start moved by %.3fs, want 0.3s", diff)
+	}
+	if padded[0].StartTime != 0 {
+		t.Errorf("padding before the first segment should clamp to 0, got %.3f", padded[0].StartTime)
+	}
+}
+
+func TestGetNonSilentSegments_MergeGap(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// The 2s pause is shorter than MergeGap, so both tones end up in one segment
+	segments, err := v.GetNonSilentSegments(SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		SilenceThreshold:   SilenceThresholdModerate,
+		MergeGap:           3000,
+	})
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+
+	if len(segments) != 1 {
+		t.Fatalf("expected segments to merge into 1, got %d: %+v", len(segments), segments)
+	}
+}
+
+func TestGetNonSilentSegments_AudioStream(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("two-tracks.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		stream string
+		want   int
+	}{
+		{"", 1},             // desktop track, continuous tone
+		{"1", 2},            // mic track, tone-silence-tone
+		{"eng", 2},          // mic track by language
+		{AudioStreamAll, 1}, // silent only when both are
+	}
+	for _, tt := range tests {
+		segments, err := v.GetNonSilentSegments(SilenceConfig{
+			MinSilenceDuration: SilenceDurationShort,
+			AudioStream:        tt.stream,
+		})
+		if err != nil {
+			t.Fatalf("AudioStream %q: %v", tt.stream, err)
+		}
+		if len(segments) != tt.want {
+			t.Errorf("AudioStream %q: got %d segments, want %d: %+v", tt.stream, len(segments), tt.want, segments)
+		}
+	}
+}
+
+func TestGetNonSilentSegments_UnknownAudioStream(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("two-tracks.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	for _, config := range []SilenceConfig{
+		{AudioStream: "5"},
+		{AudioStream: "fra"},
+		{Channel: 3},
+	} {
+		if _, err := v.GetNonSilentSegments(config); err == nil {
+			t.Errorf("expected an error for %+v, got nil", config)
+		}
+	}
+}
+
+func TestGetNonSilentSegments_AutoThreshold(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var chosen *ThresholdEstimate
+	segments, err := v.GetNonSilentSegments(SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		AutoThreshold:      true,
+		OnThreshold:        func(e ThresholdEstimate) { chosen = &e },
+	})
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+	if chosen == nil {
+		t.Fatal("OnThreshold was not called")
+	}
+	if len(segments) != 2 {
+		t.Errorf("expected 2 segments (tone-silence-tone) at %d dB, got %d", chosen.Threshold, len(segments))
+	}
+}
+
+func TestGetNonSilentSegments_AutoThresholdFallback(t *testing.T) {
+	t.Parallel()
+
+	// Nothing measured, as with a file of steady level: the configured threshold is used
+	r := &recordingRunner{probe: `{
+		"streams": [
+			{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "r_frame_rate": "30/1"},
+			{"index": 1, "codec_type": "audio", "codec_name": "aac"}
+		],
+		"format": {"duration": "12.500000"}
+	}`}
+	v, err := New(placeholderFile(t, "no-silence.mp4"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	var chosen *ThresholdEstimate
+	segments, err := v.GetNonSilentSegments(SilenceConfig{
+		SilenceThreshold: SilenceThresholdStrict,
+		AutoThreshold:    true,
+		OnThreshold:      func(e ThresholdEstimate) { chosen = &e },
+	})
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+	if chosen == nil || !chosen.Fallback || chosen.Threshold != SilenceThresholdStrict {
+		t.Fatalf("OnThreshold got %+v, want the -40 dB fallback", chosen)
+	}
+	if len(segments) != 1 || segments[0].StartTime != 0 || segments[0].EndTime != 12.5 {
+		t.Errorf("got %+v, want the whole file", segments)
+	}
+	last := strings.Join(r.calls[len(r.calls)-1], " ")
+	if !strings.Contains(last, "silencedetect=noise=-40dB") {
+		t.Errorf("silence detected with %q, want the fallback threshold", last)
+	}
+}
+
+func TestGetNonSilentSegments_SilenceThresholdAuto(t *testing.T) {
+	t.Parallel()
+
+	r := &recordingRunner{probe: `{
+		"streams": [
+			{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "r_frame_rate": "30/1"},
+			{"index": 1, "codec_type": "audio", "codec_name": "aac"}
+		],
+		"format": {"duration": "12.500000"}
+	}`}
+	v, err := New(placeholderFile(t, "no-silence.mp4"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// The preset measures the file like AutoThreshold and falls back to the default
+	var chosen *ThresholdEstimate
+	if _, err := v.GetNonSilentSegments(SilenceConfig{
+		SilenceThreshold: SilenceThresholdAuto,
+		OnThreshold:      func(e ThresholdEstimate) { chosen = &e },
+	}); err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+	if chosen == nil || !chosen.Fallback || chosen.Threshold != SilenceThresholdModerate {
+		t.Fatalf("OnThreshold got %+v, want the -30 dB fallback", chosen)
+	}
+	last := strings.Join(r.calls[len(r.calls)-1], " ")
+	if !strings.Contains(last, "silencedetect=noise=-30dB") {
+		t.Errorf("silence detected with %q, want the default threshold", last)
+	}
+
+	estimate, err := v.EstimateSilenceThreshold(SilenceConfig{SilenceThreshold: SilenceThresholdAuto})
+	if err != nil {
+		t.Fatalf("EstimateSilenceThreshold: %v", err)
+	}
+	if estimate != *chosen {
+		t.Errorf("EstimateSilenceThreshold = %+v, want %+v", estimate, *chosen)
+	}
+}
+
+func TestGetNonSilentSegments_VoiceDetector(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("speech-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	segments, err := v.GetNonSilentSegments(SilenceConfig{
+		MinSilenceDuration: SilenceDurationShort,
+		Detector:           DetectorVoice,
+	})
+	if err != nil {
+		t.Fatalf("GetNonSilentSegments: %v", err)
+	}
+	if len(segments) != 2 {
+		t.Errorf("expected 2 segments around the pause, got %d: %+v", len(segments), segments)
+	}
+
+	if _, err := v.GetNonSilentSegments(SilenceConfig{Detector: "psychic"}); err == nil {
+		t.Error("expected an error for an unknown detector, got nil")
+	}
+}
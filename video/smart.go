@@ -0,0 +1,286 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// smartQuality is the CRF used for the few frames smart cutting re-encodes. It is
+// higher quality than the Convert default so the re-encoded frames blend in with
+// the copied ones.
+const smartQuality = "18"
+
+// Encoder profile names for the ffprobe profiles smart cutting can reproduce
+var (
+	h264Profiles = map[string]string{
+		"Baseline":              "baseline",
+		"Constrained Baseline":  "baseline",
+		"Main":                  "main",
+		"High":                  "high",
+		"High 10":               "high10",
+		"High 4:2:2":            "high422",
+		"High 4:4:4 Predictive": "high444",
+	}
+	hevcProfiles = map[string]string{
+		"Main":    "main",
+		"Main 10": "main10",
+	}
+)
+
+// Encoder level names for the levels ffprobe reports: level_idc for H.264, where 9
+// is level 1b, and general_level_idc (30 times the level) for HEVC
+var (
+	h264Levels = map[int]string{
+		9: "1b", 10: "1", 11: "1.1", 12: "1.2", 13: "1.3",
+		20: "2", 21: "2.1", 22: "2.2", 30: "3", 31: "3.1", 32: "3.2",
+		40: "4", 41: "4.1", 42: "4.2", 50: "5", 51: "5.1", 52: "5.2",
+		60: "6", 61: "6.1", 62: "6.2",
+	}
+	hevcLevels = map[int]string{
+		30: "1", 60: "2", 63: "2.1", 90: "3", 93: "3.1",
+		120: "4", 123: "4.1", 150: "5", 153: "5.1", 156: "5.2",
+		180: "6", 183: "6.1", 186: "6.2",
+	}
+)
+
+// ExtractSegmentSmart extracts a frame-accurate segment from an H.264 or HEVC video
+// while re-encoding as little as possible. Only the frames between startTime and the
+// next keyframe are re-encoded, with parameters matching the source; the rest of the
+// video is stream-copied. Audio is re-encoded so it starts and ends exactly at the
+// cut. onProgress may be nil.
+func (v *Video) ExtractSegmentSmart(outputPath string, startTime, endTime float64, onProgress ProgressFunc) error {
+	return v.ExtractSegmentSmartContext(context.Background(), outputPath, startTime, endTime, onProgress)
+}
+
+// ExtractSegmentSmartContext is like ExtractSegmentSmart but stops ffmpeg when ctx is
+// cancelled or its deadline passes. The returned error then wraps ctx.Err().
+func (v *Video) ExtractSegmentSmartContext(ctx context.Context, outputPath string, startTime, endTime float64, onProgress ProgressFunc) error {
+	if endTime <= startTime {
+		return fmt.Errorf("invalid segment: end %.3f is not after start %.3f", endTime, startTime)
+	}
+	cutter, err := v.newSmartCutter(ctx)
+	if err != nil {
+		return err
+	}
+	stage := ffutil.NewProgressTracker(onProgress).Stage(StageExtract, 0, 100, endTime-startTime, 1)
+	if err := cutter.extract(ctx, outputPath, startTime, endTime, "", stage.Reporter(0)); err != nil {
+		return err
+	}
+	stage.Done()
+	return nil
+}
+
+// smartCutter cuts segments of one source with CutMethodSmart.
+type smartCutter struct {
+	v         *Video
+	keyframes []float64 // Sorted closed-GOP keyframe times of the first video stream, on the -ss timeline
+	encoder   []string  // Output arguments that re-encode video like the source
+	stream    string    // Map specifier of that stream, e.g. "0:0"
+	audio     []Stream  // Audio streams, all of which are carried over
+	codec     string
+	tolerance float64 // Half a frame; cut points this close to a keyframe count as on it
+}
+
+// newSmartCutter looks up the source's video stream and keyframes.
+func (v *Video) newSmartCutter(ctx context.Context) (*smartCutter, error) {
+	stream, err := v.videoStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder, err := smartEncoderArgs(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	packets, err := v.packets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	probe, err := v.probe(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe file: %w", err)
+	}
+
+	frameRate := stream.FrameRate
+	if frameRate <= 0 {
+		frameRate = 30
+	}
+
+	// Packet times count from the container's first timestamp, which is not zero in
+	// MPEG-TS or in MP4s with an edit list; -ss and the segments count from the start.
+	// Only closed-GOP keyframes can start a copied tail.
+	keyframes := ffutil.ClosedKeyframeTimes(packets)
+	for i := range keyframes {
+		keyframes[i] -= probe.Format.StartTime
+	}
+
+	return &smartCutter{
+		v:         v,
+		keyframes: keyframes,
+		encoder:   encoder,
+		stream:    fmt.Sprintf("0:%d", stream.Index),
+		audio:     probe.AudioStreams(),
+		codec:     stream.CodecName,
+		tolerance: 0.5 / frameRate,
+	}, nil
+}
+
+// smartEncoderArgs returns ffmpeg output arguments that re-encode video with the same
+// codec, profile, level, pixel format and color description as stream, so the
+// re-encoded frames can be joined with stream-copied ones.
+func smartEncoderArgs(stream *Stream) ([]string, error) {
+	var encoder string
+	var profiles map[string]string
+	var levels map[int]string
+	switch stream.CodecName {
+	case "h264":
+		encoder, profiles, levels = CodecH264, h264Profiles, h264Levels
+	case "hevc":
+		encoder, profiles, levels = CodecH265, hevcProfiles, hevcLevels
+	default:
+		return nil, fmt.Errorf("smart cutting supports H.264 and HEVC video, got %q", stream.CodecName)
+	}
+
+	args := []string{"-c:v", encoder, "-crf", smartQuality, "-preset", PresetFast}
+	if profile, ok := profiles[stream.Profile]; ok {
+		args = append(args, "-profile:v", profile)
+	}
+	if level, ok := levels[stream.Level]; ok {
+		if stream.CodecName == "h264" {
+			args = append(args, "-level:v", level)
+		} else {
+			args = append(args, "-x265-params", "level-idc="+level)
+		}
+	}
+	if stream.PixelFormat != "" {
+		args = append(args, "-pix_fmt", stream.PixelFormat)
+	}
+	for _, opt := range []struct{ flag, value string }{
+		{"-color_range", stream.ColorRange},
+		{"-colorspace", stream.ColorSpace},
+		{"-color_trc", stream.ColorTransfer},
+		{"-color_primaries", stream.ColorPrimaries},
+	} {
+		if opt.value != "" && opt.value != "unknown" {
+			args = append(args, opt.flag, opt.value)
+		}
+	}
+	return args, nil
+}
+
+// extract writes [startTime, endTime) of the source to outputPath. The video is built
+// in MPEG-TS pieces, which carry codec parameters in-band so the re-encoded head and
+// the copied tail can be joined: a head re-encoded from startTime up to the next
+// keyframe, and a tail copied from that keyframe to endTime. The pieces are then
+// joined and muxed with every audio track of the source, re-encoded through
+// audioFilter if set, its subtitles, container metadata and chapters.
+// Only the final mux reports progress through onReport.
+func (c *smartCutter) extract(ctx context.Context, outputPath string, startTime, endTime float64, audioFilter string, onReport func(ffutil.ProgressReport)) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	tempDir, err := os.MkdirTemp("", "ffmpego_smart_*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	split := endTime
+	if k, ok := ffutil.NextKeyframe(c.keyframes, startTime, c.tolerance); ok && k < endTime-c.tolerance {
+		split = max(k, startTime)
+	}
+
+	var parts []string
+	if split-startTime > c.tolerance {
+		head := filepath.Join(tempDir, "head.ts")
+		args := []string{
+			"-ss", fmt.Sprintf("%.6f", startTime),
+			"-i", c.v.path,
+			"-t", fmt.Sprintf("%.6f", split-startTime),
+			"-map", c.stream,
+		}
+		args = append(args, c.encoder...)
+		args = append(args, "-y", head)
+		if _, err := ffutil.CombinedOutput(ctx, c.v.runner, c.v.ffmpeg, args...); err != nil {
+			return fmt.Errorf("failed to re-encode segment start: %w", err)
+		}
+		parts = append(parts, head)
+	}
+
+	if endTime-split > c.tolerance {
+		tail := filepath.Join(tempDir, "tail.ts")
+		// Input seeking with stream copy starts at the keyframe at or before the seek
+		// point; nudging past the rounded keyframe time keeps it from landing on the
+		// previous one
+		args := []string{
+			"-ss", fmt.Sprintf("%.6f", split+0.0005),
+			"-i", c.v.path,
+			"-t", fmt.Sprintf("%.6f", endTime-split),
+			"-map", c.stream,
+			"-c:v", "copy",
+			"-y", tail,
+		}
+		if _, err := ffutil.CombinedOutput(ctx, c.v.runner, c.v.ffmpeg, args...); err != nil {
+			return fmt.Errorf("failed to copy segment: %w", err)
+		}
+		parts = append(parts, tail)
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("segment %.3f-%.3f is shorter than a frame", startTime, endTime)
+	}
+
+	listPath := filepath.Join(tempDir, "parts.txt")
+	var list strings.Builder
+	for _, part := range parts {
+		list.WriteString(ffutil.ConcatListEntry(part))
+	}
+	if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write file list: %w", err)
+	}
+
+	args := []string{
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listPath,
+		"-ss", fmt.Sprintf("%.6f", startTime),
+		"-t", fmt.Sprintf("%.6f", endTime-startTime),
+		"-i", c.v.path,
+		"-map", "0:v:0",
+		"-map", "1:a?",
+		"-map", "1:s?",
+		"-c", "copy",
+	}
+	args = append(args, audioEncoderArgs(c.audio)...)
+	args = append(args, mapMetadataArgs(1)...)
+	if audioFilter != "" {
+		args = append(args, "-af", audioFilter)
+	}
+	if c.codec == "hevc" && isMP4Family(outputPath) {
+		// Copying out of MPEG-TS tags HEVC as hev1, which Apple players reject
+		args = append(args, "-tag:v", "hvc1")
+	}
+	args = append(args, "-y", outputPath)
+
+	if _, err := ffutil.RunWithProgress(ctx, c.v.runner, c.v.ffmpeg, args, onReport); err != nil {
+		return fmt.Errorf("failed to extract segment: %w", err)
+	}
+	return nil
+}
+
+// isMP4Family reports whether path names an MP4 or QuickTime file.
+func isMP4Family(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".mp4", ".m4v", ".mov":
+		return true
+	default:
+		return false
+	}
+}
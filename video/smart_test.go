@@ -0,0 +1,142 @@
+package video
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractSegmentSmart(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("silence-middle.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	if err := v.ExtractSegmentSmart(out, 1.0, 3.5, nil); err != nil {
+		t.Fatalf("ExtractSegmentSmart: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 2.5, 0.15)
+}
+
+func TestKeepSegments_Smart(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("no-silence.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	segments := []Segment{
+		{StartTime: 0.5, EndTime: 1.5},
+		{StartTime: 3.0, EndTime: 4.2},
+	}
+	if err := v.KeepSegments(out, segments, CutConfig{Method: CutMethodSmart}); err != nil {
+		t.Fatalf("KeepSegments: %v", err)
+	}
+
+	assertValidMedia(t, out)
+	assertDuration(t, out, 2.2, 0.2)
+}
+
+func TestExtractSegmentSmart_StartTime(t *testing.T) {
+	t.Parallel()
+
+	// An MPEG-TS file whose timestamps start at 1.4s: its keyframes are at 0, 2 and 4
+	// seconds into the file
+	r := &packetRunner{
+		probe: `{
+			"streams": [
+				{"index": 0, "codec_type": "video", "codec_name": "h264", "profile": "High", "r_frame_rate": "25/1"},
+				{"index": 1, "codec_type": "audio", "codec_name": "aac"}
+			],
+			"format": {"start_time": "1.400000", "duration": "6.000000"}
+		}`,
+		packets: `pts_time=1.400000|dts_time=1.320000|duration_time=0.040000|size=9000|pos=376|flags=K__
+pts_time=1.440000|dts_time=1.360000|duration_time=0.040000|size=400|pos=9400|flags=___
+pts_time=3.400000|dts_time=3.320000|duration_time=0.040000|size=8800|pos=80000|flags=K__
+pts_time=5.400000|dts_time=5.320000|duration_time=0.040000|size=8700|pos=160000|flags=K__
+`,
+	}
+	v, err := New(placeholderFile(t, "in.ts"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	if err := v.ExtractSegmentSmart(out, 1.0, 3.0, nil); err != nil {
+		t.Fatalf("ExtractSegmentSmart: %v", err)
+	}
+	if len(r.calls) != 3 {
+		t.Fatalf("ran ffmpeg %d times, want head, tail and mux", len(r.calls))
+	}
+	head, tail := strings.Join(r.calls[0], " "), strings.Join(r.calls[1], " ")
+	if !strings.HasPrefix(head, "-ss 1.000000 -i "+v.Path()+" -t 1.000000 ") {
+		t.Errorf("head re-encoded with %s, want 1s up to the keyframe at 2s", head)
+	}
+	if !strings.HasPrefix(tail, "-ss 2.000500 -i "+v.Path()+" -t 1.000000 ") {
+		t.Errorf("tail copied with %s, want from the keyframe at 2s", tail)
+	}
+}
+
+func TestSmartEncoderArgs(t *testing.T) {
+	t.Parallel()
+
+	args, err := smartEncoderArgs(&Stream{
+		CodecName:   "h264",
+		Profile:     "High",
+		Level:       41,
+		PixelFormat: "yuv420p",
+		ColorSpace:  "bt709",
+		ColorRange:  "unknown",
+	})
+	if err != nil {
+		t.Fatalf("smartEncoderArgs: %v", err)
+	}
+	got := strings.Join(args, " ")
+	want := "-c:v libx264 -crf 18 -preset fast -profile:v high -level:v 4.1 -pix_fmt yuv420p -colorspace bt709"
+	if got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+
+	args, err = smartEncoderArgs(&Stream{CodecName: "hevc", Profile: "Main 10", Level: 123})
+	if err != nil {
+		t.Fatalf("smartEncoderArgs: %v", err)
+	}
+	got = strings.Join(args, " ")
+	want = "-c:v libx265 -crf 18 -preset fast -profile:v main10 -x265-params level-idc=4.1"
+	if got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+
+	for _, tt := range []struct {
+		codec string
+		level int
+		want  string
+	}{
+		{"h264", 9, "-c:v libx264 -crf 18 -preset fast -level:v 1b"},
+		{"h264", 31, "-c:v libx264 -crf 18 -preset fast -level:v 3.1"},
+		{"hevc", 93, "-c:v libx265 -crf 18 -preset fast -x265-params level-idc=3.1"},
+		{"hevc", 186, "-c:v libx265 -crf 18 -preset fast -x265-params level-idc=6.2"},
+		{"hevc", 95, "-c:v libx265 -crf 18 -preset fast"}, // Not a level; left to the encoder
+	} {
+		args, err := smartEncoderArgs(&Stream{CodecName: tt.codec, Level: tt.level})
+		if err != nil {
+			t.Fatalf("smartEncoderArgs: %v", err)
+		}
+		if got := strings.Join(args, " "); got != tt.want {
+			t.Errorf("%s level %d: args = %q, want %q", tt.codec, tt.level, got, tt.want)
+		}
+	}
+
+	if _, err := smartEncoderArgs(&Stream{CodecName: "vp9"}); err == nil {
+		t.Error("expected an error for VP9, got nil")
+	}
+}
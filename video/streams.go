@@ -0,0 +1,60 @@
+package video
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// copyAllStreamsArgs maps every stream of input 0 except data streams, which many
+// containers cannot hold, and stream-copies them.
+var copyAllStreamsArgs = []string{"-map", "0", "-map", "-0:d?", "-c", "copy"}
+
+// audioEncoderArgs re-encodes each audio stream with the encoder for its own codec,
+// so segments cut from the same source can still be joined by stream copy.
+func audioEncoderArgs(audio []Stream) []string {
+	var args []string
+	for i, s := range audio {
+		args = append(args, fmt.Sprintf("-c:a:%d", i), encoderForDecoder(s.CodecName))
+	}
+	return args
+}
+
+// writeMetadata writes the source's container metadata, and its chapters moved onto
+// the output timeline of clips, to an ffmetadata file in dir.
+func (v *Video) writeMetadata(ctx context.Context, dir string, clips []ffutil.Clip) (string, error) {
+	probe, err := v.probe(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to probe file: %w", err)
+	}
+
+	path := filepath.Join(dir, "metadata.txt")
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create metadata file: %w", err)
+	}
+	defer f.Close()
+
+	chapters := ffutil.RemapChapters(probe.Chapters, clips)
+	if err := ffutil.WriteFFMetadata(f, probe.Format.Tags, chapters); err != nil {
+		return "", fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("failed to write metadata file: %w", err)
+	}
+	return path, nil
+}
+
+// metadataInputArgs adds an ffmetadata file written by writeMetadata as an input.
+func metadataInputArgs(path string) []string {
+	return []string{"-f", "ffmetadata", "-i", path}
+}
+
+// mapMetadataArgs takes the global metadata and chapters from the given input.
+func mapMetadataArgs(input int) []string {
+	n := fmt.Sprint(input)
+	return []string{"-map_metadata", n, "-map_chapters", n}
+}
@@ -0,0 +1,92 @@
+package video
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/meunomeebero/ffmpego/audio"
+	"github.com/meunomeebero/ffmpego/internal/ffutil"
+)
+
+// SyncConfig contains configuration for FindAudioOffset. Zero values select the
+// defaults.
+type SyncConfig struct {
+	// Start is where the compared window begins in the video, in seconds.
+	Start float64
+	// Duration is the length of the compared window in seconds (default 60). Pick a
+	// part where both recordings hear the same sound, such as speech or a clap.
+	Duration float64
+	// MaxOffset is the largest offset searched for, either way, in seconds (default 30).
+	MaxOffset float64
+}
+
+// SyncEstimate is the offset found between two recordings, with how confident the
+// match is.
+type SyncEstimate = ffutil.SyncEstimate
+
+func (c SyncConfig) options() ffutil.SyncOptions {
+	return ffutil.SyncOptions{Start: c.Start, Duration: c.Duration, MaxOffset: c.MaxOffset}
+}
+
+// FindAudioOffset estimates where reference starts on the video's timeline by
+// cross-correlating the first audio stream of each file. The result is the offset
+// RemoveSilenceUsing takes. To line up another camera, open it with audio.New.
+func (v *Video) FindAudioOffset(reference *audio.Audio, config SyncConfig) (SyncEstimate, error) {
+	return v.FindAudioOffsetContext(context.Background(), reference, config)
+}
+
+// FindAudioOffsetContext is like FindAudioOffset but stops ffmpeg when ctx is
+// cancelled or its deadline passes.
+func (v *Video) FindAudioOffsetContext(ctx context.Context, reference *audio.Audio, config SyncConfig) (SyncEstimate, error) {
+	if reference == nil {
+		return SyncEstimate{}, fmt.Errorf("no reference recording given")
+	}
+	return ffutil.EstimateOffset(ctx, v.runner, v.ffmpeg, v.path, reference.Path(), config.options())
+}
+
+// WithAudioOffset writes a copy of the video with all its audio tracks moved later by
+// offset seconds, or earlier when offset is negative, to fix audio that is out of
+// sync. Every stream except data streams is copied without re-encoding and the output
+// keeps the video's duration. The video must have an audio stream.
+func (v *Video) WithAudioOffset(outputPath string, offset float64) error {
+	return v.WithAudioOffsetContext(context.Background(), outputPath, offset)
+}
+
+// WithAudioOffsetContext is like WithAudioOffset but stops ffmpeg when ctx is
+// cancelled or its deadline passes.
+func (v *Video) WithAudioOffsetContext(ctx context.Context, outputPath string, offset float64) error {
+	probe, err := v.probe(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to probe file: %w", err)
+	}
+	if len(probe.AudioStreams()) == 0 {
+		return fmt.Errorf("cannot shift the audio: %s has no audio stream", v.path)
+	}
+	info, err := v.getInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get video info: %w", err)
+	}
+
+	args := []string{"-i", v.path}
+	args = append(args, offsetInputArgs(offset)...)
+	args = append(args,
+		"-i", v.path,
+		"-map", "0", "-map", "-0:a", "-map", "-0:d?", "-map", "1:a",
+		"-c", "copy",
+		"-t", fmt.Sprintf("%.6f", info.Duration),
+		"-y", outputPath,
+	)
+	if _, err := ffutil.CombinedOutput(ctx, v.runner, v.ffmpeg, args...); err != nil {
+		return fmt.Errorf("failed to shift audio: %w", err)
+	}
+	return nil
+}
+
+// offsetInputArgs returns the input options that place the next input offset seconds
+// into the output: delayed with -itsoffset, or started early by seeking into it.
+func offsetInputArgs(offset float64) []string {
+	if offset >= 0 {
+		return []string{"-itsoffset", fmt.Sprintf("%.6f", offset)}
+	}
+	return []string{"-ss", fmt.Sprintf("%.6f", -offset)}
+}
@@ -0,0 +1,122 @@
+package video
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meunomeebero/ffmpego/audio"
+)
+
+func TestOffsetInputArgs(t *testing.T) {
+	if got := offsetInputArgs(1.5); got[0] != "-itsoffset" || got[1] != "1.500000" {
+		t.Errorf("offsetInputArgs(1.5) = %v", got)
+	}
+	if got := offsetInputArgs(-0.25); got[0] != "-ss" || got[1] != "0.250000" {
+		t.Errorf("offsetInputArgs(-0.25) = %v", got)
+	}
+}
+
+func TestWithAudioOffset_FakeRunner(t *testing.T) {
+	t.Parallel()
+
+	r := &recordingRunner{probe: `{
+		"streams": [
+			{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "r_frame_rate": "30/1"},
+			{"index": 1, "codec_type": "audio", "codec_name": "aac"},
+			{"index": 2, "codec_type": "data", "codec_name": "bin_data"}
+		],
+		"format": {"duration": "12.500000"}
+	}`}
+	v, err := New(placeholderFile(t, "no-silence.mp4"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := v.WithAudioOffset("out.mp4", 0.5); err != nil {
+		t.Fatalf("WithAudioOffset: %v", err)
+	}
+	got := strings.Join(r.calls[len(r.calls)-1], " ")
+	want := "-itsoffset 0.500000 -i " + v.Path() + " -map 0 -map -0:a -map -0:d? -map 1:a -c copy -t 12.500000 -y out.mp4"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("got  %s\nwant ...%s", got, want)
+	}
+}
+
+func TestWithAudioOffset_NoAudio(t *testing.T) {
+	t.Parallel()
+
+	r := &recordingRunner{probe: `{
+		"streams": [{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "r_frame_rate": "30/1"}],
+		"format": {"duration": "12.500000"}
+	}`}
+	v, err := New(placeholderFile(t, "no-silence.mp4"), WithRunner(r))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	err = v.WithAudioOffset("out.mp4", 0.5)
+	if err == nil || !strings.Contains(err.Error(), "no audio stream") {
+		t.Fatalf("expected a no audio stream error, got %v", err)
+	}
+	for _, call := range r.calls {
+		if call[0] != "ffprobe" {
+			t.Errorf("ran %v for a video without audio", call)
+		}
+	}
+}
+
+func TestWithAudioOffset_FindAudioOffset(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("noise.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	original, err := audio.New(fixture("noise.mp4"))
+	if err != nil {
+		t.Fatalf("audio.New: %v", err)
+	}
+
+	for _, offset := range []float64{0.5, -0.75} {
+		out := filepath.Join(t.TempDir(), "shifted.mp4")
+		if err := v.WithAudioOffset(out, offset); err != nil {
+			t.Fatalf("WithAudioOffset(%v): %v", offset, err)
+		}
+		assertValidMedia(t, out)
+		assertDuration(t, out, 6.0, 0.2)
+
+		shifted, err := New(out)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		got, err := shifted.FindAudioOffset(original, SyncConfig{Duration: 4, MaxOffset: 2})
+		if err != nil {
+			t.Fatalf("FindAudioOffset: %v", err)
+		}
+		// Stream copy places audio at packet boundaries
+		if math.Abs(got.Offset-offset) > 0.03 {
+			t.Errorf("Offset = %.3f, want %.3f", got.Offset, offset)
+		}
+		if got.Confidence < minSyncConfidence {
+			t.Errorf("Confidence = %.2f, want at least %.2f", got.Confidence, minSyncConfidence)
+		}
+	}
+}
+
+func TestFindAudioOffset_NoMatch(t *testing.T) {
+	requireFFmpeg(t)
+	t.Parallel()
+
+	v, err := New(fixture("noise.mp4"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	silent, err := audio.New(fixture("all-silence.mp4"))
+	if err != nil {
+		t.Fatalf("audio.New: %v", err)
+	}
+	if _, err := v.FindAudioOffset(silent, SyncConfig{}); err == nil {
+		t.Error("expected an error for a silent reference, got nil")
+	}
+}
@@ -12,12 +12,17 @@ import (
 
 var testFixtureDir string
 
+// haveFFmpeg reports whether ffmpeg is installed. Without it the fixtures aren't
+// generated and the tests that need them skip, while tests using a fake Runner
+// still run.
+var haveFFmpeg bool
+
 func TestMain(m *testing.M) {
-	_, err := exec.LookPath("ffmpeg")
-	if err != nil {
-		fmt.Println("skipping: ffmpeg not found in PATH")
-		os.Exit(0)
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		fmt.Println("ffmpeg not found in PATH: skipping tests that need fixtures")
+		os.Exit(m.Run())
 	}
+	haveFFmpeg = true
 
 	dir, err := os.MkdirTemp("", "ffmpego_video_test_*")
 	if err != nil {
@@ -49,6 +54,8 @@ func runFFmpeg(dir, name string, args ...string) error {
 func generateVideoFixtures(dir string) error {
 	const videoSrc = "testsrc2=size=320x240:rate=15"
 	const encArgs = "-c:v libx264 -preset ultrafast -crf 28 -c:a aac -ac 1"
+	// a 220 Hz tone with two harmonics, rising and falling four times a second
+	const syllablesSrc = "aevalsrc=exprs=(0.575-0.425*cos(8*PI*t))*(0.5*sin(440*PI*t)+0.25*sin(880*PI*t)+0.125*sin(1320*PI*t)):s=44100"
 
 	// no-silence.mp4: 5s video + 5s sine tone, simple mux
 	if err := runFFmpeg(dir, "no-silence.mp4",
@@ -125,6 +132,23 @@ func generateVideoFixtures(dir string) error {
 		return err
 	}
 
+	// speech-middle.mp4: silence-middle.mp4 with tones rising and falling four times a
+	// second, like syllables
+	if err := runFFmpeg(dir, "speech-middle.mp4",
+		"-f", "lavfi", "-i", videoSrc+":duration=6",
+		"-f", "lavfi", "-i", syllablesSrc+":d=2",
+		"-f", "lavfi", "-i", "anullsrc=r=44100:cl=mono",
+		"-f", "lavfi", "-i", syllablesSrc+":d=2",
+		"-filter_complex", "[2]atrim=duration=2[s];[1][s][3]concat=n=3:v=0:a=1[a]",
+		"-map", "0:v",
+		"-map", "[a]",
+		"-c:v", "libx264", "-preset", "ultrafast", "-crf", "28",
+		"-c:a", "aac", "-ac", "1",
+		"-shortest",
+	); err != nil {
+		return err
+	}
+
 	// silence-end.mp4: 5s video + (3s sine concat 2s silence)
 	if err := runFFmpeg(dir, "silence-end.mp4",
 		"-f", "lavfi", "-i", videoSrc+":duration=5",
@@ -140,9 +164,59 @@ func generateVideoFixtures(dir string) error {
 		return err
 	}
 
+	// two-tracks.mp4: 6s video, desktop track (6s sine) then mic track (2s sine + 2s
+	// silence + 2s sine), the mic tagged as English
+	if err := runFFmpeg(dir, "two-tracks.mp4",
+		"-f", "lavfi", "-i", videoSrc+":duration=6",
+		"-f", "lavfi", "-i", "sine=frequency=440:sample_rate=44100:duration=6",
+		"-f", "lavfi", "-i", "sine=frequency=440:sample_rate=44100:duration=2",
+		"-f", "lavfi", "-i", "anullsrc=r=44100:cl=mono",
+		"-f", "lavfi", "-i", "sine=frequency=440:sample_rate=44100:duration=2",
+		"-filter_complex", "[3]atrim=duration=2[s];[2][s][4]concat=n=3:v=0:a=1[mic]",
+		"-map", "0:v",
+		"-map", "1:a",
+		"-map", "[mic]",
+		"-metadata:s:a:1", "language=eng",
+		"-c:v", "libx264", "-preset", "ultrafast", "-crf", "28",
+		"-c:a", "aac", "-ac", "1",
+		"-shortest",
+	); err != nil {
+		return err
+	}
+
+	// noise.mp4: 6s video + 6s pink noise, which matches itself at one offset only
+	if err := runFFmpeg(dir, "noise.mp4",
+		"-f", "lavfi", "-i", videoSrc+":duration=6",
+		"-f", "lavfi", "-i", "anoisesrc=r=44100:a=0.3:c=pink:d=6",
+		"-c:v", "libx264", "-preset", "ultrafast", "-crf", "28",
+		"-c:a", "aac", "-ac", "1",
+		"-shortest",
+	); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// requireFFmpeg skips the test when ffmpeg is not installed.
+func requireFFmpeg(t *testing.T) {
+	t.Helper()
+	if !haveFFmpeg {
+		t.Skip("ffmpeg not found in PATH")
+	}
+}
+
+// placeholderFile creates an empty file for tests whose Runner never reads it, so
+// that the existence check in New passes.
+func placeholderFile(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	return path
+}
+
 func fixture(name string) string {
 	return filepath.Join(testFixtureDir, name)
 }
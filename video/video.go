@@ -15,20 +15,30 @@ import (
 // However, temporary files are uniquely named to prevent conflicts between
 // concurrent operations on different Video instances.
 type Video struct {
-	path string
-	info *Info
+	path        string
+	info        *Info
+	probed      *ProbeResult
+	packetIndex []Packet
+	caps        *Capabilities
+	runner      ffutil.Runner
+	ffmpeg      string   // ffmpeg binary name or path
+	ffprobe     string   // ffprobe binary name or path
+	opts        []Option // kept so derived instances (e.g. for segments) share the configuration
 }
 
 // New creates a new Video instance from a file path.
 // Returns an error if ffmpeg/ffprobe are not installed or the file does not exist.
-func New(path string) (*Video, error) {
-	if err := ffutil.CheckDependencies(); err != nil {
-		return nil, err
+func New(path string, opts ...Option) (*Video, error) {
+	v := newWithOptions(path, opts)
+	if _, ok := v.runner.(ffutil.ExecRunner); ok {
+		if err := ffutil.CheckBinaries(v.ffmpeg, v.ffprobe); err != nil {
+			return nil, err
+		}
 	}
 	if _, err := os.Stat(path); err != nil {
 		return nil, fmt.Errorf("video file not accessible: %s: %w", path, err)
 	}
-	return &Video{path: path}, nil
+	return v, nil
 }
 
 // Path returns the video file path